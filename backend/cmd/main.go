@@ -3,15 +3,22 @@ package main
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 
+	"sams-backend/internal/contracts"
 	"sams-backend/internal/database"
+	"sams-backend/internal/depreciation"
 	"sams-backend/internal/handlers"
+	"sams-backend/internal/labels"
+	"sams-backend/internal/maintenance"
 	"sams-backend/internal/middleware"
 	"sams-backend/internal/models"
+	"sams-backend/internal/search"
+	"sams-backend/internal/trash"
 )
 
 func main() {
@@ -30,9 +37,34 @@ func main() {
 	}
 
 	// Auto-migrate database schema
-	if err := db.AutoMigrate(&models.Category{}, &models.Asset{}, &models.Department{}, &models.User{}); err != nil {
+	if err := db.SetupJoinTable(&models.Contract{}, "Assets", &models.ContractAsset{}); err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
+	if err := db.AutoMigrate(&models.Category{}, &models.Geofence{}, &models.Vendor{}, &models.VendorContact{}, &models.Asset{}, &models.Department{}, &models.User{}, &models.AssetHistory{}, &models.AssetCustody{}, &models.MaintenancePlan{}, &models.WorkOrder{}, &models.AssetStatusEvent{}, &models.DisposalRequest{}, &models.AssetTransfer{}, &models.GeofenceAlert{}, &models.AssetSighting{}, &models.InventoryCampaign{}, &models.InventoryCampaignAuditor{}, &models.InventoryScan{}, &models.Contract{}, &models.ContractAlert{}, &models.Part{}, &models.StockLocation{}, &models.StockLevel{}, &models.StockMovement{}, &models.Meter{}, &models.MeterReading{}, &models.MeterRule{}, &models.MaintenanceDueEvent{}); err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
+	if err := database.DropLegacyUniqueConstraints(db); err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
+	if err := search.Setup(db); err != nil {
+		log.Fatal("Failed to set up search:", err)
+	}
+
+	// Asset QR codes encode a deep link built from QR_LINK_TEMPLATE
+	if template := os.Getenv("QR_LINK_TEMPLATE"); template == "" || template == labels.DefaultLinkTemplate {
+		log.Printf("WARNING: asset QR codes link to the development default %s; set QR_LINK_TEMPLATE to the frontend's public URL", labels.DefaultLinkTemplate)
+	} else if err := labels.CheckLinkTemplate(template); err != nil {
+		log.Fatal("Invalid QR_LINK_TEMPLATE: ", err)
+	}
+
+	// Start background jobs
+	maintenance.StartScheduler(db, durationFromEnv("MAINTENANCE_SCHEDULER_INTERVAL", time.Hour))
+	depreciation.StartRecalculation(db, durationFromEnv("DEPRECIATION_RECALC_INTERVAL", 24*time.Hour))
+	contracts.StartExpiryAlerts(db, durationFromEnv("CONTRACT_EXPIRY_ALERT_INTERVAL", time.Hour))
+	// Deleted records are only purged automatically when a retention is configured
+	if retention := durationFromEnv("TRASH_RETENTION", 0); retention > 0 {
+		trash.StartPurge(db, durationFromEnv("TRASH_PURGE_INTERVAL", 24*time.Hour), retention)
+	}
 
 	// Initialize handlers
 	userHandler := handlers.NewUserHandler(db)
@@ -55,9 +87,10 @@ func main() {
 	// Middleware
 	app.Use(logger.New())
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: os.Getenv("CORS_ALLOWED_ORIGINS"),
-		AllowMethods: "GET, POST, PUT, DELETE, OPTIONS",
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
+		AllowOrigins:  os.Getenv("CORS_ALLOWED_ORIGINS"),
+		AllowMethods:  "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization, If-Match",
+		ExposeHeaders: "ETag",
 	}))
 
 	// Health check endpoint
@@ -101,14 +134,39 @@ func main() {
 	app.Get("/api/v1/assets", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetAssets)
 	app.Get("/api/v1/assets/summary", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetAssetSummary)
 	app.Get("/api/v1/assets/summary-by-category", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetCategorySummary)
+	app.Get("/api/v1/assets/export", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.ExportAssets)
 	app.Get("/api/v1/assets/summary-by-status", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetStatusSummary)
+	app.Get("/api/v1/assets.geojson", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetAssetsGeoJSON)
+	app.Get("/api/v1/assets/clusters", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetAssetClusters)
+	app.Get("/api/v1/assets/nearby", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetNearbyAssets)
+	app.Get("/api/v1/assets/within-bbox", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetAssetsInBBox)
+	app.Post("/api/v1/assets/within-polygon", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetAssetsInPolygon)
+	app.Get("/api/v1/assets/labels/layouts", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetLabelLayouts)
+	app.Post("/api/v1/assets/labels", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.PrintAssetLabels)
 	app.Get("/api/v1/assets/:id", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetAsset)
 	app.Get("/api/v1/assets/:id/qr", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GenerateAssetQR)
+	app.Get("/api/v1/assets/:id/barcode", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GenerateAssetBarcode)
+	app.Get("/api/v1/assets/:id/history", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetAssetHistory)
+	app.Get("/api/v1/assets/:id/custody", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetAssetCustody)
+	app.Get("/api/v1/assets/:id/transitions", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetAssetTransitions)
+	app.Get("/api/v1/assets/:id/depreciation", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetAssetDepreciation)
+	app.Get("/api/v1/assets/:id/sightings", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetAssetSightings)
 
 	// Asset CRUD operations - only admin and manager
 	app.Post("/api/v1/assets", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.CreateAsset)
+	app.Post("/api/v1/assets/import", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.ImportAssets)
 	app.Put("/api/v1/assets/:id", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.UpdateAsset)
+	app.Patch("/api/v1/assets/:id", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.PatchAsset)
 	app.Delete("/api/v1/assets/:id", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.DeleteAsset)
+	app.Post("/api/v1/assets/:id/transitions", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.TransitionAsset)
+	app.Post("/api/v1/assets/depreciation/recalculate", middleware.AuthMiddleware(), middleware.RequireAdmin(), handlers.RecalculateDepreciation)
+
+	// Custody Routes - check-out / check-in by admin and manager
+	app.Post("/api/v1/assets/:id/checkout", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.CheckoutAsset)
+	app.Post("/api/v1/assets/:id/checkin", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.CheckinAsset)
+	app.Get("/api/v1/custody/overdue", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.GetOverdueCustody)
+	app.Get("/api/v1/users/:id/custody", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.GetUserCustody)
+	app.Get("/api/v1/departments/:id/custody", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.GetDepartmentCustody)
 
 	// Category Routes - only admin and manager
 	app.Get("/api/v1/categories", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetCategories)
@@ -122,6 +180,123 @@ func main() {
 	app.Put("/api/v1/departments/:id", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.UpdateDepartment)
 	app.Delete("/api/v1/departments/:id", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.DeleteDepartment)
 
+	// Maintenance Routes - plans and work orders are managed by admin and manager,
+	// assignees can progress their own work orders
+	app.Get("/api/v1/maintenance-plans", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetMaintenancePlans)
+	app.Post("/api/v1/maintenance-plans/generate", middleware.AuthMiddleware(), middleware.RequireAdmin(), handlers.GenerateWorkOrders)
+	app.Get("/api/v1/maintenance-plans/:id", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetMaintenancePlan)
+	app.Post("/api/v1/maintenance-plans", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.CreateMaintenancePlan)
+	app.Put("/api/v1/maintenance-plans/:id", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.UpdateMaintenancePlan)
+	app.Delete("/api/v1/maintenance-plans/:id", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.DeleteMaintenancePlan)
+	app.Get("/api/v1/work-orders", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetWorkOrders)
+	app.Get("/api/v1/work-orders/:id", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetWorkOrder)
+	app.Post("/api/v1/work-orders", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.CreateWorkOrder)
+	app.Put("/api/v1/work-orders/:id", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.UpdateWorkOrder)
+	app.Post("/api/v1/work-orders/:id/status", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.UpdateWorkOrderStatus)
+	app.Post("/api/v1/work-orders/:id/complete", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.CompleteWorkOrder)
+
+	// Disposal Routes - requested by admin and manager, approved or rejected by admin
+	app.Post("/api/v1/assets/:id/disposals", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.CreateDisposalRequest)
+	app.Get("/api/v1/disposals", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.GetDisposalRequests)
+	app.Get("/api/v1/disposals/register", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.GetDisposalRegister)
+	app.Get("/api/v1/disposals/:id", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.GetDisposalRequest)
+	app.Post("/api/v1/disposals/:id/approve", middleware.AuthMiddleware(), middleware.RequireAdmin(), handlers.ApproveDisposalRequest)
+	app.Post("/api/v1/disposals/:id/reject", middleware.AuthMiddleware(), middleware.RequireAdmin(), handlers.RejectDisposalRequest)
+	app.Post("/api/v1/disposals/:id/cancel", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.CancelDisposalRequest)
+
+	// Transfer Routes - initiated by the source department's manager, accepted by the receiving one
+	app.Post("/api/v1/assets/:id/transfers", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.CreateAssetTransfer)
+	app.Get("/api/v1/assets/:id/transfers", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetAssetTransfers)
+	app.Get("/api/v1/departments/:id/transfers", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetDepartmentTransfers)
+	app.Get("/api/v1/transfers/:id", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetTransfer)
+	app.Post("/api/v1/transfers/:id/accept", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.AcceptTransfer)
+	app.Post("/api/v1/transfers/:id/reject", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.RejectTransfer)
+	app.Post("/api/v1/transfers/:id/cancel", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.CancelTransfer)
+
+	// Trash Routes - listing and restoring users, and purging, are admin only
+	app.Get("/api/v1/trash/:type", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.GetTrash)
+	app.Post("/api/v1/trash/:type/:id/restore", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.RestoreFromTrash)
+	app.Delete("/api/v1/trash/:type/:id", middleware.AuthMiddleware(), middleware.RequireAdmin(), handlers.PurgeFromTrash)
+
+	// Search Routes - users are only included for admins
+	app.Get("/api/v1/search", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.Search)
+
+	// Scan Routes - any user can record a sighting
+	app.Post("/api/v1/scans", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.CreateScan)
+
+	// Geofence Routes - zones are defined by admins, alerts are handled by admin and manager
+	app.Get("/api/v1/geofences", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetGeofences)
+	app.Get("/api/v1/geofences/:id", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetGeofence)
+	app.Post("/api/v1/geofences", middleware.AuthMiddleware(), middleware.RequireAdmin(), handlers.CreateGeofence)
+	app.Put("/api/v1/geofences/:id", middleware.AuthMiddleware(), middleware.RequireAdmin(), handlers.UpdateGeofence)
+	app.Delete("/api/v1/geofences/:id", middleware.AuthMiddleware(), middleware.RequireAdmin(), handlers.DeleteGeofence)
+	app.Get("/api/v1/geofence-alerts", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.GetGeofenceAlerts)
+	app.Post("/api/v1/geofence-alerts/:id/acknowledge", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.AcknowledgeGeofenceAlert)
+
+	// Contract Routes - warranties and service contracts are managed by admin and manager
+	app.Get("/api/v1/contracts", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetContracts)
+	app.Get("/api/v1/contracts/expiring", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetExpiringContracts)
+	app.Get("/api/v1/contracts/:id", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetContract)
+	app.Post("/api/v1/contracts", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.CreateContract)
+	app.Put("/api/v1/contracts/:id", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.UpdateContract)
+	app.Delete("/api/v1/contracts/:id", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.DeleteContract)
+	app.Get("/api/v1/assets/:id/contracts", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetAssetContracts)
+	app.Get("/api/v1/contract-alerts", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.GetContractAlerts)
+	app.Post("/api/v1/contract-alerts/:id/acknowledge", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.AcknowledgeContractAlert)
+
+	// Vendor Routes - manufacturers, suppliers and service providers are managed by admin and manager
+	app.Get("/api/v1/vendors", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetVendors)
+	app.Get("/api/v1/vendors/summary", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetVendorSummary)
+	app.Get("/api/v1/vendors/:id", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetVendor)
+	app.Post("/api/v1/vendors", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.CreateVendor)
+	app.Put("/api/v1/vendors/:id", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.UpdateVendor)
+	app.Delete("/api/v1/vendors/:id", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.DeleteVendor)
+	app.Get("/api/v1/vendors/:id/assets", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetVendorAssets)
+	app.Post("/api/v1/vendors/:id/contacts", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.CreateVendorContact)
+	app.Put("/api/v1/vendors/:id/contacts/:contactId", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.UpdateVendorContact)
+	app.Delete("/api/v1/vendors/:id/contacts/:contactId", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.DeleteVendorContact)
+
+	// Meter Routes - meters and rules are managed by admin and manager, readings are submitted by users
+	app.Get("/api/v1/assets/:id/meters", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetAssetMeters)
+	app.Post("/api/v1/assets/:id/meters", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.CreateMeter)
+	app.Get("/api/v1/assets/:id/usage", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetAssetUsage)
+	app.Get("/api/v1/meters/:id", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetMeter)
+	app.Put("/api/v1/meters/:id", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.UpdateMeter)
+	app.Delete("/api/v1/meters/:id", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.DeleteMeter)
+	app.Get("/api/v1/meters/:id/readings", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetMeterReadings)
+	app.Post("/api/v1/meters/:id/readings", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.CreateMeterReading)
+	app.Post("/api/v1/meters/:id/rules", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.CreateMeterRule)
+	app.Put("/api/v1/meters/:id/rules/:ruleId", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.UpdateMeterRule)
+	app.Delete("/api/v1/meters/:id/rules/:ruleId", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.DeleteMeterRule)
+	app.Get("/api/v1/maintenance-due-events", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetMaintenanceDueEvents)
+	app.Post("/api/v1/maintenance-due-events/:id/acknowledge", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.AcknowledgeMaintenanceDueEvent)
+
+	// Stock Routes - parts and stock are managed by admin and manager, users issue parts to their work orders
+	app.Get("/api/v1/parts", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetParts)
+	app.Get("/api/v1/parts/:id", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetPart)
+	app.Post("/api/v1/parts", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.CreatePart)
+	app.Put("/api/v1/parts/:id", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.UpdatePart)
+	app.Delete("/api/v1/parts/:id", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.DeletePart)
+	app.Put("/api/v1/parts/:id/stock/:locationId", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.UpdateStockRule)
+	app.Get("/api/v1/stock-locations", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetStockLocations)
+	app.Post("/api/v1/stock-locations", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.CreateStockLocation)
+	app.Put("/api/v1/stock-locations/:id", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.UpdateStockLocation)
+	app.Delete("/api/v1/stock-locations/:id", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.DeleteStockLocation)
+	app.Get("/api/v1/stock-levels/low", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetLowStock)
+	app.Get("/api/v1/stock-movements", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetStockMovements)
+	app.Post("/api/v1/stock-movements", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.CreateStockMovement)
+	app.Get("/api/v1/assets/:id/cost-of-ownership", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetAssetCostOfOwnership)
+
+	// Inventory Routes - campaigns are run by admin and manager, scans are submitted by their auditors
+	app.Get("/api/v1/inventory-campaigns", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetInventoryCampaigns)
+	app.Post("/api/v1/inventory-campaigns", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.CreateInventoryCampaign)
+	app.Get("/api/v1/inventory-campaigns/:id", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetInventoryCampaign)
+	app.Put("/api/v1/inventory-campaigns/:id/auditors", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.UpdateInventoryAuditors)
+	app.Get("/api/v1/inventory-campaigns/:id/scans", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetInventoryScans)
+	app.Post("/api/v1/inventory-campaigns/:id/scans", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.CreateInventoryScan)
+	app.Get("/api/v1/inventory-campaigns/:id/reconciliation", middleware.AuthMiddleware(), middleware.RequireUser(), handlers.GetInventoryReconciliation)
+	app.Post("/api/v1/inventory-campaigns/:id/close", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.CloseInventoryCampaign)
+
 	// AI Routes - only admin and manager
 	app.Post("/api/v1/ai/chat", middleware.AuthMiddleware(), middleware.RequireManager(), handlers.HandleAIQuery)
 
@@ -149,3 +324,18 @@ func main() {
 		log.Fatal("Failed to start server:", err)
 	}
 }
+
+// durationFromEnv reads a duration such as "30m" from the environment, falling back to def
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid %s %q, using %s", key, value, def)
+		return def
+	}
+	return d
+}
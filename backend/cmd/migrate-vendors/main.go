@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+
+	"github.com/joho/godotenv"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/models"
+	"sams-backend/internal/vendors"
+)
+
+func main() {
+	apply := flag.Bool("apply", false, "create the vendors and link the assets; without it the clusters are only listed")
+	flag.Parse()
+
+	// Load environment variables
+	if err := godotenv.Load("../../.env"); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	// Initialize database
+	db, err := database.InitDB()
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	// Auto-migrate the vendor registry and the asset links to it
+	if err := db.AutoMigrate(&models.Vendor{}, &models.VendorContact{}, &models.Asset{}, &models.AssetHistory{}); err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
+
+	result, err := vendors.Migrate(db, *apply)
+	if err != nil {
+		log.Fatal("Failed to migrate manufacturers:", err)
+	}
+
+	for _, cluster := range result.Clusters {
+		spellings := make([]string, len(cluster.Names))
+		for i, name := range cluster.Names {
+			spellings[i] = name.Text
+		}
+		target := "new vendor"
+		if cluster.VendorID != nil {
+			target = "existing vendor"
+		}
+		log.Printf("%s (%s, %d assets): %s", cluster.Canonical, target, cluster.Assets, strings.Join(spellings, " | "))
+	}
+
+	if !*apply {
+		log.Printf("Dry run: %d manufacturer clusters found. Run with -apply to create the vendors and link the assets.", len(result.Clusters))
+		return
+	}
+	log.Printf("Vendor migration completed: %d vendors created, %d assets linked", result.VendorsCreated, result.AssetsLinked)
+}
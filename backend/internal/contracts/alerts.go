@@ -0,0 +1,56 @@
+// Package contracts raises alerts for warranties and service contracts that are about to expire
+package contracts
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"sams-backend/internal/models"
+)
+
+// RaiseExpiryAlerts creates an alert for every contract that ends within its alert lead time,
+// counting its end date as covered, and has not been alerted for its current end date. It returns
+// the number of alerts raised.
+func RaiseExpiryAlerts(db *gorm.DB) (int, error) {
+	var contracts []models.Contract
+	err := db.Where("end_date >= CURRENT_DATE AND end_date - alert_days <= CURRENT_DATE").
+		Where("NOT EXISTS (SELECT 1 FROM contract_alerts WHERE contract_alerts.contract_id = contracts.id AND contract_alerts.end_date = contracts.end_date)").
+		Find(&contracts).Error
+	if err != nil {
+		return 0, err
+	}
+
+	raised := 0
+	for _, contract := range contracts {
+		alert := models.ContractAlert{ContractID: contract.ID, EndDate: contract.EndDate, Status: models.ContractAlertOpen}
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&alert)
+		if result.Error != nil {
+			return raised, result.Error
+		}
+		if result.RowsAffected > 0 {
+			raised++
+		}
+	}
+	return raised, nil
+}
+
+// StartExpiryAlerts runs RaiseExpiryAlerts in the background at the given interval
+func StartExpiryAlerts(db *gorm.DB, every time.Duration) {
+	go func() {
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+
+		for {
+			raised, err := RaiseExpiryAlerts(db)
+			if err != nil {
+				log.Printf("Contract expiry alerts failed: %v", err)
+			} else if raised > 0 {
+				log.Printf("Contract expiry alerts raised %d alerts", raised)
+			}
+			<-ticker.C
+		}
+	}()
+}
@@ -55,6 +55,36 @@ func InitDB() (*gorm.DB, error) {
 	return db, nil
 }
 
+// legacyUniqueConstraints lists table-wide unique constraints that were replaced by unique indexes
+// ignoring soft-deleted rows, so values of records in the trash can be reused
+var legacyUniqueConstraints = []struct{ table, constraint string }{
+	{"assets", "assets_serial_number_key"},
+	{"categories", "categories_name_key"},
+	{"departments", "departments_name_key"},
+	{"users", "users_username_key"},
+	{"users", "users_email_key"},
+}
+
+// DropLegacyUniqueConstraints removes the unique constraints listed in legacyUniqueConstraints
+func DropLegacyUniqueConstraints(db *gorm.DB) error {
+	for _, legacy := range legacyUniqueConstraints {
+		if err := db.Exec(fmt.Sprintf("ALTER TABLE IF EXISTS %s DROP CONSTRAINT IF EXISTS %s", legacy.table, legacy.constraint)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TranslateError converts a constraint violation reported by the database into the matching gorm
+// error, such as gorm.ErrDuplicatedKey, for callers that handle it. Other errors are returned as
+// they are.
+func TranslateError(db *gorm.DB, err error) error {
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok && err != nil {
+		return translator.Translate(err)
+	}
+	return err
+}
+
 // GetDB returns the database instance
 func GetDB() *gorm.DB {
 	return DB
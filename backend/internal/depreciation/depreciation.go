@@ -0,0 +1,208 @@
+package depreciation
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"sams-backend/internal/models"
+)
+
+// Supported depreciation methods
+const (
+	StraightLine     = "straight_line"
+	DecliningBalance = "declining_balance"
+	SumOfYearsDigits = "sum_of_years_digits"
+	DefaultMethod    = StraightLine
+)
+
+// Schedule period lengths
+const (
+	PeriodYear  = "year"
+	PeriodMonth = "month"
+)
+
+const (
+	monthsPerYear = 12
+	// decliningMultiple gives double-declining balance when no rate is configured
+	decliningMultiple = 2.0
+)
+
+// ErrInsufficientData is returned when an asset lacks the cost, acquisition date or expected life
+// needed to compute depreciation
+var ErrInsufficientData = errors.New("asset needs acquisition_cost, acquisition_date and expected_life_years for depreciation")
+
+// Input holds everything needed to depreciate a single asset
+type Input struct {
+	Method      string    `json:"method"`
+	Cost        float64   `json:"acquisition_cost"`
+	Salvage     float64   `json:"salvage_value"`
+	LifeYears   int       `json:"expected_life_years"`
+	RatePercent float64   `json:"depreciation_rate"`
+	StartDate   time.Time `json:"acquisition_date"`
+}
+
+// Period is one row of a depreciation schedule
+type Period struct {
+	Period                  int       `json:"period"`
+	StartDate               time.Time `json:"start_date"`
+	EndDate                 time.Time `json:"end_date"`
+	Depreciation            float64   `json:"depreciation"`
+	AccumulatedDepreciation float64   `json:"accumulated_depreciation"`
+	BookValue               float64   `json:"book_value"`
+}
+
+// IsValidMethod reports whether the method is one of the supported methods
+func IsValidMethod(method string) bool {
+	switch method {
+	case StraightLine, DecliningBalance, SumOfYearsDigits:
+		return true
+	}
+	return false
+}
+
+// ResolveMethod returns the method configured on the asset, falling back to its category and
+// then to straight-line
+func ResolveMethod(asset *models.Asset, category *models.Category) string {
+	if IsValidMethod(asset.DepreciationMethod) {
+		return asset.DepreciationMethod
+	}
+	if category != nil && IsValidMethod(category.DepreciationMethod) {
+		return category.DepreciationMethod
+	}
+	return DefaultMethod
+}
+
+// FromAsset builds the depreciation input for an asset; category may be nil
+func FromAsset(asset *models.Asset, category *models.Category) (Input, error) {
+	if asset.AcquisitionCost <= 0 || asset.AcquisitionDate == nil || asset.ExpectedLifeYears == nil || *asset.ExpectedLifeYears < 1 {
+		return Input{}, ErrInsufficientData
+	}
+	if asset.SalvageValue < 0 || asset.SalvageValue > asset.AcquisitionCost {
+		return Input{}, errors.New("salvage_value must be between 0 and acquisition_cost")
+	}
+
+	return Input{
+		Method:      ResolveMethod(asset, category),
+		Cost:        asset.AcquisitionCost,
+		Salvage:     asset.SalvageValue,
+		LifeYears:   *asset.ExpectedLifeYears,
+		RatePercent: asset.DepreciationRate,
+		StartDate:   *asset.AcquisitionDate,
+	}, nil
+}
+
+// annualCharges returns the depreciation charged in each year of the asset's life
+func (in Input) annualCharges() []float64 {
+	base := in.Cost - in.Salvage
+	life := in.LifeYears
+	charges := make([]float64, life)
+
+	switch in.Method {
+	case SumOfYearsDigits:
+		digits := float64(life*(life+1)) / 2
+		for year := 1; year <= life; year++ {
+			charges[year-1] = base * float64(life-year+1) / digits
+		}
+
+	case DecliningBalance:
+		// Rate is the configured annual percentage, or double-declining when unset
+		rate := in.RatePercent / 100
+		if rate <= 0 {
+			rate = decliningMultiple / float64(life)
+		}
+		book := in.Cost
+		for year := 1; year <= life; year++ {
+			charge := book * rate
+			// Never depreciate below salvage, and write down to salvage in the final year
+			if book-charge < in.Salvage || year == life {
+				charge = book - in.Salvage
+			}
+			charges[year-1] = charge
+			book -= charge
+		}
+
+	default:
+		for year := range charges {
+			charges[year] = base / float64(life)
+		}
+	}
+
+	return charges
+}
+
+// BookValueAt returns the book value of the asset at the given time, prorating the current
+// year's charge by whole months elapsed
+func (in Input) BookValueAt(at time.Time) float64 {
+	months := monthsBetween(in.StartDate, at)
+	if months <= 0 {
+		return round(in.Cost)
+	}
+
+	charges := in.annualCharges()
+	if months >= in.LifeYears*monthsPerYear {
+		return round(in.Salvage)
+	}
+
+	value := in.Cost
+	for year := 0; year < months/monthsPerYear; year++ {
+		value -= charges[year]
+	}
+	value -= charges[months/monthsPerYear] * float64(months%monthsPerYear) / monthsPerYear
+
+	return round(math.Max(value, in.Salvage))
+}
+
+// Schedule returns the projected depreciation over the asset's whole life, per year or per month
+func (in Input) Schedule(period string) []Period {
+	charges := in.annualCharges()
+
+	var schedule []Period
+	accumulated := 0.0
+	book := in.Cost
+	for year, charge := range charges {
+		if period == PeriodMonth {
+			for month := 0; month < monthsPerYear; month++ {
+				index := year*monthsPerYear + month
+				amount := charge / monthsPerYear
+				accumulated += amount
+				book -= amount
+				schedule = append(schedule, Period{
+					Period:                  index + 1,
+					StartDate:               in.StartDate.AddDate(0, index, 0),
+					EndDate:                 in.StartDate.AddDate(0, index+1, -1),
+					Depreciation:            round(amount),
+					AccumulatedDepreciation: round(accumulated),
+					BookValue:               round(book),
+				})
+			}
+			continue
+		}
+
+		accumulated += charge
+		book -= charge
+		schedule = append(schedule, Period{
+			Period:                  year + 1,
+			StartDate:               in.StartDate.AddDate(year, 0, 0),
+			EndDate:                 in.StartDate.AddDate(year+1, 0, -1),
+			Depreciation:            round(charge),
+			AccumulatedDepreciation: round(accumulated),
+			BookValue:               round(book),
+		})
+	}
+
+	return schedule
+}
+
+// monthsBetween returns the number of whole months from start to end
+func monthsBetween(start, end time.Time) int {
+	months := (end.Year()-start.Year())*monthsPerYear + int(end.Month()) - int(start.Month())
+	if end.Day() < start.Day() {
+		months--
+	}
+	return months
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,147 @@
+package depreciation
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"sams-backend/internal/models"
+)
+
+var acquired = time.Date(2020, 1, 15, 0, 0, 0, 0, time.UTC)
+
+func TestScheduleYearly(t *testing.T) {
+	tests := []struct {
+		name  string
+		input Input
+		want  []float64 // depreciation charged each year
+		book  []float64 // book value at the end of each year
+	}{
+		{
+			"straight line",
+			Input{Method: StraightLine, Cost: 10000, Salvage: 1000, LifeYears: 5},
+			[]float64{1800, 1800, 1800, 1800, 1800},
+			[]float64{8200, 6400, 4600, 2800, 1000},
+		},
+		{
+			"sum of the years' digits",
+			Input{Method: SumOfYearsDigits, Cost: 10000, Salvage: 1000, LifeYears: 5},
+			[]float64{3000, 2400, 1800, 1200, 600},
+			[]float64{7000, 4600, 2800, 1600, 1000},
+		},
+		{
+			"double declining balance writes down to salvage in the final year",
+			Input{Method: DecliningBalance, Cost: 10000, Salvage: 1000, LifeYears: 5},
+			[]float64{4000, 2400, 1440, 864, 296},
+			[]float64{6000, 3600, 2160, 1296, 1000},
+		},
+		{
+			"declining balance stops at salvage",
+			Input{Method: DecliningBalance, Cost: 10000, Salvage: 3000, LifeYears: 5},
+			[]float64{4000, 2400, 600, 0, 0},
+			[]float64{6000, 3600, 3000, 3000, 3000},
+		},
+		{
+			"declining balance at a configured rate",
+			Input{Method: DecliningBalance, Cost: 1000, LifeYears: 4, RatePercent: 30},
+			[]float64{300, 210, 147, 343},
+			[]float64{700, 490, 343, 0},
+		},
+		{
+			"unknown methods fall back to straight line",
+			Input{Method: "", Cost: 900, LifeYears: 3},
+			[]float64{300, 300, 300},
+			[]float64{600, 300, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input.StartDate = acquired
+			schedule := tt.input.Schedule(PeriodYear)
+			if len(schedule) != len(tt.want) {
+				t.Fatalf("got %d periods, want %d", len(schedule), len(tt.want))
+			}
+			for i, period := range schedule {
+				if period.Period != i+1 || period.Depreciation != tt.want[i] || period.BookValue != tt.book[i] ||
+					period.AccumulatedDepreciation != round(tt.input.Cost-tt.book[i]) {
+					t.Errorf("year %d = %+v, want depreciation %v, book value %v", i+1, period, tt.want[i], tt.book[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScheduleMonthly(t *testing.T) {
+	input := Input{Method: StraightLine, Cost: 10000, Salvage: 1000, LifeYears: 5, StartDate: acquired}
+	schedule := input.Schedule(PeriodMonth)
+	if len(schedule) != 60 {
+		t.Fatalf("got %d periods, want 60", len(schedule))
+	}
+
+	first, last := schedule[0], schedule[59]
+	if first.Depreciation != 150 || first.BookValue != 9850 || !first.StartDate.Equal(acquired) ||
+		!first.EndDate.Equal(time.Date(2020, 2, 14, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("first month = %+v", first)
+	}
+	if last.Period != 60 || last.AccumulatedDepreciation != 9000 || last.BookValue != 1000 ||
+		!last.EndDate.Equal(time.Date(2025, 1, 14, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("last month = %+v", last)
+	}
+}
+
+func TestBookValueAt(t *testing.T) {
+	straightLine := Input{Method: StraightLine, Cost: 10000, Salvage: 1000, LifeYears: 5, StartDate: acquired}
+	sumOfDigits := Input{Method: SumOfYearsDigits, Cost: 10000, Salvage: 1000, LifeYears: 5, StartDate: acquired}
+
+	tests := []struct {
+		name  string
+		input Input
+		at    time.Time
+		want  float64
+	}{
+		{"before acquisition", straightLine, time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC), 10000},
+		{"within the first month", straightLine, time.Date(2020, 2, 14, 0, 0, 0, 0, time.UTC), 10000},
+		{"after a whole month", straightLine, time.Date(2020, 2, 15, 0, 0, 0, 0, time.UTC), 9850},
+		{"eighteen months", straightLine, time.Date(2021, 7, 15, 0, 0, 0, 0, time.UTC), 7300},
+		{"a day short of eighteen months", straightLine, time.Date(2021, 7, 14, 0, 0, 0, 0, time.UTC), 7450},
+		{"prorates the year's charge", sumOfDigits, time.Date(2021, 4, 15, 0, 0, 0, 0, time.UTC), 6400},
+		{"at the end of its life", straightLine, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), 1000},
+		{"long after its life", straightLine, time.Date(2040, 1, 1, 0, 0, 0, 0, time.UTC), 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.input.BookValueAt(tt.at); got != tt.want {
+				t.Errorf("BookValueAt(%s) = %v, want %v", tt.at.Format("2006-01-02"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromAsset(t *testing.T) {
+	life := 5
+	asset := &models.Asset{AcquisitionCost: 10000, SalvageValue: 1000, AcquisitionDate: &acquired, ExpectedLifeYears: &life}
+	category := &models.Category{DepreciationMethod: SumOfYearsDigits}
+
+	input, err := FromAsset(asset, category)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if input.Method != SumOfYearsDigits || input.Cost != 10000 || input.Salvage != 1000 || input.LifeYears != 5 || !input.StartDate.Equal(acquired) {
+		t.Errorf("FromAsset = %+v", input)
+	}
+
+	asset.DepreciationMethod = DecliningBalance
+	if input, _ := FromAsset(asset, category); input.Method != DecliningBalance {
+		t.Errorf("asset method: got %s, want %s", input.Method, DecliningBalance)
+	}
+	if input, _ := FromAsset(&models.Asset{AcquisitionCost: 1, AcquisitionDate: &acquired, ExpectedLifeYears: &life}, nil); input.Method != DefaultMethod {
+		t.Errorf("default method: got %s, want %s", input.Method, DefaultMethod)
+	}
+
+	if _, err := FromAsset(&models.Asset{AcquisitionCost: 10000, AcquisitionDate: &acquired}, nil); !errors.Is(err, ErrInsufficientData) {
+		t.Errorf("missing life: got %v, want ErrInsufficientData", err)
+	}
+	if _, err := FromAsset(&models.Asset{AcquisitionCost: 100, SalvageValue: 200, AcquisitionDate: &acquired, ExpectedLifeYears: &life}, nil); err == nil {
+		t.Error("salvage above cost: expected an error")
+	}
+}
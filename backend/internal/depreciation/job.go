@@ -0,0 +1,104 @@
+package depreciation
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"sams-backend/internal/lifecycle"
+	"sams-backend/internal/models"
+)
+
+// recalculationBatchSize is the number of assets loaded per batch during recalculation
+const recalculationBatchSize = 500
+
+// errAssetChanged skips an asset that was edited or disposed after its batch was loaded
+var errAssetChanged = errors.New("asset changed since it was loaded")
+
+// Recalculate sets current_value to the computed book value for every non-disposed asset that
+// has enough data to depreciate. Each change is recorded in the asset history without a user.
+// An asset edited or disposed since its batch was loaded is left for the next run, and an asset
+// that fails to update is logged and skipped. It returns the number of assets whose value changed.
+func Recalculate(db *gorm.DB, now time.Time) (int, error) {
+	var categories []models.Category
+	if err := db.Find(&categories).Error; err != nil {
+		return 0, err
+	}
+	categoryByID := make(map[string]*models.Category, len(categories))
+	for i := range categories {
+		categoryByID[categories[i].ID.String()] = &categories[i]
+	}
+
+	updated := 0
+	var assets []models.Asset
+	result := db.Where("status <> ?", lifecycle.StatusDisposed).FindInBatches(&assets, recalculationBatchSize, func(_ *gorm.DB, _ int) error {
+		for i := range assets {
+			asset := &assets[i]
+
+			var category *models.Category
+			if asset.CategoryID != nil {
+				category = categoryByID[asset.CategoryID.String()]
+			}
+
+			input, err := FromAsset(asset, category)
+			if err != nil {
+				continue
+			}
+
+			value := input.BookValueAt(now)
+			if value == asset.CurrentValue {
+				continue
+			}
+
+			err = db.Transaction(func(tx *gorm.DB) error {
+				result := tx.Model(asset).Where("version = ? AND status <> ?", asset.Version, lifecycle.StatusDisposed).
+					UpdateColumns(map[string]interface{}{"current_value": value, "version": gorm.Expr("version + 1")})
+				if result.Error != nil {
+					return result.Error
+				}
+				if result.RowsAffected == 0 {
+					return errAssetChanged
+				}
+				return tx.Create(&models.AssetHistory{
+					AssetID:   asset.ID,
+					Field:     "current_value",
+					OldValue:  strconv.FormatFloat(asset.CurrentValue, 'f', -1, 64),
+					NewValue:  strconv.FormatFloat(value, 'f', -1, 64),
+					ChangedAt: now,
+				}).Error
+			})
+			if errors.Is(err, errAssetChanged) {
+				continue
+			}
+			if err != nil {
+				log.Printf("Depreciation recalculation skipped asset %s: %v", asset.ID, err)
+				continue
+			}
+			updated++
+		}
+		return nil
+	})
+
+	return updated, result.Error
+}
+
+// StartRecalculation runs Recalculate in the background at the given interval
+func StartRecalculation(db *gorm.DB, every time.Duration) {
+	go func() {
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+
+		for {
+			updated, err := Recalculate(db, time.Now())
+			if err != nil {
+				log.Printf("Depreciation recalculation failed: %v", err)
+			} else if updated > 0 {
+				log.Printf("Depreciation recalculation updated %d assets", updated)
+			}
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,269 @@
+// Package filter parses the compact filter and sort expressions accepted by list endpoints,
+// such as filter=current_value>1000000;status in (active,maintenance) and sort=-current_value,name
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Type is the value type of a filterable field
+type Type int
+
+// Field value types
+const (
+	String Type = iota
+	Number
+	Date
+	UUID
+)
+
+// Field describes a column that can be filtered and sorted on
+type Field struct {
+	Column string
+	Type   Type
+}
+
+// Fields maps the names accepted in expressions to their columns
+type Fields map[string]Field
+
+// Condition is one comparison of a filter expression
+type Condition struct {
+	Column string
+	Op     string
+	Values []interface{}
+}
+
+// Supported operators; "~" is a case-insensitive substring match
+var comparisonOps = []string{">=", "<=", "!=", "=", ">", "<", "~"}
+
+// Parse parses a filter expression made of clauses joined by ";". Each clause is a field, an
+// operator and a value, for example current_value>=1000, name~pump, status in (active,maintenance),
+// department_id is null. Values containing separators can be quoted with ' or ".
+func Parse(expr string, fields Fields) ([]Condition, error) {
+	var conditions []Condition
+	for _, clause := range split(expr, ';') {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+		condition, err := parseClause(clause, fields)
+		if err != nil {
+			return nil, fmt.Errorf("filter %q: %v", clause, err)
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, nil
+}
+
+func parseClause(clause string, fields Fields) (Condition, error) {
+	end := 0
+	for end < len(clause) && isIdentChar(clause[end]) {
+		end++
+	}
+	name := strings.ToLower(clause[:end])
+	field, ok := fields[name]
+	if !ok {
+		return Condition{}, fmt.Errorf("unknown field %q", clause[:end])
+	}
+
+	rest := strings.TrimSpace(clause[end:])
+	lower := strings.ToLower(rest)
+
+	switch {
+	case lower == "is null":
+		return Condition{Column: field.Column, Op: "IS NULL"}, nil
+	case lower == "is not null":
+		return Condition{Column: field.Column, Op: "IS NOT NULL"}, nil
+	case hasKeyword(lower, "in"), hasKeyword(lower, "not in"):
+		op, list := "IN", rest[len("in"):]
+		if hasKeyword(lower, "not in") {
+			op, list = "NOT IN", rest[len("not in"):]
+		}
+		list = strings.TrimSpace(list)
+		if len(list) < 2 || list[0] != '(' || list[len(list)-1] != ')' {
+			return Condition{}, errors.New("in expects a parenthesised list")
+		}
+		var values []interface{}
+		for _, raw := range split(list[1:len(list)-1], ',') {
+			value, err := convert(unquote(raw), field.Type)
+			if err != nil {
+				return Condition{}, err
+			}
+			values = append(values, value)
+		}
+		if len(values) == 0 {
+			return Condition{}, errors.New("in expects at least one value")
+		}
+		return Condition{Column: field.Column, Op: op, Values: []interface{}{values}}, nil
+	}
+
+	for _, op := range comparisonOps {
+		if !strings.HasPrefix(rest, op) {
+			continue
+		}
+		raw := unquote(rest[len(op):])
+		switch op {
+		case "~":
+			if field.Type != String {
+				return Condition{}, errors.New("~ only applies to text fields")
+			}
+			return Condition{Column: field.Column, Op: "ILIKE", Values: []interface{}{"%" + escapeLike(raw) + "%"}}, nil
+		case ">", ">=", "<", "<=":
+			if field.Type != Number && field.Type != Date {
+				return Condition{}, fmt.Errorf("%s only applies to numeric and date fields", op)
+			}
+		}
+		value, err := convert(raw, field.Type)
+		if err != nil {
+			return Condition{}, err
+		}
+		if op == "!=" {
+			op = "<>"
+		}
+		return Condition{Column: field.Column, Op: op, Values: []interface{}{value}}, nil
+	}
+
+	return Condition{}, errors.New("expected an operator (=, !=, >, >=, <, <=, ~, in, not in, is null)")
+}
+
+// Apply adds the conditions to the query
+func Apply(db *gorm.DB, conditions []Condition) *gorm.DB {
+	for _, condition := range conditions {
+		if len(condition.Values) == 0 {
+			db = db.Where(condition.Column + " " + condition.Op)
+			continue
+		}
+		sql := condition.Column + " " + condition.Op + " ?"
+		if condition.Op == "ILIKE" {
+			sql += ` ESCAPE '\'`
+		}
+		db = db.Where(sql, condition.Values...)
+	}
+	return db
+}
+
+// SortKey is one column of a sort order
+type SortKey struct {
+	Column string
+	Desc   bool
+	// Expr, when set, sorts on an expression such as a search rank instead of Column
+	Expr clause.Expression
+}
+
+// Sort parses a comma-separated sort expression where each field may be prefixed with "-" for
+// descending order
+func Sort(expr string, fields Fields) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		desc := false
+		if strings.HasPrefix(part, "-") {
+			desc = true
+			part = part[1:]
+		} else if strings.HasPrefix(part, "+") {
+			part = part[1:]
+		}
+		field, ok := fields[strings.ToLower(part)]
+		if !ok {
+			return nil, fmt.Errorf("cannot sort by %q", part)
+		}
+		keys = append(keys, SortKey{Column: field.Column, Desc: desc})
+	}
+	return keys, nil
+}
+
+// Convert parses a raw query value into the Go value for the field type
+func Convert(raw string, t Type) (interface{}, error) {
+	return convert(strings.TrimSpace(raw), t)
+}
+
+func convert(raw string, t Type) (interface{}, error) {
+	switch t {
+	case Number:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", raw)
+		}
+		return value, nil
+	case Date:
+		for _, layout := range []string{"2006-01-02", time.RFC3339} {
+			if value, err := time.Parse(layout, raw); err == nil {
+				return value, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not a date (YYYY-MM-DD)", raw)
+	case UUID:
+		value, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a UUID", raw)
+		}
+		return value, nil
+	}
+	return raw, nil
+}
+
+// split splits s on sep, ignoring separators inside quotes or parentheses
+func split(s string, sep byte) []string {
+	var parts []string
+	var quote byte
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// escapeLike escapes the LIKE wildcards % and _, and the backslash that escapes them, so that
+// a value matches literally
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// unquote trims whitespace and one pair of matching quotes
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// hasKeyword reports whether s starts with the keyword followed by a space or parenthesis
+func hasKeyword(s, keyword string) bool {
+	if !strings.HasPrefix(s, keyword) || len(s) == len(keyword) {
+		return false
+	}
+	next := s[len(keyword)]
+	return next == ' ' || next == '('
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
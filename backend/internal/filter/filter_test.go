@@ -0,0 +1,127 @@
+package filter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var fields = Fields{
+	"name":          {Column: "assets.name", Type: String},
+	"status":        {Column: "assets.status", Type: String},
+	"current_value": {Column: "assets.current_value", Type: Number},
+	"purchased":     {Column: "assets.purchase_date", Type: Date},
+	"department_id": {Column: "assets.department_id", Type: UUID},
+}
+
+func TestParse(t *testing.T) {
+	department := uuid.MustParse("6f1c2a9e-3b5d-4c8e-9f0a-1b2c3d4e5f60")
+	tests := []struct {
+		expr string
+		want []Condition
+	}{
+		{"", nil},
+		{" ; ", nil},
+		{"current_value>1000000", []Condition{{Column: "assets.current_value", Op: ">", Values: []interface{}{1000000.0}}}},
+		{"current_value >= 10.5", []Condition{{Column: "assets.current_value", Op: ">=", Values: []interface{}{10.5}}}},
+		{"current_value<=0", []Condition{{Column: "assets.current_value", Op: "<=", Values: []interface{}{0.0}}}},
+		{"status!=disposed", []Condition{{Column: "assets.status", Op: "<>", Values: []interface{}{"disposed"}}}},
+		{"NAME~pump", []Condition{{Column: "assets.name", Op: "ILIKE", Values: []interface{}{"%pump%"}}}},
+		{`name~50%_off\x`, []Condition{{Column: "assets.name", Op: "ILIKE", Values: []interface{}{`%50\%\_off\\x%`}}}},
+		{"purchased<2024-01-01", []Condition{{Column: "assets.purchase_date", Op: "<", Values: []interface{}{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}}}},
+		{"department_id=" + department.String(), []Condition{{Column: "assets.department_id", Op: "=", Values: []interface{}{department}}}},
+		{"department_id is null", []Condition{{Column: "assets.department_id", Op: "IS NULL"}}},
+		{"department_id IS NOT NULL", []Condition{{Column: "assets.department_id", Op: "IS NOT NULL"}}},
+		{"status in (active, maintenance)", []Condition{{Column: "assets.status", Op: "IN", Values: []interface{}{[]interface{}{"active", "maintenance"}}}}},
+		{"status not in(disposed)", []Condition{{Column: "assets.status", Op: "NOT IN", Values: []interface{}{[]interface{}{"disposed"}}}}},
+		{"current_value in (1,2.5)", []Condition{{Column: "assets.current_value", Op: "IN", Values: []interface{}{[]interface{}{1.0, 2.5}}}}},
+		{`name='a;b';name~"x, (y)"`, []Condition{
+			{Column: "assets.name", Op: "=", Values: []interface{}{"a;b"}},
+			{Column: "assets.name", Op: "ILIKE", Values: []interface{}{"%x, (y)%"}},
+		}},
+		{"name in ('a,b', c)", []Condition{{Column: "assets.name", Op: "IN", Values: []interface{}{[]interface{}{"a,b", "c"}}}}},
+		{"current_value>1; status=active", []Condition{
+			{Column: "assets.current_value", Op: ">", Values: []interface{}{1.0}},
+			{Column: "assets.status", Op: "=", Values: []interface{}{"active"}},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.expr, fields)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %#v, want %#v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr, want string
+	}{
+		{"colour=red", `unknown field "colour"`},
+		{"name", "expected an operator"},
+		{"name is empty", "expected an operator"},
+		{"name>b", "> only applies to numeric and date fields"},
+		{"current_value~1", "~ only applies to text fields"},
+		{"current_value=lots", `"lots" is not a number`},
+		{"purchased>yesterday", `"yesterday" is not a date`},
+		{"department_id=42", `"42" is not a UUID`},
+		{"status in active", "in expects a parenthesised list"},
+		{"current_value in (1, x)", `"x" is not a number`},
+		{"status=active;colour=red", `filter "colour=red"`},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.expr, fields)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %v, want %q", tt.expr, err, tt.want)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	keys, err := Sort("-current_value, +Name,,status", fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SortKey{
+		{Column: "assets.current_value", Desc: true},
+		{Column: "assets.name"},
+		{Column: "assets.status"},
+	}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("Sort = %+v, want %+v", keys, want)
+	}
+
+	if _, err := Sort("-colour", fields); err == nil || !strings.Contains(err.Error(), `cannot sort by "colour"`) {
+		t.Errorf("Sort(-colour) error = %v", err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conditions, err := Parse("current_value>=100;status in (active,maintenance);department_id is null;name~pump", fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rows []map[string]interface{}
+	statement := Apply(db.Table("assets"), conditions).Find(&rows).Statement
+	want := "SELECT * FROM \"assets\" WHERE assets.current_value >= $1 AND assets.status IN ($2,$3) AND assets.department_id IS NULL AND assets.name ILIKE $4 ESCAPE '\\'"
+	if got := statement.SQL.String(); got != want {
+		t.Errorf("SQL = %s\nwant  %s", got, want)
+	}
+	if want := []interface{}{100.0, "active", "maintenance", "%pump%"}; !reflect.DeepEqual(statement.Vars, want) {
+		t.Errorf("vars = %v, want %v", statement.Vars, want)
+	}
+}
@@ -0,0 +1,370 @@
+// Package geo provides the coordinate types, GeoJSON encoding and spatial query conditions used
+// for asset locations. Coordinates are WGS 84 degrees and distances are great-circle distances on
+// a spherical earth, which is accurate to within half a percent.
+package geo
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// EarthRadiusKm is the mean radius of the earth
+const EarthRadiusKm = 6371.0
+
+// kmPerDegree is the length of one degree of latitude
+const kmPerDegree = EarthRadiusKm * math.Pi / 180
+
+// Point is a position in degrees
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// Valid reports whether the point lies within the range of latitudes and longitudes
+func (p Point) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lng >= -180 && p.Lng <= 180
+}
+
+// BBox is a bounding box. MinLng is greater than MaxLng when the box crosses the antimeridian.
+type BBox struct {
+	MinLng, MinLat, MaxLng, MaxLat float64
+}
+
+// ParseBBox parses a bounding box written minLng,minLat,maxLng,maxLat, the order used by GeoJSON
+// and WMS clients such as QGIS
+func ParseBBox(raw string) (BBox, error) {
+	parts := strings.Split(raw, ",")
+	if len(parts) != 4 {
+		return BBox{}, errors.New("bbox must be minLng,minLat,maxLng,maxLat")
+	}
+	var values [4]float64
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return BBox{}, fmt.Errorf("bbox: %q is not a number", part)
+		}
+		values[i] = value
+	}
+	box := BBox{MinLng: values[0], MinLat: values[1], MaxLng: values[2], MaxLat: values[3]}
+	if !(Point{Lat: box.MinLat, Lng: box.MinLng}).Valid() || !(Point{Lat: box.MaxLat, Lng: box.MaxLng}).Valid() || box.MinLat > box.MaxLat {
+		return BBox{}, errors.New("bbox is out of range")
+	}
+	return box, nil
+}
+
+// Contains reports whether the point lies inside the box, edges included
+func (b BBox) Contains(p Point) bool {
+	if p.Lat < b.MinLat || p.Lat > b.MaxLat {
+		return false
+	}
+	if b.MinLng > b.MaxLng {
+		return p.Lng >= b.MinLng || p.Lng <= b.MaxLng
+	}
+	return p.Lng >= b.MinLng && p.Lng <= b.MaxLng
+}
+
+// Polygon is an outer ring with optional holes. Rings need not repeat their first point.
+type Polygon struct {
+	Outer []Point
+	Holes [][]Point
+}
+
+// Validate checks that every ring has at least three points within range
+func (p Polygon) Validate() error {
+	for i, ring := range append([][]Point{p.Outer}, p.Holes...) {
+		if len(ring) < 3 {
+			if i == 0 {
+				return errors.New("polygon needs at least three points")
+			}
+			return errors.New("polygon hole needs at least three points")
+		}
+		for _, point := range ring {
+			if !point.Valid() {
+				return fmt.Errorf("polygon point %v,%v is out of range", point.Lng, point.Lat)
+			}
+		}
+	}
+	return nil
+}
+
+// Bounds returns the bounding box of the outer ring
+func (p Polygon) Bounds() BBox {
+	box := BBox{MinLng: math.Inf(1), MinLat: math.Inf(1), MaxLng: math.Inf(-1), MaxLat: math.Inf(-1)}
+	for _, point := range p.Outer {
+		box.MinLng = math.Min(box.MinLng, point.Lng)
+		box.MinLat = math.Min(box.MinLat, point.Lat)
+		box.MaxLng = math.Max(box.MaxLng, point.Lng)
+		box.MaxLat = math.Max(box.MaxLat, point.Lat)
+	}
+	return box
+}
+
+// Contains reports whether the point lies inside the outer ring and outside every hole
+func (p Polygon) Contains(point Point) bool {
+	if !ringContains(p.Outer, point) {
+		return false
+	}
+	for _, hole := range p.Holes {
+		if ringContains(hole, point) {
+			return false
+		}
+	}
+	return true
+}
+
+// ringContains is the even-odd ray casting test
+func ringContains(ring []Point, point Point) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Lat > point.Lat) != (b.Lat > point.Lat) &&
+			point.Lng < (b.Lng-a.Lng)*(point.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Distance returns the great-circle distance between two points in kilometres
+func Distance(a, b Point) float64 {
+	dLat := (b.Lat - a.Lat) * math.Pi / 180
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+	h := math.Pow(math.Sin(dLat/2), 2) +
+		math.Cos(a.Lat*math.Pi/180)*math.Cos(b.Lat*math.Pi/180)*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// DistanceToPolygon returns the distance in kilometres from a point to the nearest edge of the
+// polygon's outer ring. Edges are measured on a plane centred on the point, which is accurate for
+// the distances of a few kilometres that matter for geofences.
+func DistanceToPolygon(p Polygon, point Point) float64 {
+	// Project to kilometres east and north of the point
+	cos := math.Cos(point.Lat * math.Pi / 180)
+	project := func(q Point) (float64, float64) {
+		return (q.Lng - point.Lng) * kmPerDegree * cos, (q.Lat - point.Lat) * kmPerDegree
+	}
+
+	nearest := math.Inf(1)
+	for i, j := 0, len(p.Outer)-1; i < len(p.Outer); j, i = i, i+1 {
+		ax, ay := project(p.Outer[j])
+		bx, by := project(p.Outer[i])
+		dx, dy := bx-ax, by-ay
+
+		// Position along the edge of the point nearest the origin, clamped to the edge
+		t := 0.0
+		if length := dx*dx + dy*dy; length > 0 {
+			t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/length))
+		}
+		nearest = math.Min(nearest, math.Hypot(ax+t*dx, ay+t*dy))
+	}
+	return nearest
+}
+
+// RadiusBounds returns a box enclosing the circle around center, used to narrow radius queries
+// before computing distances
+func RadiusBounds(center Point, radiusKm float64) BBox {
+	dLat := radiusKm / kmPerDegree
+	box := BBox{MinLat: math.Max(-90, center.Lat-dLat), MaxLat: math.Min(90, center.Lat+dLat), MinLng: -180, MaxLng: 180}
+
+	// Near the poles the circle spans every longitude
+	cos := math.Cos(center.Lat * math.Pi / 180)
+	if dLng := radiusKm / (kmPerDegree * cos); cos > 0 && dLng < 180 {
+		box.MinLng = wrapLng(center.Lng - dLng)
+		box.MaxLng = wrapLng(center.Lng + dLng)
+	}
+	return box
+}
+
+func wrapLng(lng float64) float64 {
+	if lng < -180 {
+		return lng + 360
+	}
+	if lng > 180 {
+		return lng - 360
+	}
+	return lng
+}
+
+// The conditions below apply to the latitude and longitude columns of the queried table
+
+// HasLocation selects rows with both coordinates set
+func HasLocation() clause.Expr {
+	return clause.Expr{SQL: "latitude IS NOT NULL AND longitude IS NOT NULL"}
+}
+
+// WithinBBox selects rows located inside the box
+func WithinBBox(b BBox) clause.Expr {
+	if b.MinLng > b.MaxLng {
+		return clause.Expr{
+			SQL:  "latitude BETWEEN ? AND ? AND (longitude >= ? OR longitude <= ?)",
+			Vars: []interface{}{b.MinLat, b.MaxLat, b.MinLng, b.MaxLng},
+		}
+	}
+	return clause.Expr{
+		SQL:  "latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?",
+		Vars: []interface{}{b.MinLat, b.MaxLat, b.MinLng, b.MaxLng},
+	}
+}
+
+// DistanceFrom is the distance in kilometres of a row from center, by the haversine formula
+func DistanceFrom(center Point) clause.Expr {
+	return clause.Expr{
+		SQL: "(2 * ? * asin(least(1, sqrt(power(sin(radians(latitude - ?) / 2), 2) + " +
+			"cos(radians(?)) * cos(radians(latitude)) * power(sin(radians(longitude - ?) / 2), 2)))))",
+		Vars: []interface{}{EarthRadiusKm, center.Lat, center.Lat, center.Lng},
+	}
+}
+
+// WithinRadius selects rows within radiusKm of center
+func WithinRadius(center Point, radiusKm float64) clause.Expr {
+	return clause.Expr{
+		SQL:  "? AND ? <= ?",
+		Vars: []interface{}{WithinBBox(RadiusBounds(center, radiusKm)), DistanceFrom(center), radiusKm},
+	}
+}
+
+// WithinPolygon selects rows located inside the polygon, using the PostgreSQL polygon type
+func WithinPolygon(p Polygon) clause.Expr {
+	sql := "? AND ?::polygon @> point(longitude, latitude)"
+	vars := []interface{}{WithinBBox(p.Bounds()), polygonLiteral(p.Outer)}
+	for _, hole := range p.Holes {
+		sql += " AND NOT ?::polygon @> point(longitude, latitude)"
+		vars = append(vars, polygonLiteral(hole))
+	}
+	return clause.Expr{SQL: sql, Vars: vars}
+}
+
+// polygonLiteral writes a ring as a PostgreSQL polygon with longitude as x
+func polygonLiteral(ring []Point) string {
+	points := make([]string, len(ring))
+	for i, point := range ring {
+		points[i] = "(" + strconv.FormatFloat(point.Lng, 'f', -1, 64) + "," + strconv.FormatFloat(point.Lat, 'f', -1, 64) + ")"
+	}
+	return "(" + strings.Join(points, ",") + ")"
+}
+
+// Geometry is a GeoJSON geometry object
+type Geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// Feature is a GeoJSON feature
+type Feature struct {
+	Type       string                 `json:"type"`
+	ID         interface{}            `json:"id,omitempty"`
+	Geometry   *Geometry              `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// Value stores the geometry as JSON
+func (g Geometry) Value() (driver.Value, error) {
+	encoded, err := json.Marshal(g)
+	return string(encoded), err
+}
+
+// Scan loads a geometry stored as JSON
+func (g *Geometry) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case []byte:
+		return json.Unmarshal(value, g)
+	case string:
+		return json.Unmarshal([]byte(value), g)
+	}
+	return fmt.Errorf("cannot scan %T into a geometry", src)
+}
+
+// Polygon decodes a Polygon geometry
+func (g Geometry) Polygon() (Polygon, error) {
+	encoded, err := json.Marshal(g)
+	if err != nil {
+		return Polygon{}, err
+	}
+	return ParsePolygon(encoded)
+}
+
+// PointFeature returns a feature located at the point
+func PointFeature(id interface{}, point Point, properties map[string]interface{}) Feature {
+	coordinates, _ := json.Marshal([2]float64{point.Lng, point.Lat})
+	return Feature{
+		Type:       "Feature",
+		ID:         id,
+		Geometry:   &Geometry{Type: "Point", Coordinates: coordinates},
+		Properties: properties,
+	}
+}
+
+// PolygonGeometry encodes a polygon as a GeoJSON geometry, closing its rings
+func PolygonGeometry(p Polygon) *Geometry {
+	var rings [][][2]float64
+	for _, ring := range append([][]Point{p.Outer}, p.Holes...) {
+		positions := make([][2]float64, 0, len(ring)+1)
+		for _, point := range ring {
+			positions = append(positions, [2]float64{point.Lng, point.Lat})
+		}
+		positions = append(positions, positions[0])
+		rings = append(rings, positions)
+	}
+	coordinates, _ := json.Marshal(rings)
+	return &Geometry{Type: "Polygon", Coordinates: coordinates}
+}
+
+// ParsePolygon decodes a GeoJSON Polygon, given as a geometry or a feature holding one
+func ParsePolygon(data []byte) (Polygon, error) {
+	var object struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+		Geometry    *Geometry       `json:"geometry"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return Polygon{}, errors.New("invalid GeoJSON")
+	}
+	if object.Type == "Feature" && object.Geometry != nil {
+		object.Type, object.Coordinates = object.Geometry.Type, object.Geometry.Coordinates
+	}
+	if object.Type != "Polygon" {
+		return Polygon{}, errors.New("GeoJSON geometry must be a Polygon")
+	}
+
+	var rings [][][]float64
+	if err := json.Unmarshal(object.Coordinates, &rings); err != nil || len(rings) == 0 {
+		return Polygon{}, errors.New("invalid Polygon coordinates")
+	}
+
+	var polygon Polygon
+	for i, positions := range rings {
+		var ring []Point
+		for _, position := range positions {
+			if len(position) < 2 {
+				return Polygon{}, errors.New("invalid Polygon coordinates")
+			}
+			ring = append(ring, Point{Lng: position[0], Lat: position[1]})
+		}
+		// GeoJSON rings repeat their first position at the end
+		if n := len(ring); n > 1 && ring[0] == ring[n-1] {
+			ring = ring[:n-1]
+		}
+		if i == 0 {
+			polygon.Outer = ring
+		} else {
+			polygon.Holes = append(polygon.Holes, ring)
+		}
+	}
+	return polygon, polygon.Validate()
+}
+
+// tileCells is the number of grid cells across a map tile when clustering, giving cells of
+// about 64 pixels on 256 pixel tiles
+const tileCells = 4
+
+// GridSize returns the side in degrees of the clustering grid cells at a web map zoom level
+func GridSize(zoom int) float64 {
+	return 360 / math.Pow(2, float64(zoom)) / tileCells
+}
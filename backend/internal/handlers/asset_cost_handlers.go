@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/models"
+)
+
+// AssetCostOfOwnership breaks down what an asset has cost over its life so far
+type AssetCostOfOwnership struct {
+	AssetID         uuid.UUID `json:"asset_id"`
+	AcquisitionCost float64   `json:"acquisition_cost"`
+	// LabourCost and WorkOrderPartsCost are recorded on the asset's work orders; the latter
+	// covers parts bought for the job rather than issued from stock
+	LabourCost         float64 `json:"labour_cost"`
+	WorkOrderPartsCost float64 `json:"work_order_parts_cost"`
+	// StockPartsCost is the value of the parts issued from stock to the asset
+	StockPartsCost float64 `json:"stock_parts_cost"`
+	// ContractCost is the asset's share of the contracts covering it, each contract's cost being
+	// split evenly across its assets
+	ContractCost float64 `json:"contract_cost"`
+	TotalCost    float64 `json:"total_cost"`
+}
+
+// GetAssetCostOfOwnership godoc
+// @Summary Get asset cost of ownership
+// @Description Get the acquisition cost of an asset plus the labour, parts and contract costs it
+// @Description has incurred since. Cancelled work orders are left out.
+// @Tags assets
+// @Produce  json
+// @Param id path string true "Asset ID"
+// @Success 200 {object} AssetCostOfOwnership
+// @Failure 404 {object} fiber.Map
+// @Router /assets/{id}/cost-of-ownership [get]
+func GetAssetCostOfOwnership(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var asset models.Asset
+	if err := db.Select("id", "acquisition_cost").First(&asset, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Asset not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch asset"})
+	}
+
+	cost := AssetCostOfOwnership{AssetID: asset.ID, AcquisitionCost: asset.AcquisitionCost}
+
+	var workOrders struct {
+		LabourCost float64
+		PartsCost  float64
+	}
+	err := db.Model(&models.WorkOrder{}).
+		Select("COALESCE(SUM(labour_cost), 0) AS labour_cost, COALESCE(SUM(parts_cost), 0) AS parts_cost").
+		Where("asset_id = ? AND status <> ?", asset.ID, models.WorkOrderCancelled).
+		Scan(&workOrders).Error
+	if err == nil {
+		cost.LabourCost = workOrders.LabourCost
+		cost.WorkOrderPartsCost = workOrders.PartsCost
+		err = db.Model(&models.StockMovement{}).
+			Select("COALESCE(SUM(-quantity * unit_cost), 0)").
+			Where("asset_id = ? AND kind = ?", asset.ID, models.StockIssue).
+			Scan(&cost.StockPartsCost).Error
+	}
+	if err == nil {
+		err = db.Model(&models.Contract{}).
+			Select("COALESCE(SUM(contracts.cost / (SELECT COUNT(*) FROM contract_assets shares WHERE shares.contract_id = contracts.id)), 0)").
+			Joins("JOIN contract_assets ON contract_assets.contract_id = contracts.id").
+			Where("contract_assets.asset_id = ?", asset.ID).
+			Scan(&cost.ContractCost).Error
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to calculate cost of ownership"})
+	}
+
+	cost.TotalCost = cost.AcquisitionCost + cost.LabourCost + cost.WorkOrderPartsCost + cost.StockPartsCost + cost.ContractCost
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": cost})
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/models"
+)
+
+// exportBatchSize is the number of assets loaded from the database at a time while exporting
+const exportBatchSize = 500
+
+// defaultExportColumns is the column order used when no columns are requested; category and
+// department hold names rather than IDs
+var defaultExportColumns = []string{
+	"id", "name", "description", "category", "department", "type", "model", "serial_number",
+	"asset_tag", "manufacturer", "acquisition_cost", "current_value", "depreciation_rate", "salvage_value",
+	"depreciation_method", "status", "condition", "criticality", "latitude", "longitude", "address",
+	"building_room", "acquisition_date", "expected_life_years", "maintenance_schedule",
+	"certifications", "standards", "audit_info", "last_seen_at", "created_at", "updated_at",
+}
+
+// parseExportColumns validates the comma-separated columns parameter
+func parseExportColumns(param string) ([]string, error) {
+	if param == "" {
+		return defaultExportColumns, nil
+	}
+
+	var columns []string
+	for _, column := range strings.Split(param, ",") {
+		column = strings.TrimSpace(column)
+		if column == "" {
+			continue
+		}
+		if _, ok := assetFieldIndex[column]; !ok || column == "deleted_at" {
+			return nil, fmt.Errorf("unknown column %q", column)
+		}
+		columns = append(columns, column)
+	}
+	if len(columns) == 0 {
+		return defaultExportColumns, nil
+	}
+	return columns, nil
+}
+
+// exportValue returns the raw value of an asset column, with category and department as names
+func exportValue(asset *models.Asset, column string) interface{} {
+	switch column {
+	case "category":
+		if asset.Category != nil {
+			return asset.Category.Name
+		}
+		return nil
+	case "department":
+		if asset.Department != nil {
+			return asset.Department.Name
+		}
+		return nil
+	}
+	return reflect.ValueOf(asset).Elem().Field(assetFieldIndex[column]).Interface()
+}
+
+// exportText returns an asset column rendered as text for CSV output
+func exportText(asset *models.Asset, column string) string {
+	value := exportValue(asset, column)
+	if value == nil {
+		return ""
+	}
+	if text, ok := value.(string); ok {
+		return escapeFormula(text)
+	}
+	return formatFieldValue(reflect.ValueOf(value))
+}
+
+// escapeFormula prefixes text that a spreadsheet would run as a formula with a quote, so that
+// names and descriptions entered by users are always shown as text
+func escapeFormula(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+// exportCell returns an asset column as an XLSX cell value. Numbers and times keep their type so
+// they can be summed and sorted in a spreadsheet, with times in UTC shown through timeStyle;
+// other values are written as text, escaped like CSV text.
+func exportCell(asset *models.Asset, column string, timeStyle int) interface{} {
+	value := exportValue(asset, column)
+	if value == nil {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return v.Interface()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return excelize.Cell{StyleID: timeStyle, Value: t.UTC()}
+	}
+	if v.Kind() == reflect.String {
+		return escapeFormula(v.String())
+	}
+	return formatFieldValue(v)
+}
+
+// spooledFile is a temporary file holding a response body; closing it once sent removes it
+type spooledFile struct {
+	*os.File
+}
+
+func (f spooledFile) Close() error {
+	err := f.File.Close()
+	os.Remove(f.Name())
+	return err
+}
+
+// spool writes a response body to a temporary file, which is returned rewound. CSV and JSON
+// Lines have no closing that a client could miss, so an export is written in full before it is
+// sent; a failure then becomes an error response instead of a silently truncated file.
+func spool(write func(w *bufio.Writer) error) (*spooledFile, int, error) {
+	file, err := os.CreateTemp("", "sams-export-*")
+	if err != nil {
+		return nil, 0, err
+	}
+	spooled := &spooledFile{File: file}
+
+	w := bufio.NewWriter(file)
+	if err = write(w); err == nil {
+		err = w.Flush()
+	}
+	var size int64
+	if err == nil {
+		size, err = file.Seek(0, io.SeekCurrent)
+	}
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		spooled.Close()
+		return nil, 0, err
+	}
+	return spooled, int(size), nil
+}
+
+// ExportAssets godoc
+// @Summary Export assets
+// @Description Export every asset matching the GetAssets filters as CSV, XLSX or JSON Lines.
+// @Description In CSV and XLSX, text starting with = + - @, a tab or a carriage return is
+// @Description prefixed with ' so that spreadsheets do not run it as a formula.
+// @Tags assets
+// @Produce  text/csv
+// @Param format query string false "csv (default), xlsx or jsonl"
+// @Param columns query string false "Comma-separated columns to include"
+// @Param search query string false "Search term"
+// @Param category query string false "Category names, comma-separated"
+// @Param status query string false "Asset statuses, comma-separated"
+// @Param condition query string false "Asset conditions, comma-separated"
+// @Param filter query string false "Filter expression, as for GetAssets"
+// @Success 200 {file} file
+// @Failure 400 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /assets/export [get]
+func ExportAssets(c *fiber.Ctx) error {
+	format := c.Query("format", "csv")
+	columns, err := parseExportColumns(c.Query("columns"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	query, err := applyAssetFilters(c, database.GetDB().Model(&models.Asset{}))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	// Batches are keyed on the primary key, so the export is ordered by asset ID
+	query = query.Preload("Category").Preload("Department")
+
+	filename := "assets_" + time.Now().Format("20060102_150405")
+	var write func(w *bufio.Writer) error
+
+	switch format {
+	case "csv":
+		c.Set("Content-Type", "text/csv")
+		filename += ".csv"
+		write = func(w *bufio.Writer) error {
+			out := csv.NewWriter(w)
+			if err := out.Write(columns); err != nil {
+				return err
+			}
+			err := eachExportBatch(query, func(assets []models.Asset) error {
+				for i := range assets {
+					record := make([]string, len(columns))
+					for j, column := range columns {
+						record[j] = exportText(&assets[i], column)
+					}
+					if err := out.Write(record); err != nil {
+						return err
+					}
+				}
+				out.Flush()
+				return out.Error()
+			})
+			out.Flush()
+			return err
+		}
+
+	case "jsonl":
+		c.Set("Content-Type", "application/x-ndjson")
+		filename += ".jsonl"
+		write = func(w *bufio.Writer) error {
+			encoder := json.NewEncoder(w)
+			return eachExportBatch(query, func(assets []models.Asset) error {
+				for i := range assets {
+					record := make(map[string]interface{}, len(columns))
+					for _, column := range columns {
+						record[column] = exportValue(&assets[i], column)
+					}
+					if err := encoder.Encode(record); err != nil {
+						return err
+					}
+				}
+				return w.Flush()
+			})
+		}
+
+	case "xlsx":
+		c.Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		filename += ".xlsx"
+		write = func(w *bufio.Writer) error {
+			workbook := excelize.NewFile()
+			defer workbook.Close()
+			timeStyle, err := workbook.NewStyle(&excelize.Style{NumFmt: 22})
+			if err != nil {
+				return err
+			}
+
+			// The stream writer spools rows to disk, so memory stays bounded
+			sheet, err := workbook.NewStreamWriter("Sheet1")
+			if err != nil {
+				return err
+			}
+
+			header := make([]interface{}, len(columns))
+			for i, column := range columns {
+				header[i] = column
+			}
+			if err := sheet.SetRow("A1", header); err != nil {
+				return err
+			}
+
+			rowNumber := 2
+			err = eachExportBatch(query, func(assets []models.Asset) error {
+				for i := range assets {
+					row := make([]interface{}, len(columns))
+					for j, column := range columns {
+						row[j] = exportCell(&assets[i], column, timeStyle)
+					}
+					cell, _ := excelize.CoordinatesToCellName(1, rowNumber)
+					if err := sheet.SetRow(cell, row); err != nil {
+						return err
+					}
+					rowNumber++
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if err := sheet.Flush(); err != nil {
+				return err
+			}
+			return workbook.Write(w)
+		}
+
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Format must be csv, xlsx or jsonl",
+		})
+	}
+
+	body, size, err := spool(write)
+	if err != nil {
+		log.Printf("Asset export failed: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to export assets",
+		})
+	}
+
+	c.Set("Content-Disposition", "attachment; filename="+filename)
+	return c.SendStream(body, size)
+}
+
+// eachExportBatch loads the query results in batches and passes each batch to fn
+func eachExportBatch(query *gorm.DB, fn func(assets []models.Asset) error) error {
+	var assets []models.Asset
+	return query.FindInBatches(&assets, exportBatchSize, func(_ *gorm.DB, _ int) error {
+		return fn(assets)
+	}).Error
+}
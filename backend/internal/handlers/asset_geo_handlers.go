@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm/clause"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/filter"
+	"sams-backend/internal/geo"
+	"sams-backend/internal/models"
+)
+
+// maxRadiusKm bounds the radius of nearby searches
+const maxRadiusKm = 1000
+
+// NearbyAsset is an asset found by a radius search, with its distance from the search centre
+type NearbyAsset struct {
+	models.Asset
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// assetLocation returns the coordinates of an asset, or false when it has none
+func assetLocation(asset *models.Asset) (geo.Point, bool) {
+	if asset.Latitude == nil || asset.Longitude == nil {
+		return geo.Point{}, false
+	}
+	return geo.Point{Lat: *asset.Latitude, Lng: *asset.Longitude}, true
+}
+
+// GetNearbyAssets godoc
+// @Summary Find assets near a point
+// @Description Get assets within a radius of a point, nearest first. The GetAssets filters apply.
+// @Tags assets
+// @Produce  json
+// @Param lat query number true "Latitude of the centre"
+// @Param lng query number true "Longitude of the centre"
+// @Param radius_km query number false "Radius in kilometres (default 1)"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Router /assets/nearby [get]
+func GetNearbyAssets(c *fiber.Ctx) error {
+	lat, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+	lng, lngErr := strconv.ParseFloat(c.Query("lng"), 64)
+	center := geo.Point{Lat: lat, Lng: lng}
+	if latErr != nil || lngErr != nil || !center.Valid() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "lat and lng must be valid coordinates",
+		})
+	}
+	radius, err := strconv.ParseFloat(c.Query("radius_km", "1"), 64)
+	if err != nil || radius <= 0 || radius > maxRadiusKm {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "radius_km must be between 0 and " + strconv.Itoa(maxRadiusKm),
+		})
+	}
+
+	query, err := applyAssetFilters(c, database.GetDB().Model(&models.Asset{}))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	query = query.Where(geo.HasLocation()).Where(geo.WithinRadius(center, radius))
+
+	var assets []models.Asset
+	order := []filter.SortKey{{Expr: geo.DistanceFrom(center)}}
+	pagination, err := listPage(c, query, order, 20, &assets, "Category", "Department")
+	if err != nil {
+		return pageError(c, err, "Failed to fetch assets")
+	}
+
+	nearby := make([]NearbyAsset, len(assets))
+	for i := range assets {
+		location, _ := assetLocation(&assets[i])
+		nearby[i] = NearbyAsset{Asset: assets[i], DistanceKm: geo.Distance(center, location)}
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       nearby,
+		"message":    "Assets retrieved successfully",
+		"pagination": pagination,
+	})
+}
+
+// GetAssetsInBBox godoc
+// @Summary Find assets in a bounding box
+// @Description Get assets located inside a bounding box. The GetAssets filters, sort and
+// @Description pagination apply.
+// @Tags assets
+// @Produce  json
+// @Param bbox query string true "minLng,minLat,maxLng,maxLat"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Router /assets/within-bbox [get]
+func GetAssetsInBBox(c *fiber.Ctx) error {
+	box, err := geo.ParseBBox(c.Query("bbox"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	return listAssetsWithin(c, geo.WithinBBox(box))
+}
+
+// GetAssetsInPolygon godoc
+// @Summary Find assets in a polygon
+// @Description Get assets located inside a GeoJSON Polygon, sent as a geometry or a feature.
+// @Description Holes are excluded. The GetAssets filters, sort and pagination apply.
+// @Tags assets
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Router /assets/within-polygon [post]
+func GetAssetsInPolygon(c *fiber.Ctx) error {
+	polygon, err := geo.ParsePolygon(c.Body())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	return listAssetsWithin(c, geo.WithinPolygon(polygon))
+}
+
+// listAssetsWithin lists the assets matching the request filters and a spatial condition
+func listAssetsWithin(c *fiber.Ctx, within clause.Expr) error {
+	query, err := applyAssetFilters(c, database.GetDB().Model(&models.Asset{}))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	order, err := assetOrder(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	query = query.Where(geo.HasLocation()).Where(within)
+
+	var assets []models.Asset
+	pagination, err := listPage(c, query, order, 20, &assets, "Category", "Department")
+	if err != nil {
+		return pageError(c, err, "Failed to fetch assets")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       assets,
+		"message":    "Assets retrieved successfully",
+		"pagination": pagination,
+	})
+}
+
+// assetFeature returns the GeoJSON feature of an asset with coordinates
+func assetFeature(asset *models.Asset, location geo.Point) geo.Feature {
+	properties := map[string]interface{}{
+		"name":          asset.Name,
+		"serial_number": asset.SerialNumber,
+		"type":          asset.Type,
+		"model":         asset.Model,
+		"manufacturer":  asset.Manufacturer,
+		"status":        asset.Status,
+		"condition":     asset.Condition,
+		"criticality":   asset.Criticality,
+		"current_value": asset.CurrentValue,
+		"address":       asset.Address,
+		"building_room": asset.BuildingRoom,
+		"category":      nil,
+		"department":    nil,
+	}
+	if asset.Category != nil {
+		properties["category"] = asset.Category.Name
+	}
+	if asset.Department != nil {
+		properties["department"] = asset.Department.Name
+	}
+	return geo.PointFeature(asset.ID, location, properties)
+}
+
+// GetAssetsGeoJSON godoc
+// @Summary Export asset locations as GeoJSON
+// @Description Stream the assets with coordinates as a GeoJSON FeatureCollection of points, for
+// @Description GIS tools such as QGIS. The GetAssets filters apply, and bbox limits the area.
+// @Tags assets
+// @Produce  application/geo+json
+// @Param bbox query string false "minLng,minLat,maxLng,maxLat"
+// @Success 200 {file} file
+// @Failure 400 {object} fiber.Map
+// @Router /assets.geojson [get]
+func GetAssetsGeoJSON(c *fiber.Ctx) error {
+	query, err := applyAssetFilters(c, database.GetDB().Model(&models.Asset{}))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	query = query.Where(geo.HasLocation())
+	if raw := c.Query("bbox"); raw != "" {
+		box, err := geo.ParseBBox(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		query = query.Where(geo.WithinBBox(box))
+	}
+	query = query.Preload("Category").Preload("Department")
+
+	c.Set("Content-Type", "application/geo+json")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		w.WriteString(`{"type":"FeatureCollection","features":[`)
+		first := true
+		err := eachExportBatch(query, func(assets []models.Asset) error {
+			for i := range assets {
+				location, _ := assetLocation(&assets[i])
+				encoded, err := json.Marshal(assetFeature(&assets[i], location))
+				if err != nil {
+					return err
+				}
+				if !first {
+					w.WriteByte(',')
+				}
+				first = false
+				w.Write(encoded)
+			}
+			return w.Flush()
+		})
+		// The status line has already been sent, so a failure leaves the collection unclosed for
+		// the client to reject rather than passing off a truncated one as complete
+		if err != nil {
+			log.Printf("GeoJSON export failed: %v", err)
+			return
+		}
+		w.WriteString("]}")
+		w.Flush()
+	})
+	return nil
+}
+
+// clusterMaxZoom is the zoom level from which the map shows individual assets instead of clusters
+const clusterMaxZoom = 16
+
+// maxMapAssets bounds the individual assets returned for one map view
+const maxMapAssets = 2000
+
+// AssetCluster is a group of assets sharing a grid cell of the map
+type AssetCluster struct {
+	Latitude       float64 `json:"latitude"`
+	Longitude      float64 `json:"longitude"`
+	Count          int64   `json:"count"`
+	TotalValue     float64 `json:"total_value"`
+	DominantStatus string  `json:"dominant_status"`
+	// AssetID identifies the asset of a cluster holding a single one
+	AssetID *string `json:"asset_id,omitempty"`
+	// The bounds of the cluster's assets, to zoom the map onto them
+	MinLatitude  float64 `json:"min_latitude"`
+	MinLongitude float64 `json:"min_longitude"`
+	MaxLatitude  float64 `json:"max_latitude"`
+	MaxLongitude float64 `json:"max_longitude"`
+}
+
+// MapAsset is an asset shown individually on the map
+type MapAsset struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name"`
+	SerialNumber string  `json:"serial_number"`
+	Status       string  `json:"status"`
+	CurrentValue float64 `json:"current_value"`
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+}
+
+// GetAssetClusters godoc
+// @Summary Cluster asset locations for a map view
+// @Description Group the assets inside a bounding box into grid cells sized for the zoom level,
+// @Description with their count, total current value and most common status. From zoom 16 the
+// @Description assets are returned individually. The GetAssets filters apply.
+// @Tags assets
+// @Produce  json
+// @Param bbox query string true "minLng,minLat,maxLng,maxLat"
+// @Param zoom query int true "Web map zoom level, 0 to 22"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Router /assets/clusters [get]
+func GetAssetClusters(c *fiber.Ctx) error {
+	box, err := geo.ParseBBox(c.Query("bbox"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	zoom, err := strconv.Atoi(c.Query("zoom"))
+	if err != nil || zoom < 0 || zoom > 22 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "zoom must be between 0 and 22",
+		})
+	}
+
+	query, err := applyAssetFilters(c, database.GetDB().Model(&models.Asset{}))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	query = query.Where(geo.HasLocation()).Where(geo.WithinBBox(box))
+
+	if zoom >= clusterMaxZoom {
+		var assets []MapAsset
+		if err := query.Select("id, name, serial_number, status, current_value, latitude, longitude").
+			Order("id").Limit(maxMapAssets + 1).Scan(&assets).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   true,
+				"message": "Failed to fetch assets",
+			})
+		}
+		truncated := len(assets) > maxMapAssets
+		if truncated {
+			assets = assets[:maxMapAssets]
+		}
+		return c.JSON(fiber.Map{
+			"error": false,
+			"data": fiber.Map{
+				"zoom":      zoom,
+				"clustered": false,
+				"assets":    assets,
+				"truncated": truncated,
+			},
+			"message": "Assets retrieved successfully",
+		})
+	}
+
+	size := geo.GridSize(zoom)
+	clusters := []AssetCluster{}
+	err = query.Select(`avg(latitude) AS latitude, avg(longitude) AS longitude, count(*) AS count,
+		coalesce(sum(current_value), 0) AS total_value,
+		mode() WITHIN GROUP (ORDER BY status) AS dominant_status,
+		CASE WHEN count(*) = 1 THEN min(id::text) END AS asset_id,
+		min(latitude) AS min_latitude, min(longitude) AS min_longitude,
+		max(latitude) AS max_latitude, max(longitude) AS max_longitude`).
+		Group(fmt.Sprintf("floor(longitude / %[1]g), floor(latitude / %[1]g)", size)).
+		Scan(&clusters).Error
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to cluster assets",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data": fiber.Map{
+			"zoom":      zoom,
+			"clustered": true,
+			"cell_size": size,
+			"clusters":  clusters,
+		},
+		"message": "Asset clusters retrieved successfully",
+	})
+}
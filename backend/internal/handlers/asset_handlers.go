@@ -1,16 +1,23 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
-	"github.com/skip2/go-qrcode"
 	"gorm.io/gorm"
 
 	"sams-backend/internal/database"
+	"sams-backend/internal/depreciation"
+	"sams-backend/internal/filter"
+	"sams-backend/internal/labels"
+	"sams-backend/internal/lifecycle"
+	"sams-backend/internal/middleware"
 	"sams-backend/internal/models"
+	"sams-backend/internal/search"
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -150,82 +157,235 @@ func GetAssetSummary(c *fiber.Ctx) error {
 // @Produce  json
 // @Param page query int false "Page number"
 // @Param limit query int false "Items per page"
-// @Param search query string false "Search term"
-// @Param category query string false "Category name"
-// @Param status query string false "Asset status"
+// @Param after query string false "Cursor from next_cursor; switches to keyset pagination"
+// @Param before query string false "Cursor from prev_cursor; switches to keyset pagination"
+// @Param count query bool false "Set to false to skip the total count"
+// @Param search query string false "Search term, matched by full-text and fuzzy search; results are ordered by relevance unless sort is given"
+// @Param category query string false "Category names, comma-separated"
+// @Param department query string false "Department names, comma-separated"
+// @Param status query string false "Asset statuses, comma-separated"
+// @Param condition query string false "Asset conditions, comma-separated"
+// @Param criticality query string false "Criticality levels, comma-separated"
+// @Param manufacturer query string false "Manufacturers, comma-separated"
+// @Param min_value query number false "Minimum current value"
+// @Param max_value query number false "Maximum current value"
+// @Param acquired_from query string false "Earliest acquisition date (YYYY-MM-DD)"
+// @Param acquired_to query string false "Latest acquisition date (YYYY-MM-DD)"
+// @Param min_life query int false "Minimum expected life in years"
+// @Param max_life query int false "Maximum expected life in years"
+// @Param has_coordinates query bool false "Only assets with (or without) coordinates"
+// @Param filter query string false "Filter expression, e.g. current_value>1000000;status in (active,maintenance)"
+// @Param sort query string false "Sort fields, prefix with - for descending, e.g. -current_value,name"
 // @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
 // @Failure 500 {object} fiber.Map
 // @Router /assets [get]
 func GetAssets(c *fiber.Ctx) error {
 	db := database.GetDB()
 	var assets []models.Asset
-	var total int64
 
-	// Pagination
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	db, err := applyAssetFilters(c, db.Model(&models.Asset{}))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	order, err := assetOrder(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
 
-	// Ensure valid pagination values
-	if page < 1 {
-		page = 1
+	pagination, err := listPage(c, db, order, 10, &assets, "Category", "Department", "LastSeenBy", "ManufacturerVendor", "Supplier")
+	if err != nil {
+		return pageError(c, err, "Failed to fetch assets")
 	}
-	if limit < 1 || limit > 100 {
-		limit = 10
+
+	response := fiber.Map{
+		"error":      false,
+		"data":       assets,
+		"message":    "Assets retrieved successfully",
+		"pagination": pagination,
 	}
 
-	offset := (page - 1) * limit
+	// Searches also return a snippet of each asset with the matched words highlighted
+	if term := c.Query("search"); term != "" {
+		ids := make([]uuid.UUID, len(assets))
+		for i, asset := range assets {
+			ids[i] = asset.ID
+		}
+		highlights, err := search.Assets.Snippets(database.GetDB(), search.Parse(term), ids)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   true,
+				"message": "Failed to fetch assets",
+			})
+		}
+		response["highlights"] = highlights
+	}
+
+	return c.JSON(response)
+}
+
+// assetFilterFields are the asset columns accepted in filter and sort expressions
+var assetFilterFields = filter.Fields{
+	"name":                {Column: "name", Type: filter.String},
+	"description":         {Column: "description", Type: filter.String},
+	"type":                {Column: "type", Type: filter.String},
+	"model":               {Column: "model", Type: filter.String},
+	"serial_number":       {Column: "serial_number", Type: filter.String},
+	"asset_tag":           {Column: "asset_tag", Type: filter.String},
+	"manufacturer":        {Column: "manufacturer", Type: filter.String},
+	"manufacturer_id":     {Column: "manufacturer_id", Type: filter.UUID},
+	"supplier_id":         {Column: "supplier_id", Type: filter.UUID},
+	"category_id":         {Column: "category_id", Type: filter.UUID},
+	"department_id":       {Column: "department_id", Type: filter.UUID},
+	"acquisition_cost":    {Column: "acquisition_cost", Type: filter.Number},
+	"current_value":       {Column: "current_value", Type: filter.Number},
+	"depreciation_rate":   {Column: "depreciation_rate", Type: filter.Number},
+	"salvage_value":       {Column: "salvage_value", Type: filter.Number},
+	"depreciation_method": {Column: "depreciation_method", Type: filter.String},
+	"status":              {Column: "status", Type: filter.String},
+	"condition":           {Column: "condition", Type: filter.String},
+	"criticality":         {Column: "criticality", Type: filter.String},
+	"latitude":            {Column: "latitude", Type: filter.Number},
+	"longitude":           {Column: "longitude", Type: filter.Number},
+	"address":             {Column: "address", Type: filter.String},
+	"building_room":       {Column: "building_room", Type: filter.String},
+	"acquisition_date":    {Column: "acquisition_date", Type: filter.Date},
+	"expected_life_years": {Column: "expected_life_years", Type: filter.Number},
+	"last_seen_at":        {Column: "last_seen_at", Type: filter.Date},
+	"created_at":          {Column: "created_at", Type: filter.Date},
+	"updated_at":          {Column: "updated_at", Type: filter.Date},
+}
+
+// assetRangeFilters maps min/max query parameters to the column and bound they apply
+var assetRangeFilters = []struct {
+	param, column, op string
+	valueType         filter.Type
+}{
+	{"min_value", "current_value", ">=", filter.Number},
+	{"max_value", "current_value", "<=", filter.Number},
+	{"min_cost", "acquisition_cost", ">=", filter.Number},
+	{"max_cost", "acquisition_cost", "<=", filter.Number},
+	{"acquired_from", "acquisition_date", ">=", filter.Date},
+	{"acquired_to", "acquisition_date", "<=", filter.Date},
+	{"min_life", "expected_life_years", ">=", filter.Number},
+	{"max_life", "expected_life_years", "<=", filter.Number},
+}
+
+// queryValues returns every value of a query parameter, accepting both repeated parameters and
+// comma-separated lists; the "all" placeholder used by the frontend is ignored
+func queryValues(c *fiber.Ctx, key string) []string {
+	var values []string
+	for _, raw := range c.Context().QueryArgs().PeekMulti(key) {
+		for _, value := range strings.Split(string(raw), ",") {
+			if value = strings.TrimSpace(value); value != "" && value != "all" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
 
+// applyAssetFilters applies the query filters shared by the asset listing endpoints. Filters
+// taking values accept several, and the filter parameter takes a filter expression. The returned
+// error describes an invalid parameter.
+func applyAssetFilters(c *fiber.Ctx, db *gorm.DB) (*gorm.DB, error) {
 	// Search functionality
-	if search := c.Query("search"); search != "" {
-		searchTerm := "%" + search + "%"
-		db = db.Where("name ILIKE ? OR serial_number ILIKE ? OR model ILIKE ? OR description ILIKE ?", searchTerm, searchTerm, searchTerm, searchTerm)
+	if term := c.Query("search"); term != "" {
+		db = search.Assets.Filter(db, search.Parse(term))
 	}
 
 	// Apply filters
-	if categoryName := c.Query("category"); categoryName != "" && categoryName != "all" {
-		// Find category ID by name
-		var category models.Category
-		if err := db.Where("name = ?", categoryName).First(&category).Error; err == nil {
-			db = db.Where("category_id = ?", category.ID)
+	if names := queryValues(c, "category"); len(names) > 0 {
+		db = db.Where("category_id IN (?)", database.GetDB().Model(&models.Category{}).Select("id").Where("name IN ?", names))
+	}
+	if names := queryValues(c, "department"); len(names) > 0 {
+		db = db.Where("department_id IN (?)", database.GetDB().Model(&models.Department{}).Select("id").Where("name IN ?", names))
+	}
+
+	for _, param := range []string{"category_id", "department_id", "manufacturer_id", "supplier_id"} {
+		values := queryValues(c, param)
+		if len(values) == 0 {
+			continue
+		}
+		ids := make([]uuid.UUID, len(values))
+		for i, value := range values {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %q is not a UUID", param, value)
+			}
+			ids[i] = id
 		}
+		db = db.Where(param+" IN ?", ids)
 	}
 
-	if status := c.Query("status"); status != "" && status != "all" {
-		db = db.Where("status = ?", status)
+	for _, param := range []string{"status", "condition", "criticality"} {
+		if values := queryValues(c, param); len(values) > 0 {
+			db = db.Where(param+" IN ?", values)
+		}
 	}
 
-	if condition := c.Query("condition"); condition != "" {
-		db = db.Where("condition = ?", condition)
+	if values := queryValues(c, "manufacturer"); len(values) > 0 {
+		for i := range values {
+			values[i] = strings.ToLower(values[i])
+		}
+		db = db.Where("LOWER(manufacturer) IN ?", values)
 	}
 
-	if err := db.Model(&models.Asset{}).Count(&total).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to count assets",
-		})
+	for _, r := range assetRangeFilters {
+		raw := c.Query(r.param)
+		if raw == "" {
+			continue
+		}
+		value, err := filter.Convert(raw, r.valueType)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", r.param, err)
+		}
+		db = db.Where(r.column+" "+r.op+" ?", value)
 	}
 
-	if err := db.Preload("Category").Preload("Department").Offset(offset).Limit(limit).Order("created_at DESC").Find(&assets).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to fetch assets",
-		})
+	if raw := c.Query("has_coordinates"); raw != "" {
+		hasCoordinates, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("has_coordinates: %q is not a boolean", raw)
+		}
+		if hasCoordinates {
+			db = db.Where("latitude IS NOT NULL AND longitude IS NOT NULL")
+		} else {
+			db = db.Where("latitude IS NULL OR longitude IS NULL")
+		}
 	}
 
-	// Calculate total pages
-	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	if expr := c.Query("filter"); expr != "" {
+		conditions, err := filter.Parse(expr, assetFilterFields)
+		if err != nil {
+			return nil, err
+		}
+		db = filter.Apply(db, conditions)
+	}
 
-	return c.JSON(fiber.Map{
-		"error":   false,
-		"data":    assets,
-		"message": "Assets retrieved successfully",
-		"pagination": fiber.Map{
-			"page":        page,
-			"limit":       limit,
-			"total":       total,
-			"total_pages": totalPages,
-		},
-	})
+	return db, nil
+}
+
+// assetOrder returns the sort order requested by the sort parameter. Searches default to the
+// most relevant first and other listings to the newest first.
+func assetOrder(c *fiber.Ctx) ([]filter.SortKey, error) {
+	order, err := filter.Sort(c.Query("sort"), assetFilterFields)
+	if err != nil {
+		return nil, err
+	}
+	if len(order) == 0 {
+		if term := c.Query("search"); term != "" {
+			order = append(order, filter.SortKey{Expr: search.Assets.Rank(search.Parse(term)), Desc: true})
+		}
+		order = append(order, filter.SortKey{Column: "created_at", Desc: true})
+	}
+	return order, nil
 }
 
 // GetAsset returns a single asset by ID
@@ -243,7 +403,7 @@ func GetAsset(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := db.Preload("Category").Preload("Department").First(&asset, "id = ?", assetID).Error; err != nil {
+	if err := db.Preload("Category").Preload("Department").Preload("LastSeenBy").Preload("ManufacturerVendor").Preload("Supplier").First(&asset, "id = ?", assetID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error":   true,
@@ -256,6 +416,7 @@ func GetAsset(c *fiber.Ctx) error {
 		})
 	}
 
+	c.Set(fiber.HeaderETag, assetETag(&asset))
 	return c.JSON(fiber.Map{
 		"error":   false,
 		"data":    asset,
@@ -277,30 +438,51 @@ func CreateAsset(c *fiber.Ctx) error {
 		})
 	}
 
-	// Validate required fields
-	if asset.Name == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+	if err := prepareNewAsset(db, &asset); err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
 			"error":   true,
-			"message": "Asset name is required",
+			"message": err.Message,
 		})
 	}
 
-	if asset.SerialNumber == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Serial number is required",
-		})
+	var createdBy *uuid.UUID
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		createdBy = &userID
 	}
-
-	// Check if serial number already exists
-	var existingAsset models.Asset
-	if err := db.Where("serial_number = ?", asset.SerialNumber).First(&existingAsset).Error; err == nil {
-		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&asset).Error; err != nil {
+			return err
+		}
+		event := lifecycle.InitialEvent(&asset, "Asset created", createdBy)
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+		if asset.HomeGeofenceID == nil {
+			return nil
+		}
+		return checkGeofence(c, tx, &asset, models.LocationSourceAssetCreate)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   true,
-			"message": "Asset with this serial number already exists",
+			"message": "Failed to create asset",
 		})
 	}
 
+	// Reload with category information
+	db.Preload("Category").First(&asset, asset.ID)
+
+	c.Set(fiber.HeaderETag, assetETag(&asset))
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"error":   false,
+		"data":    asset,
+		"message": "Asset created successfully",
+	})
+}
+
+// prepareNewAsset validates an asset about to be created, applies default values and computes
+// its current value. The returned error carries the HTTP status to respond with.
+func prepareNewAsset(db *gorm.DB, asset *models.Asset) *fiber.Error {
 	// Set default values
 	if asset.Status == "" {
 		asset.Status = "active"
@@ -312,21 +494,74 @@ func CreateAsset(c *fiber.Ctx) error {
 		asset.Criticality = "low"
 	}
 
-	if err := db.Create(&asset).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create asset",
-		})
+	if err := validateAsset(db, asset); err != nil {
+		return err
 	}
+	applyDepreciation(db, asset)
 
-	// Reload with category information
-	db.Preload("Category").First(&asset, asset.ID)
+	return nil
+}
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"error":   false,
-		"data":    asset,
-		"message": "Asset created successfully",
-	})
+// validateAsset checks the required fields, serial number uniqueness and enumerated values of an
+// asset. The returned error carries the HTTP status to respond with.
+func validateAsset(db *gorm.DB, asset *models.Asset) *fiber.Error {
+	// Validate required fields
+	if asset.Name == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Asset name is required")
+	}
+
+	if asset.SerialNumber == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Serial number is required")
+	}
+
+	// Check if serial number already exists on another asset
+	var existingAsset models.Asset
+	if err := db.Where("serial_number = ? AND id <> ?", asset.SerialNumber, asset.ID).First(&existingAsset).Error; err == nil {
+		return fiber.NewError(fiber.StatusConflict, "Asset with this serial number already exists")
+	}
+	if asset.AssetTag != "" {
+		if err := db.Where("asset_tag = ? AND id <> ?", asset.AssetTag, asset.ID).First(&existingAsset).Error; err == nil {
+			return fiber.NewError(fiber.StatusConflict, "Asset with this tag number already exists")
+		}
+	}
+
+	if asset.Status == lifecycle.StatusDisposed {
+		return fiber.NewError(fiber.StatusBadRequest, "Assets can only be disposed through an approved disposal request")
+	}
+	if !isOneOf(asset.Status, lifecycle.StatusActive, lifecycle.StatusInactive, lifecycle.StatusMaintenance) {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid status")
+	}
+	if !isOneOf(asset.Condition, "excellent", "good", "fair", "poor", "critical") {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid condition")
+	}
+	if !isOneOf(asset.Criticality, "low", "medium", "high", "critical") {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid criticality")
+	}
+
+	if asset.DepreciationMethod != "" && !depreciation.IsValidMethod(asset.DepreciationMethod) {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid depreciation method")
+	}
+
+	if asset.HomeGeofenceID != nil {
+		if err := db.Select("id").First(&models.Geofence{}, "id = ?", *asset.HomeGeofenceID).Error; err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Home geofence not found")
+		}
+	}
+	if err := linkAssetVendors(db, asset); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// isOneOf reports whether value equals one of the allowed values
+func isOneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
 }
 
 // UpdateAsset updates an existing asset
@@ -357,6 +592,24 @@ func UpdateAsset(c *fiber.Ctx) error {
 		})
 	}
 
+	// If-Match is optional here for existing clients, but a stale ETag is still rejected
+	if err := checkIfMatch(c, &asset, false); err != nil {
+		c.Set(fiber.HeaderETag, assetETag(&asset))
+		return c.Status(err.Code).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Message,
+		})
+	}
+
+	if asset.Status == lifecycle.StatusDisposed {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   true,
+			"message": "Disposed assets cannot be edited",
+		})
+	}
+
+	before := asset
+
 	var updateData models.Asset
 	if err := c.BodyParser(&updateData); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -375,7 +628,15 @@ func UpdateAsset(c *fiber.Ctx) error {
 	if updateData.CategoryID != nil {
 		asset.CategoryID = updateData.CategoryID
 	}
-	if updateData.DepartmentID != nil {
+	if updateData.DepartmentID != nil && !sameDepartment(asset.DepartmentID, updateData.DepartmentID) {
+		// Unassigned assets can be given a department directly; moving between departments
+		// needs the receiving department to accept a transfer
+		if asset.DepartmentID != nil {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":   true,
+				"message": "Asset department can only be changed through POST /api/v1/assets/:id/transfers",
+			})
+		}
 		asset.DepartmentID = updateData.DepartmentID
 	}
 	if updateData.Type != "" {
@@ -384,6 +645,16 @@ func UpdateAsset(c *fiber.Ctx) error {
 	if updateData.Model != "" {
 		asset.Model = updateData.Model
 	}
+	if updateData.AssetTag != "" && updateData.AssetTag != asset.AssetTag {
+		var existingAsset models.Asset
+		if err := db.Where("asset_tag = ? AND id <> ?", updateData.AssetTag, asset.ID).First(&existingAsset).Error; err == nil {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":   true,
+				"message": "Asset with this tag number already exists",
+			})
+		}
+		asset.AssetTag = updateData.AssetTag
+	}
 	if updateData.Manufacturer != "" {
 		asset.Manufacturer = updateData.Manufacturer
 	}
@@ -396,8 +667,23 @@ func UpdateAsset(c *fiber.Ctx) error {
 	if updateData.DepreciationRate != 0 {
 		asset.DepreciationRate = updateData.DepreciationRate
 	}
-	if updateData.Status != "" {
-		asset.Status = updateData.Status
+	if updateData.SalvageValue != 0 {
+		asset.SalvageValue = updateData.SalvageValue
+	}
+	if updateData.DepreciationMethod != "" {
+		if !depreciation.IsValidMethod(updateData.DepreciationMethod) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Invalid depreciation method",
+			})
+		}
+		asset.DepreciationMethod = updateData.DepreciationMethod
+	}
+	if updateData.Status != "" && updateData.Status != asset.Status {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   true,
+			"message": "Asset status can only be changed through POST /api/v1/assets/:id/transitions",
+		})
 	}
 	if updateData.Condition != "" {
 		asset.Condition = updateData.Condition
@@ -417,6 +703,27 @@ func UpdateAsset(c *fiber.Ctx) error {
 	if updateData.BuildingRoom != "" {
 		asset.BuildingRoom = updateData.BuildingRoom
 	}
+	if updateData.HomeGeofenceID != nil {
+		if err := db.Select("id").First(&models.Geofence{}, "id = ?", *updateData.HomeGeofenceID).Error; err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Home geofence not found",
+			})
+		}
+		asset.HomeGeofenceID = updateData.HomeGeofenceID
+	}
+	if updateData.ManufacturerID != nil {
+		asset.ManufacturerID = updateData.ManufacturerID
+	}
+	if updateData.SupplierID != nil {
+		asset.SupplierID = updateData.SupplierID
+	}
+	if err := linkAssetVendors(db, &asset); err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Message,
+		})
+	}
 	if updateData.AcquisitionDate != nil {
 		asset.AcquisitionDate = updateData.AcquisitionDate
 	}
@@ -436,7 +743,26 @@ func UpdateAsset(c *fiber.Ctx) error {
 		asset.AuditInfo = updateData.AuditInfo
 	}
 
-	if err := db.Save(&asset).Error; err != nil {
+	applyDepreciation(db, &asset)
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := saveAssetVersion(tx, &asset); err != nil {
+			return err
+		}
+		if locationChanged(&before, &asset) {
+			if err := checkGeofence(c, tx, &asset, models.LocationSourceAssetUpdate); err != nil {
+				return err
+			}
+		}
+		return recordAssetHistory(c, tx, before, asset)
+	})
+	if err != nil {
+		if errors.Is(err, errAssetVersionConflict) {
+			return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+				"error":   true,
+				"message": errAssetVersionConflict.Message,
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   true,
 			"message": "Failed to update asset",
@@ -446,6 +772,7 @@ func UpdateAsset(c *fiber.Ctx) error {
 	// Reload with category and department information
 	db.Preload("Category").Preload("Department").First(&asset, "id = ?", id)
 
+	c.Set(fiber.HeaderETag, assetETag(&asset))
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"error":   false,
 		"data":    asset,
@@ -454,6 +781,7 @@ func UpdateAsset(c *fiber.Ctx) error {
 }
 
 // DeleteAsset deletes an asset
+// @Failure 409 {object} fiber.Map
 // @Failure 500 {object} fiber.Map
 // @Router /assets/{id} [delete]
 func DeleteAsset(c *fiber.Ctx) error {
@@ -480,6 +808,12 @@ func DeleteAsset(c *fiber.Ctx) error {
 			"message": "Failed to fetch asset",
 		})
 	}
+	if asset.Status == lifecycle.StatusDisposed {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   true,
+			"message": "Disposed assets are kept in the disposal register and cannot be deleted",
+		})
+	}
 
 	if err := db.Delete(&asset).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -494,7 +828,19 @@ func DeleteAsset(c *fiber.Ctx) error {
 	})
 }
 
-// GenerateAssetQR generates a QR code for an asset
+// GenerateAssetQR godoc
+// @Summary Generate an asset QR code
+// @Description Generate a QR code encoding a deep link to the asset, as configured by
+// @Description QR_LINK_TEMPLATE, for scanning back into the system
+// @Tags assets
+// @Produce  png
+// @Param id path string true "Asset ID"
+// @Param size query int false "Width and height in pixels, 64 to 2048 (default 256)"
+// @Param level query string false "Error correction level: L, M (default), Q or H"
+// @Param format query string false "png (default) or svg"
+// @Success 200 {file} file
+// @Failure 400 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
 // @Failure 500 {object} fiber.Map
 // @Router /assets/{id}/qr [get]
 func GenerateAssetQR(c *fiber.Ctx) error {
@@ -509,6 +855,28 @@ func GenerateAssetQR(c *fiber.Ctx) error {
 		})
 	}
 
+	size := c.QueryInt("size", 256)
+	if size < 64 || size > 2048 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "size must be between 64 and 2048",
+		})
+	}
+	level, err := labels.ParseLevel(c.Query("level"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	format := c.Query("format", "png")
+	if format != "png" && format != "svg" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "format must be png or svg",
+		})
+	}
+
 	if err := db.First(&asset, "id = ?", assetID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -522,12 +890,15 @@ func GenerateAssetQR(c *fiber.Ctx) error {
 		})
 	}
 
-	// Create QR code content
-	qrContent := fmt.Sprintf("Asset: %s\nSerial: %s\nCategory: %s\nLocation: %s",
-		asset.Name, asset.SerialNumber, asset.Type, asset.Address)
-
 	// Generate QR code
-	qrCode, err := qrcode.Encode(qrContent, qrcode.Medium, 256)
+	var qrCode []byte
+	if format == "svg" {
+		qrCode, err = labels.QRSVG(assetLink(asset.ID), level, size)
+		c.Set("Content-Type", "image/svg+xml")
+	} else {
+		qrCode, err = labels.QRPNG(assetLink(asset.ID), level, size)
+		c.Set("Content-Type", "image/png")
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   true,
@@ -535,9 +906,7 @@ func GenerateAssetQR(c *fiber.Ctx) error {
 		})
 	}
 
-	// Set response headers
-	c.Set("Content-Type", "image/png")
-	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=asset_%s_qr.png", asset.SerialNumber))
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=asset_%s_qr.%s", asset.SerialNumber, format))
 
 	return c.Send(qrCode)
 }
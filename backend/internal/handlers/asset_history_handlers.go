@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/filter"
+	"sams-backend/internal/middleware"
+	"sams-backend/internal/models"
+)
+
+// untrackedAssetFields lists asset JSON fields that are not recorded in the history
+var untrackedAssetFields = map[string]bool{
+	"id":                  true,
+	"category":            true,
+	"department":          true,
+	"home_geofence":       true,
+	"manufacturer_vendor": true,
+	"supplier":            true,
+	"last_seen_at":        true,
+	"last_seen_by_id":     true,
+	"last_seen_by":        true,
+	"created_at":          true,
+	"updated_at":          true,
+	"deleted_at":          true,
+	"version":             true,
+}
+
+// diffAssets compares two versions of an asset and returns one history entry per changed field
+func diffAssets(before, after models.Asset) []models.AssetHistory {
+	var changes []models.AssetHistory
+
+	beforeValue := reflect.ValueOf(before)
+	afterValue := reflect.ValueOf(after)
+	assetType := beforeValue.Type()
+
+	for i := 0; i < assetType.NumField(); i++ {
+		field := strings.Split(assetType.Field(i).Tag.Get("json"), ",")[0]
+		if field == "" || field == "-" || untrackedAssetFields[field] {
+			continue
+		}
+
+		oldValue := formatFieldValue(beforeValue.Field(i))
+		newValue := formatFieldValue(afterValue.Field(i))
+		if oldValue == newValue {
+			continue
+		}
+
+		changes = append(changes, models.AssetHistory{
+			AssetID:  after.ID,
+			Field:    field,
+			OldValue: oldValue,
+			NewValue: newValue,
+		})
+	}
+
+	return changes
+}
+
+// formatFieldValue renders a field value as text, as stored in the history and written to exports
+func formatFieldValue(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch value := v.Interface().(type) {
+	case time.Time:
+		return value.Format(time.RFC3339)
+	case uuid.UUID:
+		return value.String()
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// recordAssetHistory stores the field-level changes between two versions of an asset,
+// attributed to the user making the current request
+func recordAssetHistory(c *fiber.Ctx, tx *gorm.DB, before, after models.Asset) error {
+	changes := diffAssets(before, after)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	var changedBy *uuid.UUID
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		changedBy = &userID
+	}
+
+	now := time.Now()
+	for i := range changes {
+		changes[i].ChangedByID = changedBy
+		changes[i].ChangedAt = now
+	}
+
+	return tx.Create(&changes).Error
+}
+
+// GetAssetHistory godoc
+// @Summary Get asset change history
+// @Description Get a paginated list of field-level changes made to an asset
+// @Tags assets
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Asset ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Param field query string false "Only changes to this field"
+// @Success 200 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /assets/{id}/history [get]
+func GetAssetHistory(c *fiber.Ctx) error {
+	db := database.GetDB()
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid asset ID",
+		})
+	}
+
+	// History remains readable for soft-deleted assets
+	var asset models.Asset
+	if err := db.Unscoped().First(&asset, "id = ?", assetID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   true,
+				"message": "Asset not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to fetch asset",
+		})
+	}
+
+	query := db.Model(&models.AssetHistory{}).Where("asset_id = ?", assetID)
+	if field := c.Query("field"); field != "" {
+		query = query.Where("field = ?", field)
+	}
+
+	var history []models.AssetHistory
+	order := []filter.SortKey{{Column: "changed_at", Desc: true}, {Column: "field"}}
+	pagination, err := listPage(c, query, order, 20, &history, "ChangedBy")
+	if err != nil {
+		return pageError(c, err, "Failed to fetch asset history")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       history,
+		"message":    "Asset history retrieved successfully",
+		"pagination": pagination,
+	})
+}
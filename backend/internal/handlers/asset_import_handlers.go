@@ -0,0 +1,385 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/lifecycle"
+	"sams-backend/internal/middleware"
+	"sams-backend/internal/models"
+)
+
+// maxImportRows bounds the number of data rows accepted in a single import file
+const maxImportRows = 5000
+
+// importBatchSize is the number of assets inserted per statement during an import
+const importBatchSize = 100
+
+// importRowError describes why a row of an import file was rejected
+type importRowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// assetFieldIndex maps asset JSON field names to their struct field index
+var assetFieldIndex = func() map[string]int {
+	index := make(map[string]int)
+	assetType := reflect.TypeOf(models.Asset{})
+	for i := 0; i < assetType.NumField(); i++ {
+		name := strings.Split(assetType.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			index[name] = i
+		}
+	}
+	return index
+}()
+
+// nonImportableAssetFields are asset fields that cannot be set from an import file
+var nonImportableAssetFields = map[string]bool{
+	"id":         true,
+	"created_at": true,
+	"updated_at": true,
+	"deleted_at": true,
+	"version":    true,
+	// Set by scans
+	"last_seen_at":    true,
+	"last_seen_by_id": true,
+	"last_seen_by":    true,
+}
+
+// setAssetField parses a text value into the asset field with the given JSON name
+func setAssetField(asset *models.Asset, name, value string) error {
+	i, ok := assetFieldIndex[name]
+	if !ok || nonImportableAssetFields[name] {
+		return fmt.Errorf("unknown column %q", name)
+	}
+	field := reflect.ValueOf(asset).Elem().Field(i)
+
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(value)
+	case float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		field.SetFloat(f)
+	case *float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		field.Set(reflect.ValueOf(&f))
+	case *int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("must be a whole number")
+		}
+		field.Set(reflect.ValueOf(&n))
+	case *time.Time:
+		t, err := parseImportDate(value)
+		if err != nil {
+			return errors.New("must be a date (YYYY-MM-DD)")
+		}
+		field.Set(reflect.ValueOf(&t))
+	case *uuid.UUID:
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return errors.New("must be a UUID")
+		}
+		field.Set(reflect.ValueOf(&id))
+	default:
+		return fmt.Errorf("column %q cannot be imported", name)
+	}
+	return nil
+}
+
+func parseImportDate(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", time.RFC3339, "02/01/2006"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("unrecognised date")
+}
+
+// normalizeColumn turns a header such as "Serial Number" into the JSON field name "serial_number"
+func normalizeColumn(header string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(header)), " ", "_")
+}
+
+// readImportRows reads every row of an uploaded CSV or XLSX file, header row included
+func readImportRows(fileHeader *multipart.FileHeader) ([][]string, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(fileHeader.Filename)) {
+	case ".csv":
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		return reader.ReadAll()
+
+	case ".xlsx":
+		workbook, err := excelize.OpenReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer workbook.Close()
+
+		sheets := workbook.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.New("workbook has no sheets")
+		}
+		return workbook.GetRows(sheets[0])
+
+	default:
+		return nil, errors.New("file must be .csv or .xlsx")
+	}
+}
+
+// lookupIDsByName returns a lower-cased name to ID map for categories or departments
+func lookupIDsByName(db *gorm.DB, model interface{}) (map[string]uuid.UUID, error) {
+	var rows []struct {
+		ID   uuid.UUID
+		Name string
+	}
+	if err := db.Model(model).Select("id, name").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]uuid.UUID, len(rows))
+	for _, row := range rows {
+		ids[strings.ToLower(row.Name)] = row.ID
+	}
+	return ids, nil
+}
+
+// ImportAssets godoc
+// @Summary Bulk import assets
+// @Description Import assets from a CSV or XLSX file. The first row holds column names matching
+// @Description asset fields; "category" and "department" columns take names. With dry_run the
+// @Description file is only validated. Otherwise all rows are created in one transaction, or none.
+// @Tags assets
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param file formData file true "CSV or XLSX file"
+// @Param dry_run query bool false "Validate without creating assets"
+// @Success 200 {object} fiber.Map
+// @Failure 422 {object} fiber.Map
+// @Router /assets/import [post]
+func ImportAssets(c *fiber.Ctx) error {
+	db := database.GetDB()
+	dryRun := c.QueryBool("dry_run") || c.FormValue("dry_run") == "true"
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "A CSV or XLSX file is required",
+		})
+	}
+
+	rows, err := readImportRows(fileHeader)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to read import file: " + err.Error(),
+		})
+	}
+
+	if len(rows) < 2 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Import file must contain a header row and at least one data row",
+		})
+	}
+	if len(rows)-1 > maxImportRows {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": fmt.Sprintf("Import file cannot contain more than %d rows", maxImportRows),
+		})
+	}
+
+	// Validate the header before looking at any data
+	columns := make([]string, len(rows[0]))
+	var unknown []string
+	for i, header := range rows[0] {
+		columns[i] = normalizeColumn(header)
+		switch columns[i] {
+		case "", "category", "department":
+			continue
+		}
+		if _, ok := assetFieldIndex[columns[i]]; !ok || nonImportableAssetFields[columns[i]] {
+			unknown = append(unknown, header)
+		}
+	}
+	if len(unknown) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Unknown columns: " + strings.Join(unknown, ", "),
+		})
+	}
+
+	categoryIDs, err := lookupIDsByName(db, &models.Category{})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to fetch categories",
+		})
+	}
+	departmentIDs, err := lookupIDsByName(db, &models.Department{})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to fetch departments",
+		})
+	}
+
+	var assets []models.Asset
+	rowErrors := []importRowError{}
+	serialRows := make(map[string]int)
+	tagRows := make(map[string]int)
+	totalRows := 0
+
+	for i, row := range rows[1:] {
+		// Row numbers match the spreadsheet, where the header is row 1
+		rowNumber := i + 2
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+		totalRows++
+
+		var asset models.Asset
+		rowValid := true
+		for j, value := range row {
+			value = strings.TrimSpace(value)
+			if j >= len(columns) || columns[j] == "" || value == "" {
+				continue
+			}
+
+			switch columns[j] {
+			case "category":
+				if id, ok := categoryIDs[strings.ToLower(value)]; ok {
+					asset.CategoryID = &id
+				} else {
+					rowErrors = append(rowErrors, importRowError{Row: rowNumber, Column: "category", Message: "Unknown category " + value})
+					rowValid = false
+				}
+			case "department":
+				if id, ok := departmentIDs[strings.ToLower(value)]; ok {
+					asset.DepartmentID = &id
+				} else {
+					rowErrors = append(rowErrors, importRowError{Row: rowNumber, Column: "department", Message: "Unknown department " + value})
+					rowValid = false
+				}
+			default:
+				if err := setAssetField(&asset, columns[j], value); err != nil {
+					rowErrors = append(rowErrors, importRowError{Row: rowNumber, Column: columns[j], Message: err.Error()})
+					rowValid = false
+				}
+			}
+		}
+		if !rowValid {
+			continue
+		}
+
+		if previous, ok := serialRows[asset.SerialNumber]; ok && asset.SerialNumber != "" {
+			rowErrors = append(rowErrors, importRowError{Row: rowNumber, Column: "serial_number", Message: fmt.Sprintf("Serial number duplicates row %d", previous)})
+			continue
+		}
+		serialRows[asset.SerialNumber] = rowNumber
+		if previous, ok := tagRows[asset.AssetTag]; ok && asset.AssetTag != "" {
+			rowErrors = append(rowErrors, importRowError{Row: rowNumber, Column: "asset_tag", Message: fmt.Sprintf("Tag number duplicates row %d", previous)})
+			continue
+		}
+		tagRows[asset.AssetTag] = rowNumber
+
+		if err := prepareNewAsset(db, &asset); err != nil {
+			rowErrors = append(rowErrors, importRowError{Row: rowNumber, Message: err.Message})
+			continue
+		}
+		assets = append(assets, asset)
+	}
+
+	report := fiber.Map{
+		"dry_run":      dryRun,
+		"total_rows":   totalRows,
+		"valid_rows":   len(assets),
+		"invalid_rows": totalRows - len(assets),
+		"errors":       rowErrors,
+		"created":      0,
+	}
+
+	if dryRun {
+		return c.JSON(fiber.Map{
+			"error":   false,
+			"data":    report,
+			"message": "Import validated",
+		})
+	}
+
+	if len(rowErrors) > 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":   true,
+			"data":    report,
+			"message": "Import rejected, no assets were created",
+		})
+	}
+
+	var importedBy *uuid.UUID
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		importedBy = &userID
+	}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(&assets, importBatchSize).Error; err != nil {
+			return err
+		}
+		events := make([]models.AssetStatusEvent, len(assets))
+		for i := range assets {
+			events[i] = lifecycle.InitialEvent(&assets[i], "Asset imported", importedBy)
+		}
+		if err := tx.CreateInBatches(&events, importBatchSize).Error; err != nil {
+			return err
+		}
+		for i := range assets {
+			if assets[i].HomeGeofenceID == nil {
+				continue
+			}
+			if err := checkGeofence(c, tx, &assets[i], models.LocationSourceImport); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"data":    report,
+			"message": "Failed to import assets, no assets were created",
+		})
+	}
+
+	report["created"] = len(assets)
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"error":   false,
+		"data":    report,
+		"message": "Assets imported successfully",
+	})
+}
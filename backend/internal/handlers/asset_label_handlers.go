@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/labels"
+	"sams-backend/internal/models"
+)
+
+// assetLink returns the deep link encoded in an asset's QR code. QR_LINK_TEMPLATE is a URL
+// in which {id} is replaced with the asset ID.
+func assetLink(id uuid.UUID) string {
+	return labels.DeepLink(os.Getenv("QR_LINK_TEMPLATE"), id)
+}
+
+// assetCodeValue returns the value a barcode encodes for an asset: its serial number or, for
+// the tag key, its tag number
+func assetCodeValue(asset *models.Asset, key string) (string, error) {
+	if key == "tag" {
+		if asset.AssetTag == "" {
+			return "", fmt.Errorf("asset %s has no tag number", asset.ID)
+		}
+		return asset.AssetTag, nil
+	}
+	return asset.SerialNumber, nil
+}
+
+// GenerateAssetBarcode godoc
+// @Summary Generate an asset barcode
+// @Description Generate a Code 128, Code 39 or DataMatrix barcode of the asset's serial or tag
+// @Description number, with the number printed below it
+// @Tags assets
+// @Produce  png
+// @Param id path string true "Asset ID"
+// @Param type query string false "code128 (default), code39 or datamatrix"
+// @Param key query string false "serial (default) or tag"
+// @Param format query string false "png (default) or svg"
+// @Param scale query int false "Pixels per module, 1 to 20 (default 2, or 6 for datamatrix)"
+// @Param height query int false "Bar height in pixels for linear barcodes, 10 to 1000 (default 80)"
+// @Param text query bool false "Print the human-readable text (default true)"
+// @Success 200 {file} file
+// @Failure 400 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /assets/{id}/barcode [get]
+func GenerateAssetBarcode(c *fiber.Ctx) error {
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid asset ID",
+		})
+	}
+
+	symbology := c.Query("type", labels.SymbologyCode128)
+	defaultScale := 2
+	if symbology == labels.SymbologyDataMatrix {
+		defaultScale = 6
+	}
+	opts := labels.ImageOptions{
+		Scale:     c.QueryInt("scale", defaultScale),
+		BarHeight: c.QueryInt("height", 80),
+		Text:      c.QueryBool("text", true),
+	}
+	if opts.Scale < 1 || opts.Scale > 20 || opts.BarHeight < 10 || opts.BarHeight > 1000 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "scale must be between 1 and 20 and height between 10 and 1000",
+		})
+	}
+	format := c.Query("format", "png")
+	if format != "png" && format != "svg" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "format must be png or svg",
+		})
+	}
+	key := c.Query("key", "serial")
+	if key != "serial" && key != "tag" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "key must be serial or tag",
+		})
+	}
+
+	var asset models.Asset
+	if err := database.GetDB().First(&asset, "id = ?", assetID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   true,
+				"message": "Asset not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to fetch asset",
+		})
+	}
+
+	value, err := assetCodeValue(&asset, key)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	code, err := labels.Encode(symbology, value)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	var image []byte
+	if format == "svg" {
+		image = labels.SVG(code, opts)
+		c.Set("Content-Type", "image/svg+xml")
+	} else {
+		image, err = labels.PNG(code, opts)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   true,
+				"message": "Failed to generate barcode",
+			})
+		}
+		c.Set("Content-Type", "image/png")
+	}
+
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=asset_%s_%s.%s", asset.SerialNumber, code.Symbology, format))
+	return c.Send(image)
+}
+
+// GetLabelLayouts godoc
+// @Summary List label layouts
+// @Description Get the label stocks that asset labels can be printed on, with dimensions in millimetres
+// @Tags assets
+// @Produce  json
+// @Success 200 {object} fiber.Map
+// @Router /assets/labels/layouts [get]
+func GetLabelLayouts(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    labels.Layouts,
+		"message": "Label layouts retrieved successfully",
+	})
+}
+
+// PrintAssetLabels godoc
+// @Summary Print asset labels
+// @Description Render a PDF sheet of labels, one per asset in the order given. QR and DataMatrix
+// @Description labels show the code beside the asset's name, serial number and tag number; Code 128
+// @Description and Code 39 labels show the name above the barcode and the encoded number below.
+// @Tags assets
+// @Accept  json
+// @Produce  application/pdf
+// @Param request body models.AssetLabelRequest true "Assets and label layout"
+// @Success 200 {file} file
+// @Failure 400 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /assets/labels [post]
+func PrintAssetLabels(c *fiber.Ctx) error {
+	var req models.AssetLabelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+	if err := validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	if req.Layout == "" {
+		req.Layout = labels.DefaultLayout
+	}
+	layout, ok := labels.LookupLayout(req.Layout)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Unknown label layout " + req.Layout,
+		})
+	}
+	if req.Type == "" {
+		req.Type = labels.SymbologyQR
+	}
+	level, err := labels.ParseLevel(req.Level)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	var assets []models.Asset
+	if err := database.GetDB().Where("id IN ?", req.AssetIDs).Find(&assets).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to fetch assets",
+		})
+	}
+	byID := make(map[uuid.UUID]models.Asset, len(assets))
+	for _, asset := range assets {
+		byID[asset.ID] = asset
+	}
+
+	items := make([]labels.Label, 0, len(req.AssetIDs))
+	for _, id := range req.AssetIDs {
+		asset, ok := byID[id]
+		if !ok {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   true,
+				"message": "Asset " + id.String() + " not found",
+			})
+		}
+		item := labels.Label{Title: asset.Name, Lines: []string{"S/N " + asset.SerialNumber}}
+		if asset.AssetTag != "" {
+			item.Lines = append(item.Lines, "Tag "+asset.AssetTag)
+		}
+
+		if req.Type == labels.SymbologyQR {
+			item.Code, err = labels.QR(assetLink(asset.ID), level)
+		} else {
+			var value string
+			if value, err = assetCodeValue(&asset, req.Key); err == nil {
+				item.Code, err = labels.Encode(req.Type, value)
+			}
+		}
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": err.Error(),
+			})
+		}
+		items = append(items, item)
+	}
+
+	pdf := labels.Sheet(layout, items, req.Skip)
+
+	c.Set("Content-Type", "application/pdf")
+	c.Set("Content-Disposition", "attachment; filename=asset_labels.pdf")
+	return c.Send(pdf)
+}
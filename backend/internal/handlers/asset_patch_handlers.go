@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/lifecycle"
+	"sams-backend/internal/models"
+)
+
+// errAssetVersionConflict is returned when an asset was written by another request after it was read
+var errAssetVersionConflict = fiber.NewError(fiber.StatusPreconditionFailed, "Asset was modified by another request")
+
+// readOnlyAssetFields are asset fields that cannot be changed through a merge patch
+var readOnlyAssetFields = map[string]bool{
+	"id":                  true,
+	"category":            true,
+	"department":          true,
+	"home_geofence":       true,
+	"manufacturer_vendor": true,
+	"supplier":            true,
+	"last_seen_at":        true,
+	"last_seen_by_id":     true,
+	"last_seen_by":        true,
+	"created_at":          true,
+	"updated_at":          true,
+	"deleted_at":          true,
+	"version":             true,
+}
+
+// assetETag returns the entity tag of the current version of an asset
+func assetETag(asset *models.Asset) string {
+	return `"` + strconv.Itoa(asset.Version) + `"`
+}
+
+// checkIfMatch compares the If-Match header against the asset's ETag. A missing header passes
+// unless required is set, in which case 428 Precondition Required is returned.
+func checkIfMatch(c *fiber.Ctx, asset *models.Asset, required bool) *fiber.Error {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" {
+		if required {
+			return fiber.NewError(fiber.StatusPreconditionRequired, "If-Match header with the asset's ETag is required")
+		}
+		return nil
+	}
+	if header == "*" {
+		return nil
+	}
+
+	etag := assetETag(asset)
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimSpace(candidate) == etag {
+			return nil
+		}
+	}
+	return fiber.NewError(fiber.StatusPreconditionFailed, "Asset has changed since it was read; fetch it again and retry")
+}
+
+// saveAssetVersion writes every column of the asset and increments its version, but only if the
+// stored version still matches the one that was read. Otherwise errAssetVersionConflict is returned.
+func saveAssetVersion(tx *gorm.DB, asset *models.Asset) error {
+	expected := asset.Version
+	asset.Version++
+
+	result := tx.Model(asset).Where("version = ?", expected).Select("*").Omit("id", "created_at", clause.Associations).Updates(asset)
+	if result.Error != nil {
+		asset.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		asset.Version = expected
+		return errAssetVersionConflict
+	}
+	return nil
+}
+
+// mergePatch applies an RFC 7396 JSON Merge Patch to target: null removes a member, objects are
+// merged recursively and any other value replaces the member
+func mergePatch(target, patch map[string]interface{}) {
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		if patchObject, ok := value.(map[string]interface{}); ok {
+			targetObject, ok := target[key].(map[string]interface{})
+			if !ok {
+				targetObject = map[string]interface{}{}
+			}
+			mergePatch(targetObject, patchObject)
+			target[key] = targetObject
+			continue
+		}
+		target[key] = value
+	}
+}
+
+// PatchAsset godoc
+// @Summary Partially update an asset
+// @Description Apply a JSON Merge Patch (RFC 7396) to an asset. Members set to null are cleared
+// @Description and zero values are stored as given. The If-Match header must carry the ETag from
+// @Description the last read; a stale ETag is rejected with 412 Precondition Failed.
+// @Tags assets
+// @Accept  application/merge-patch+json
+// @Produce  json
+// @Param id path string true "Asset ID"
+// @Param If-Match header string true "ETag of the asset being patched"
+// @Success 200 {object} fiber.Map
+// @Failure 412 {object} fiber.Map
+// @Failure 428 {object} fiber.Map
+// @Router /assets/{id} [patch]
+func PatchAsset(c *fiber.Ctx) error {
+	db := database.GetDB()
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid asset ID",
+		})
+	}
+
+	contentType := strings.ToLower(c.Get(fiber.HeaderContentType))
+	if !strings.HasPrefix(contentType, "application/merge-patch+json") && !strings.HasPrefix(contentType, fiber.MIMEApplicationJSON) {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{
+			"error":   true,
+			"message": "Content-Type must be application/merge-patch+json",
+		})
+	}
+
+	var asset models.Asset
+	if err := db.First(&asset, "id = ?", assetID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   true,
+				"message": "Asset not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to fetch asset",
+		})
+	}
+
+	if err := checkIfMatch(c, &asset, true); err != nil {
+		c.Set(fiber.HeaderETag, assetETag(&asset))
+		return c.Status(err.Code).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Message,
+		})
+	}
+
+	if asset.Status == lifecycle.StatusDisposed {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   true,
+			"message": "Disposed assets cannot be edited",
+		})
+	}
+
+	var patch map[string]interface{}
+	if err := json.Unmarshal(c.Body(), &patch); err != nil || patch == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Request body must be a JSON object",
+		})
+	}
+	for field := range patch {
+		if _, ok := assetFieldIndex[field]; !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Unknown field " + field,
+			})
+		}
+		if readOnlyAssetFields[field] {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Field " + field + " cannot be patched",
+			})
+		}
+	}
+
+	// Apply the patch to the asset's JSON document, then decode into a fresh asset so removed
+	// members fall back to their zero value
+	document := map[string]interface{}{}
+	encoded, _ := json.Marshal(asset)
+	if err := json.Unmarshal(encoded, &document); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to patch asset",
+		})
+	}
+	mergePatch(document, patch)
+	encoded, _ = json.Marshal(document)
+
+	var patched models.Asset
+	if err := json.Unmarshal(encoded, &patched); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid field value: " + err.Error(),
+		})
+	}
+	patched.ID = asset.ID
+	patched.CreatedAt = asset.CreatedAt
+	patched.UpdatedAt = asset.UpdatedAt
+	patched.DeletedAt = asset.DeletedAt
+	patched.Version = asset.Version
+
+	if patched.Status != asset.Status {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   true,
+			"message": "Asset status can only be changed through POST /api/v1/assets/:id/transitions",
+		})
+	}
+	if asset.DepartmentID != nil && !sameDepartment(asset.DepartmentID, patched.DepartmentID) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   true,
+			"message": "Asset department can only be changed through POST /api/v1/assets/:id/transfers",
+		})
+	}
+	if err := validateAsset(db, &patched); err != nil {
+		return c.Status(err.Code).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Message,
+		})
+	}
+
+	applyDepreciation(db, &patched)
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := saveAssetVersion(tx, &patched); err != nil {
+			return err
+		}
+		if locationChanged(&asset, &patched) {
+			if err := checkGeofence(c, tx, &patched, models.LocationSourceAssetUpdate); err != nil {
+				return err
+			}
+		}
+		return recordAssetHistory(c, tx, asset, patched)
+	})
+	if err != nil {
+		if errors.Is(err, errAssetVersionConflict) {
+			return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+				"error":   true,
+				"message": errAssetVersionConflict.Message,
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to update asset",
+		})
+	}
+
+	// Reload with category and department information
+	db.Preload("Category").Preload("Department").First(&patched, "id = ?", assetID)
+
+	c.Set(fiber.HeaderETag, assetETag(&patched))
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"error":   false,
+		"data":    patched,
+		"message": "Asset updated successfully",
+	})
+}
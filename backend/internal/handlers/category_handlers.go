@@ -5,16 +5,27 @@ import (
 	"gorm.io/gorm"
 
 	"sams-backend/internal/database"
+	"sams-backend/internal/depreciation"
+	"sams-backend/internal/filter"
 	"sams-backend/internal/models"
 )
 
 func GetCategories(c *fiber.Ctx) error {
 	db := database.GetDB()
 	var categories []models.Category
-	if err := db.Find(&categories).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch categories"})
+	if !pageRequested(c) {
+		if err := db.Order("name").Find(&categories).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch categories"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": categories})
+	}
+
+	order := []filter.SortKey{{Column: "name"}}
+	pagination, err := listPage(c, db.Model(&models.Category{}), order, maxPageLimit, &categories)
+	if err != nil {
+		return pageError(c, err, "Failed to fetch categories")
 	}
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": categories})
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": categories, "pagination": pagination})
 }
 
 func GetCategory(c *fiber.Ctx) error {
@@ -38,6 +49,10 @@ func CreateCategory(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
 	}
 
+	if category.DepreciationMethod != "" && !depreciation.IsValidMethod(category.DepreciationMethod) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid depreciation method"})
+	}
+
 	if err := db.Create(&category).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to create category"})
 	}
@@ -60,6 +75,10 @@ func UpdateCategory(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
 	}
 
+	if category.DepreciationMethod != "" && !depreciation.IsValidMethod(category.DepreciationMethod) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid depreciation method"})
+	}
+
 	if err := db.Save(&category).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to update category"})
 	}
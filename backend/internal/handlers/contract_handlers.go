@@ -0,0 +1,464 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/filter"
+	"sams-backend/internal/middleware"
+	"sams-backend/internal/models"
+)
+
+// contractRequest parses and validates the request body. When it is invalid the error response
+// is written and the returned request is nil.
+func contractRequest(c *fiber.Ctx) (*models.ContractRequest, error) {
+	var req models.ContractRequest
+	if err := c.BodyParser(&req); err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+	req.Provider = strings.TrimSpace(req.Provider)
+	if err := validate.Struct(req); err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	if req.EndDate.Before(req.StartDate) {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "end_date cannot be before start_date",
+		})
+	}
+	return &req, nil
+}
+
+// applyContractRequest copies the request onto a contract
+func applyContractRequest(contract *models.Contract, req *models.ContractRequest) {
+	contract.Kind = req.Kind
+	if contract.Kind == "" {
+		contract.Kind = models.ContractWarranty
+	}
+	contract.Reference = req.Reference
+	contract.Provider = req.Provider
+	contract.VendorID = req.VendorID
+	contract.StartDate = req.StartDate
+	contract.EndDate = req.EndDate
+	contract.CoverageTerms = req.CoverageTerms
+	contract.Cost = req.Cost
+	contract.DocumentReference = req.DocumentReference
+	contract.Notes = req.Notes
+	contract.AlertDays = models.DefaultContractAlertDays
+	if req.AlertDays != nil {
+		contract.AlertDays = *req.AlertDays
+	}
+}
+
+// contractAssets loads the assets a contract covers, failing when any of them does not exist
+func contractAssets(db *gorm.DB, ids []uuid.UUID) ([]models.Asset, error) {
+	var assets []models.Asset
+	if err := db.Select("id").Where("id IN ?", ids).Find(&assets).Error; err != nil {
+		return nil, err
+	}
+
+	found := make(map[uuid.UUID]bool, len(assets))
+	for _, asset := range assets {
+		found[asset.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Asset "+id.String()+" not found")
+		}
+	}
+	return assets, nil
+}
+
+// contractVendor checks that the vendor a contract is linked to is a service provider. A
+// contract without a provider name takes the vendor's name.
+func contractVendor(db *gorm.DB, contract *models.Contract) error {
+	if contract.VendorID == nil {
+		return nil
+	}
+	var vendor models.Vendor
+	if err := db.First(&vendor, "id = ?", *contract.VendorID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusBadRequest, "Vendor not found")
+		}
+		return err
+	}
+	if !vendor.IsServiceProvider {
+		return fiber.NewError(fiber.StatusBadRequest, "Vendor "+vendor.Name+" is not a service provider")
+	}
+	if contract.Provider == "" {
+		contract.Provider = vendor.Name
+	}
+	return nil
+}
+
+// contractSaveError writes the response for a failed contract insert or update
+func contractSaveError(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Contract not found"})
+	case errors.As(err, &fiberErr):
+		return c.Status(fiberErr.Code).JSON(fiber.Map{"error": true, "message": fiberErr.Message})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to save contract"})
+}
+
+// filterContracts applies the list filters shared by contract listings: kind, vendor, provider
+// (a case-insensitive substring) and status, which is active, upcoming or expired as of today.
+// A contract is active through the whole of its end date.
+func filterContracts(c *fiber.Ctx, query *gorm.DB) (*gorm.DB, error) {
+	if kind := c.Query("kind"); kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	if vendorID := c.Query("vendor_id"); vendorID != "" {
+		id, err := uuid.Parse(vendorID)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid vendor ID")
+		}
+		query = query.Where("vendor_id = ?", id)
+	}
+	if provider := strings.TrimSpace(c.Query("provider")); provider != "" {
+		query = query.Where("provider ILIKE ?", "%"+provider+"%")
+	}
+
+	switch c.Query("status") {
+	case "", "all":
+	case "active":
+		query = query.Where("start_date <= CURRENT_DATE AND end_date >= CURRENT_DATE")
+	case "upcoming":
+		query = query.Where("start_date > CURRENT_DATE")
+	case "expired":
+		query = query.Where("end_date < CURRENT_DATE")
+	default:
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Status must be active, upcoming or expired")
+	}
+	return query, nil
+}
+
+// listContracts paginates a contract query, soonest end date first
+func listContracts(c *fiber.Ctx, query *gorm.DB) error {
+	query, err := filterContracts(c, query)
+	if err != nil {
+		return pageError(c, err, "")
+	}
+
+	var contracts []models.Contract
+	order := []filter.SortKey{{Column: "end_date"}}
+	pagination, err := listPage(c, query, order, 20, &contracts, "Assets", "Vendor")
+	if err != nil {
+		return pageError(c, err, "Failed to fetch contracts")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       contracts,
+		"message":    "Contracts retrieved successfully",
+		"pagination": pagination,
+	})
+}
+
+// GetContracts godoc
+// @Summary List contracts
+// @Description Get warranties and service contracts, soonest end date first
+// @Tags contracts
+// @Produce  json
+// @Param kind query string false "warranty, service, support or lease"
+// @Param vendor_id query string false "Filter by service provider vendor"
+// @Param provider query string false "Provider name contains"
+// @Param status query string false "active, upcoming or expired"
+// @Param asset_id query string false "Only contracts covering this asset"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /contracts [get]
+func GetContracts(c *fiber.Ctx) error {
+	db := database.GetDB()
+	query := db.Model(&models.Contract{})
+	if assetID := c.Query("asset_id"); assetID != "" {
+		id, err := uuid.Parse(assetID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid asset ID"})
+		}
+		query = query.Where("id IN (?)", db.Model(&models.ContractAsset{}).Select("contract_id").Where("asset_id = ?", id))
+	}
+	return listContracts(c, query)
+}
+
+// GetExpiringContracts godoc
+// @Summary List expiring contracts
+// @Description Get the contracts that end within the next N days, soonest first
+// @Tags contracts
+// @Produce  json
+// @Param days query int false "Days ahead, 1 to 3650 (default 30)"
+// @Param kind query string false "warranty, service, support or lease"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Router /contracts/expiring [get]
+func GetExpiringContracts(c *fiber.Ctx) error {
+	days := c.QueryInt("days", models.DefaultContractAlertDays)
+	if days < 1 || days > 3650 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "days must be between 1 and 3650"})
+	}
+
+	query := database.GetDB().Model(&models.Contract{}).Where("end_date >= CURRENT_DATE AND end_date <= CURRENT_DATE + ?::integer", days)
+	return listContracts(c, query)
+}
+
+// GetAssetContracts godoc
+// @Summary Get asset contracts
+// @Description Get the warranties and service contracts covering an asset; status=active shows
+// @Description whether it is under warranty now
+// @Tags contracts
+// @Produce  json
+// @Param id path string true "Asset ID"
+// @Param status query string false "active, upcoming or expired"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Router /assets/{id}/contracts [get]
+func GetAssetContracts(c *fiber.Ctx) error {
+	db := database.GetDB()
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid asset ID"})
+	}
+
+	query := db.Model(&models.Contract{}).Where("id IN (?)", db.Model(&models.ContractAsset{}).Select("contract_id").Where("asset_id = ?", assetID))
+	return listContracts(c, query)
+}
+
+// GetContract godoc
+// @Summary Get a contract
+// @Description Get a single contract with the assets it covers
+// @Tags contracts
+// @Produce  json
+// @Param id path string true "Contract ID"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /contracts/{id} [get]
+func GetContract(c *fiber.Ctx) error {
+	var contract models.Contract
+	if err := database.GetDB().Preload("Assets").Preload("Vendor").First(&contract, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Contract not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch contract"})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    contract,
+		"message": "Contract retrieved successfully",
+	})
+}
+
+// CreateContract godoc
+// @Summary Create a contract
+// @Description Record a warranty or service contract covering one or more assets
+// @Tags contracts
+// @Accept  json
+// @Produce  json
+// @Param request body models.ContractRequest true "Contract"
+// @Success 201 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Router /contracts [post]
+func CreateContract(c *fiber.Ctx) error {
+	db := database.GetDB()
+	req, err := contractRequest(c)
+	if req == nil {
+		return err
+	}
+
+	var contract models.Contract
+	applyContractRequest(&contract, req)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		assets, err := contractAssets(tx, req.AssetIDs)
+		if err != nil {
+			return err
+		}
+		if err := contractVendor(tx, &contract); err != nil {
+			return err
+		}
+		contract.Assets = assets
+		return tx.Omit("Assets.*").Create(&contract).Error
+	})
+	if err != nil {
+		return contractSaveError(c, err)
+	}
+
+	db.Preload("Assets").Preload("Vendor").First(&contract, "id = ?", contract.ID)
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"error":   false,
+		"data":    contract,
+		"message": "Contract created successfully",
+	})
+}
+
+// UpdateContract godoc
+// @Summary Replace a contract
+// @Description Replace a contract's terms and the assets it covers. Extending the end date
+// @Description raises a new expiry alert as the new date approaches.
+// @Tags contracts
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Contract ID"
+// @Param request body models.ContractRequest true "Contract"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /contracts/{id} [put]
+func UpdateContract(c *fiber.Ctx) error {
+	db := database.GetDB()
+	req, err := contractRequest(c)
+	if req == nil {
+		return err
+	}
+
+	var contract models.Contract
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&contract, "id = ?", c.Params("id")).Error; err != nil {
+			return err
+		}
+		assets, err := contractAssets(tx, req.AssetIDs)
+		if err != nil {
+			return err
+		}
+
+		applyContractRequest(&contract, req)
+		if err := contractVendor(tx, &contract); err != nil {
+			return err
+		}
+		if err := tx.Omit("Assets").Save(&contract).Error; err != nil {
+			return err
+		}
+		return tx.Model(&contract).Omit("Assets.*").Association("Assets").Replace(assets)
+	})
+	if err != nil {
+		return contractSaveError(c, err)
+	}
+
+	db.Preload("Assets").Preload("Vendor").First(&contract, "id = ?", contract.ID)
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    contract,
+		"message": "Contract updated successfully",
+	})
+}
+
+// DeleteContract godoc
+// @Summary Delete a contract
+// @Description Delete a contract along with its open expiry alerts
+// @Tags contracts
+// @Produce  json
+// @Param id path string true "Contract ID"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /contracts/{id} [delete]
+func DeleteContract(c *fiber.Ctx) error {
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var contract models.Contract
+		if err := tx.First(&contract, "id = ?", c.Params("id")).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("contract_id = ? AND status = ?", contract.ID, models.ContractAlertOpen).Delete(&models.ContractAlert{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&contract).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Contract not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to delete contract"})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"message": "Contract deleted successfully",
+	})
+}
+
+// GetContractAlerts godoc
+// @Summary List contract expiry alerts
+// @Description Get the alerts raised for contracts nearing their end date, soonest end date first
+// @Tags contracts
+// @Produce  json
+// @Param status query string false "open or acknowledged"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /contract-alerts [get]
+func GetContractAlerts(c *fiber.Ctx) error {
+	query := database.GetDB().Model(&models.ContractAlert{})
+	if status := c.Query("status"); status != "" && status != "all" {
+		query = query.Where("status = ?", status)
+	}
+
+	var alerts []models.ContractAlert
+	order := []filter.SortKey{{Column: "end_date"}}
+	pagination, err := listPage(c, query, order, 20, &alerts, "Contract", "Contract.Assets", "AcknowledgedBy")
+	if err != nil {
+		return pageError(c, err, "Failed to fetch contract alerts")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       alerts,
+		"message":    "Contract alerts retrieved successfully",
+		"pagination": pagination,
+	})
+}
+
+// AcknowledgeContractAlert godoc
+// @Summary Acknowledge a contract alert
+// @Description Mark an open expiry alert as seen
+// @Tags contracts
+// @Produce  json
+// @Param id path string true "Alert ID"
+// @Success 200 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /contract-alerts/{id}/acknowledge [post]
+func AcknowledgeContractAlert(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var alert models.ContractAlert
+	if err := db.First(&alert, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Contract alert not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch contract alert"})
+	}
+
+	updates := map[string]interface{}{"status": models.ContractAlertAcknowledged, "acknowledged_at": time.Now()}
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		updates["acknowledged_by_id"] = userID
+	}
+	result := db.Model(&alert).Where("status = ?", models.ContractAlertOpen).Updates(updates)
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to acknowledge contract alert"})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Only open alerts can be acknowledged"})
+	}
+
+	db.Preload("Contract").Preload("AcknowledgedBy").First(&alert, "id = ?", alert.ID)
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    alert,
+		"message": "Contract alert acknowledged",
+	})
+}
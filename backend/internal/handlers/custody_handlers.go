@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/filter"
+	"sams-backend/internal/lifecycle"
+	"sams-backend/internal/middleware"
+	"sams-backend/internal/models"
+)
+
+// CheckoutAsset checks an asset out to a user or a department
+// @Failure 500 {object} fiber.Map
+// @Router /assets/{id}/checkout [post]
+func CheckoutAsset(c *fiber.Ctx) error {
+	db := database.GetDB()
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid asset ID",
+		})
+	}
+
+	var req models.CheckoutRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	if (req.UserID == nil) == (req.DepartmentID == nil) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Exactly one of user_id or department_id is required",
+		})
+	}
+
+	now := time.Now()
+	if req.ExpectedReturnAt != nil && !req.ExpectedReturnAt.After(now) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Expected return date must be in the future",
+		})
+	}
+
+	var asset models.Asset
+	if err := db.First(&asset, "id = ?", assetID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   true,
+				"message": "Asset not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to fetch asset",
+		})
+	}
+
+	if asset.Status == lifecycle.StatusDisposed {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   true,
+			"message": "Disposed assets cannot be checked out",
+		})
+	}
+
+	if req.UserID != nil {
+		var user models.User
+		if err := db.First(&user, "id = ?", *req.UserID).Error; err != nil || !user.IsActive {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "User not found or inactive",
+			})
+		}
+	}
+
+	if req.DepartmentID != nil {
+		var department models.Department
+		if err := db.First(&department, "id = ?", *req.DepartmentID).Error; err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Department not found",
+			})
+		}
+	}
+
+	var openCount int64
+	if err := db.Model(&models.AssetCustody{}).Where("asset_id = ? AND checked_in_at IS NULL", assetID).Count(&openCount).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to check asset custody",
+		})
+	}
+	if openCount > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   true,
+			"message": "Asset is already checked out",
+		})
+	}
+
+	custody := models.AssetCustody{
+		AssetID:          assetID,
+		UserID:           req.UserID,
+		DepartmentID:     req.DepartmentID,
+		CheckedOutAt:     now,
+		ExpectedReturnAt: req.ExpectedReturnAt,
+		CheckoutNotes:    req.Notes,
+	}
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		custody.CheckedOutByID = &userID
+	}
+
+	if err := db.Create(&custody).Error; err != nil {
+		// A concurrent checkout can pass the check above; the open custody index stops it here
+		if errors.Is(database.TranslateError(db, err), gorm.ErrDuplicatedKey) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":   true,
+				"message": "Asset is already checked out",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to check out asset",
+		})
+	}
+
+	db.Preload("User").Preload("Department").First(&custody, "id = ?", custody.ID)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"error":   false,
+		"data":    custody,
+		"message": "Asset checked out successfully",
+	})
+}
+
+// CheckinAsset checks an asset back in, closing its open custody record
+// @Failure 500 {object} fiber.Map
+// @Router /assets/{id}/checkin [post]
+func CheckinAsset(c *fiber.Ctx) error {
+	db := database.GetDB()
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid asset ID",
+		})
+	}
+
+	var req models.CheckinRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	var custody models.AssetCustody
+	if err := db.Where("asset_id = ? AND checked_in_at IS NULL", assetID).First(&custody).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":   true,
+				"message": "Asset is not checked out",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to fetch asset custody",
+		})
+	}
+
+	now := time.Now()
+	custody.CheckedInAt = &now
+	custody.ReturnCondition = req.Condition
+	custody.ReturnNotes = req.Notes
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		custody.CheckedInByID = &userID
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&custody).Error; err != nil {
+			return err
+		}
+
+		// The returned condition becomes the asset's current condition, unless the asset has been
+		// disposed of since it was checked out and can no longer be edited
+		if req.Condition == "" {
+			return nil
+		}
+
+		var asset models.Asset
+		if err := tx.First(&asset, "id = ?", assetID).Error; err != nil {
+			return err
+		}
+		if asset.Status == lifecycle.StatusDisposed {
+			return nil
+		}
+		before := asset
+		asset.Condition = req.Condition
+		if err := saveAssetVersion(tx, &asset); err != nil {
+			return err
+		}
+		return recordAssetHistory(c, tx, before, asset)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to check in asset",
+		})
+	}
+
+	db.Preload("User").Preload("Department").First(&custody, "id = ?", custody.ID)
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    custody,
+		"message": "Asset checked in successfully",
+	})
+}
+
+// GetAssetCustody returns the check-out / check-in history of an asset
+// @Failure 500 {object} fiber.Map
+// @Router /assets/{id}/custody [get]
+func GetAssetCustody(c *fiber.Ctx) error {
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid asset ID",
+		})
+	}
+
+	query := database.GetDB().Model(&models.AssetCustody{}).Where("asset_id = ?", assetID)
+	return listCustody(c, query.Preload("User").Preload("Department"))
+}
+
+// GetUserCustody returns the check-out / check-in history of a user
+// @Failure 500 {object} fiber.Map
+// @Router /users/{id}/custody [get]
+func GetUserCustody(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid user ID",
+		})
+	}
+
+	query := database.GetDB().Model(&models.AssetCustody{}).Where("user_id = ?", userID)
+	return listCustody(c, query.Preload("Asset"))
+}
+
+// GetDepartmentCustody returns the check-out / check-in history of a department
+// @Failure 500 {object} fiber.Map
+// @Router /departments/{id}/custody [get]
+func GetDepartmentCustody(c *fiber.Ctx) error {
+	departmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid department ID",
+		})
+	}
+
+	query := database.GetDB().Model(&models.AssetCustody{}).Where("department_id = ?", departmentID)
+	return listCustody(c, query.Preload("Asset"))
+}
+
+// GetOverdueCustody returns all assets that are still checked out past their expected return date
+// @Failure 500 {object} fiber.Map
+// @Router /custody/overdue [get]
+func GetOverdueCustody(c *fiber.Ctx) error {
+	query := database.GetDB().Model(&models.AssetCustody{}).
+		Where("checked_in_at IS NULL AND expected_return_at IS NOT NULL AND expected_return_at < ?", time.Now())
+	return listCustody(c, query.Preload("Asset").Preload("User").Preload("Department"))
+}
+
+// listCustody paginates a custody query; ?open=true limits it to assets still checked out
+func listCustody(c *fiber.Ctx, query *gorm.DB) error {
+	if c.QueryBool("open") {
+		query = query.Where("checked_in_at IS NULL")
+	}
+
+	var records []models.AssetCustody
+	order := []filter.SortKey{{Column: "checked_out_at", Desc: true}}
+	pagination, err := listPage(c, query, order, 20, &records)
+	if err != nil {
+		return pageError(c, err, "Failed to fetch custody records")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       records,
+		"message":    "Custody records retrieved successfully",
+		"pagination": pagination,
+	})
+}
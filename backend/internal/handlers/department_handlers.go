@@ -5,16 +5,26 @@ import (
 	"gorm.io/gorm"
 
 	"sams-backend/internal/database"
+	"sams-backend/internal/filter"
 	"sams-backend/internal/models"
 )
 
 func GetDepartments(c *fiber.Ctx) error {
 	db := database.GetDB()
 	var departments []models.Department
-	if err := db.Find(&departments).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch departments"})
+	if !pageRequested(c) {
+		if err := db.Order("name").Find(&departments).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch departments"})
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": departments})
+	}
+
+	order := []filter.SortKey{{Column: "name"}}
+	pagination, err := listPage(c, db.Model(&models.Department{}), order, maxPageLimit, &departments)
+	if err != nil {
+		return pageError(c, err, "Failed to fetch departments")
 	}
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": departments})
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": departments, "pagination": pagination})
 }
 
 func GetDepartment(c *fiber.Ctx) error {
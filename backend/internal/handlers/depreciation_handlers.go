@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/depreciation"
+	"sams-backend/internal/models"
+)
+
+// bookValueAt returns the computed book value of the asset at the given time; the second
+// return value is false when the asset lacks the data to be depreciated
+func bookValueAt(db *gorm.DB, asset *models.Asset, at time.Time) (float64, bool) {
+	var category *models.Category
+	if asset.CategoryID != nil {
+		var loaded models.Category
+		if err := db.First(&loaded, "id = ?", *asset.CategoryID).Error; err == nil {
+			category = &loaded
+		}
+	}
+
+	input, err := depreciation.FromAsset(asset, category)
+	if err != nil {
+		return 0, false
+	}
+	return input.BookValueAt(at), true
+}
+
+// applyDepreciation sets the asset's current value to its computed book value when the asset
+// has enough data to be depreciated; otherwise the value is left as entered
+func applyDepreciation(db *gorm.DB, asset *models.Asset) {
+	if value, ok := bookValueAt(db, asset, time.Now()); ok {
+		asset.CurrentValue = value
+	}
+}
+
+// GetAssetDepreciation godoc
+// @Summary Get asset depreciation schedule
+// @Description Get the projected book value of an asset per period over its expected life
+// @Tags assets
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Asset ID"
+// @Param period query string false "Schedule period: year (default) or month"
+// @Param method query string false "Override the depreciation method for a what-if projection"
+// @Success 200 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /assets/{id}/depreciation [get]
+func GetAssetDepreciation(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var asset models.Asset
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid asset ID",
+		})
+	}
+
+	if err := db.Preload("Category").First(&asset, "id = ?", assetID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   true,
+				"message": "Asset not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to fetch asset",
+		})
+	}
+
+	period := c.Query("period", depreciation.PeriodYear)
+	if period != depreciation.PeriodYear && period != depreciation.PeriodMonth {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Period must be year or month",
+		})
+	}
+
+	input, err := depreciation.FromAsset(&asset, asset.Category)
+	if err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	if method := c.Query("method"); method != "" {
+		if !depreciation.IsValidMethod(method) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Invalid depreciation method",
+			})
+		}
+		input.Method = method
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data": fiber.Map{
+			"asset_id":           asset.ID,
+			"method":             input.Method,
+			"acquisition_cost":   input.Cost,
+			"salvage_value":      input.Salvage,
+			"life_years":         input.LifeYears,
+			"acquisition_date":   input.StartDate,
+			"current_book_value": input.BookValueAt(time.Now()),
+			"period":             period,
+			"schedule":           input.Schedule(period),
+		},
+		"message": "Depreciation schedule retrieved successfully",
+	})
+}
+
+// RecalculateDepreciation recomputes the current value of every depreciable asset immediately
+// @Failure 500 {object} fiber.Map
+// @Router /assets/depreciation/recalculate [post]
+func RecalculateDepreciation(c *fiber.Ctx) error {
+	updated, err := depreciation.Recalculate(database.GetDB(), time.Now())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to recalculate depreciation"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": fiber.Map{"updated": updated}})
+}
@@ -0,0 +1,427 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/filter"
+	"sams-backend/internal/lifecycle"
+	"sams-backend/internal/middleware"
+	"sams-backend/internal/models"
+)
+
+// CreateDisposalRequest godoc
+// @Summary Request asset disposal
+// @Description Request the disposal of an asset by sale, scrap, donation or transfer. The asset is
+// @Description only disposed once an admin approves the request.
+// @Tags disposals
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Asset ID"
+// @Param request body models.DisposalCreateRequest true "Disposal request"
+// @Success 201 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /assets/{id}/disposals [post]
+func CreateDisposalRequest(c *fiber.Ctx) error {
+	db := database.GetDB()
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid asset ID",
+		})
+	}
+
+	var req models.DisposalCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	var asset models.Asset
+	if err := db.First(&asset, "id = ?", assetID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   true,
+				"message": "Asset not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to fetch asset",
+		})
+	}
+
+	if asset.Status == lifecycle.StatusDisposed {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   true,
+			"message": "Asset is already disposed",
+		})
+	}
+
+	var pending int64
+	if err := db.Model(&models.DisposalRequest{}).Where("asset_id = ? AND status = ?", assetID, models.DisposalPending).Count(&pending).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to check disposal requests",
+		})
+	}
+	if pending > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   true,
+			"message": "Asset already has a pending disposal request",
+		})
+	}
+
+	disposal := models.DisposalRequest{
+		AssetID:   assetID,
+		Method:    req.Method,
+		Proceeds:  req.Proceeds,
+		Recipient: req.Recipient,
+		Reason:    req.Reason,
+		Status:    models.DisposalPending,
+	}
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		disposal.RequestedByID = &userID
+	}
+
+	if err := db.Create(&disposal).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to create disposal request",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"error":   false,
+		"data":    disposal,
+		"message": "Disposal request created successfully",
+	})
+}
+
+// GetDisposalRequests godoc
+// @Summary Get disposal requests
+// @Description Get a paginated list of disposal requests
+// @Tags disposals
+// @Produce  json
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Param status query string false "pending, approved, rejected or cancelled"
+// @Param asset_id query string false "Asset ID"
+// @Success 200 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /disposals [get]
+func GetDisposalRequests(c *fiber.Ctx) error {
+	db := database.GetDB()
+	query := db.Model(&models.DisposalRequest{})
+	if status := c.Query("status"); status != "" && status != "all" {
+		query = query.Where("status = ?", status)
+	}
+	if assetID := c.Query("asset_id"); assetID != "" {
+		query = query.Where("asset_id = ?", assetID)
+	}
+
+	var disposals []models.DisposalRequest
+	order := []filter.SortKey{{Column: "created_at", Desc: true}}
+	pagination, err := listPage(c, query, order, 20, &disposals, "Asset", "RequestedBy", "ReviewedBy")
+	if err != nil {
+		return pageError(c, err, "Failed to fetch disposal requests")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       disposals,
+		"message":    "Disposal requests retrieved successfully",
+		"pagination": pagination,
+	})
+}
+
+// GetDisposalRequest godoc
+// @Summary Get a disposal request
+// @Description Get a single disposal request by ID
+// @Tags disposals
+// @Produce  json
+// @Param id path string true "Disposal request ID"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /disposals/{id} [get]
+func GetDisposalRequest(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var disposal models.DisposalRequest
+
+	if err := db.Preload("Asset").Preload("RequestedBy").Preload("ReviewedBy").First(&disposal, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Disposal request not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch disposal request"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": disposal})
+}
+
+// errDisposalNotPending is returned when reviewing a disposal request that was already decided
+var errDisposalNotPending = errors.New("disposal request is no longer pending")
+
+// ApproveDisposalRequest godoc
+// @Summary Approve a disposal request
+// @Description Approve a pending disposal request. The gain or loss is computed against the
+// @Description depreciated book value at the disposal date, and the asset is moved to disposed,
+// @Description which locks it against further edits. Requesters cannot approve their own request.
+// @Tags disposals
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Disposal request ID"
+// @Param request body models.DisposalReviewRequest false "Review notes and disposal date"
+// @Success 200 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /disposals/{id}/approve [post]
+func ApproveDisposalRequest(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var req models.DisposalReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+
+	reviewerID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": true, "message": "User not authenticated"})
+	}
+
+	now := time.Now()
+	disposalDate := now
+	if req.DisposalDate != nil {
+		if req.DisposalDate.After(now) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Disposal date cannot be in the future"})
+		}
+		disposalDate = *req.DisposalDate
+	}
+
+	var disposal models.DisposalRequest
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&disposal, "id = ?", c.Params("id")).Error; err != nil {
+			return err
+		}
+		if disposal.Status != models.DisposalPending {
+			return errDisposalNotPending
+		}
+		if disposal.RequestedByID != nil && *disposal.RequestedByID == reviewerID {
+			return fiber.NewError(fiber.StatusForbidden, "A disposal request cannot be approved by its requester")
+		}
+
+		var asset models.Asset
+		if err := tx.First(&asset, "id = ?", disposal.AssetID).Error; err != nil {
+			return err
+		}
+
+		bookValue, ok := bookValueAt(tx, &asset, disposalDate)
+		if !ok {
+			bookValue = asset.CurrentValue
+		}
+
+		disposal.Status = models.DisposalApproved
+		disposal.ReviewedByID = &reviewerID
+		disposal.ReviewedAt = &now
+		disposal.ReviewNotes = req.Notes
+		disposal.DisposalDate = &disposalDate
+		disposal.AcquisitionCost = asset.AcquisitionCost
+		disposal.BookValue = bookValue
+		disposal.GainLoss = math.Round((disposal.Proceeds-bookValue)*100) / 100
+		if err := tx.Save(&disposal).Error; err != nil {
+			return err
+		}
+
+		before := asset
+		if _, err := lifecycle.Transition(tx, &asset, lifecycle.StatusDisposed, "Disposal approved ("+disposal.Method+"): "+disposal.Reason, &reviewerID); err != nil {
+			return err
+		}
+		return recordAssetHistory(c, tx, before, asset)
+	})
+	if err != nil {
+		return disposalReviewError(c, err)
+	}
+
+	db.Preload("Asset").First(&disposal, "id = ?", disposal.ID)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": disposal, "message": "Disposal request approved"})
+}
+
+// RejectDisposalRequest godoc
+// @Summary Reject a disposal request
+// @Description Reject a pending disposal request
+// @Tags disposals
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Disposal request ID"
+// @Param request body models.DisposalReviewRequest false "Review notes"
+// @Success 200 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /disposals/{id}/reject [post]
+func RejectDisposalRequest(c *fiber.Ctx) error {
+	return closeDisposalRequest(c, models.DisposalRejected)
+}
+
+// CancelDisposalRequest godoc
+// @Summary Cancel a disposal request
+// @Description Withdraw a pending disposal request
+// @Tags disposals
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Disposal request ID"
+// @Param request body models.DisposalReviewRequest false "Notes"
+// @Success 200 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /disposals/{id}/cancel [post]
+func CancelDisposalRequest(c *fiber.Ctx) error {
+	return closeDisposalRequest(c, models.DisposalCancelled)
+}
+
+// closeDisposalRequest moves a pending disposal request to rejected or cancelled
+func closeDisposalRequest(c *fiber.Ctx, status string) error {
+	db := database.GetDB()
+	var req models.DisposalReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+
+	var reviewerID *uuid.UUID
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		reviewerID = &userID
+	}
+
+	now := time.Now()
+	var disposal models.DisposalRequest
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&disposal, "id = ?", c.Params("id")).Error; err != nil {
+			return err
+		}
+		if disposal.Status != models.DisposalPending {
+			return errDisposalNotPending
+		}
+
+		disposal.Status = status
+		disposal.ReviewedByID = reviewerID
+		disposal.ReviewedAt = &now
+		disposal.ReviewNotes = req.Notes
+		return tx.Save(&disposal).Error
+	})
+	if err != nil {
+		return disposalReviewError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": disposal, "message": "Disposal request " + status})
+}
+
+// disposalReviewError maps an error from reviewing a disposal request to a response
+func disposalReviewError(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Disposal request not found"})
+	case errors.Is(err, errDisposalNotPending):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Disposal request is no longer pending"})
+	case errors.Is(err, lifecycle.ErrConflict):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Asset was modified by another request; try again"})
+	case errors.As(err, &fiberErr):
+		return c.Status(fiberErr.Code).JSON(fiber.Map{"error": true, "message": fiberErr.Message})
+	}
+
+	var transitionErr *lifecycle.TransitionError
+	if errors.As(err, &transitionErr) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": transitionErr.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to review disposal request"})
+}
+
+// DisposalRegisterLine summarises approved disposals for one disposal method
+type DisposalRegisterLine struct {
+	Method          string  `json:"method"`
+	Count           int64   `json:"count"`
+	AcquisitionCost float64 `json:"acquisition_cost"`
+	BookValue       float64 `json:"book_value"`
+	Proceeds        float64 `json:"proceeds"`
+	Gain            float64 `json:"gain"`
+	Loss            float64 `json:"loss"`
+	NetGainLoss     float64 `json:"net_gain_loss"`
+}
+
+// GetDisposalRegister godoc
+// @Summary Get disposal register
+// @Description Get the approved disposals within a period, with totals per disposal method
+// @Tags disposals
+// @Produce  json
+// @Param from query string true "Period start (YYYY-MM-DD)"
+// @Param to query string true "Period end (YYYY-MM-DD), inclusive"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Router /disposals/register [get]
+func GetDisposalRegister(c *fiber.Ctx) error {
+	db := database.GetDB()
+
+	from, errFrom := time.Parse("2006-01-02", c.Query("from"))
+	to, errTo := time.Parse("2006-01-02", c.Query("to"))
+	if errFrom != nil || errTo != nil || to.Before(from) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "from and to must be dates (YYYY-MM-DD) with from before to"})
+	}
+
+	var disposals []models.DisposalRequest
+	if err := db.Preload("Asset").
+		Where("status = ? AND disposal_date >= ? AND disposal_date <= ?", models.DisposalApproved, from, to).
+		Order("disposal_date ASC").
+		Find(&disposals).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch disposal register"})
+	}
+
+	byMethod := map[string]*DisposalRegisterLine{}
+	var methods []string
+	total := DisposalRegisterLine{Method: "total"}
+	for _, disposal := range disposals {
+		line, ok := byMethod[disposal.Method]
+		if !ok {
+			line = &DisposalRegisterLine{Method: disposal.Method}
+			byMethod[disposal.Method] = line
+			methods = append(methods, disposal.Method)
+		}
+		for _, l := range []*DisposalRegisterLine{line, &total} {
+			l.Count++
+			l.AcquisitionCost += disposal.AcquisitionCost
+			l.BookValue += disposal.BookValue
+			l.Proceeds += disposal.Proceeds
+			if disposal.GainLoss >= 0 {
+				l.Gain += disposal.GainLoss
+			} else {
+				l.Loss -= disposal.GainLoss
+			}
+			l.NetGainLoss += disposal.GainLoss
+		}
+	}
+
+	summary := []DisposalRegisterLine{}
+	for _, method := range methods {
+		summary = append(summary, *byMethod[method])
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"error": false,
+		"data": fiber.Map{
+			"from":      from.Format("2006-01-02"),
+			"to":        to.Format("2006-01-02"),
+			"disposals": disposals,
+			"by_method": summary,
+			"totals":    total,
+		},
+	})
+}
@@ -0,0 +1,453 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/filter"
+	"sams-backend/internal/geo"
+	"sams-backend/internal/middleware"
+	"sams-backend/internal/models"
+)
+
+// locationChanged reports whether an update moved an asset or changed its home zone, which is
+// when the geofence check runs
+func locationChanged(before, after *models.Asset) bool {
+	return !sameValue(before.Latitude, after.Latitude) || !sameValue(before.Longitude, after.Longitude) ||
+		!sameValue(before.HomeGeofenceID, after.HomeGeofenceID)
+}
+
+// sameValue reports whether two optional values are both unset or equal
+func sameValue[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// checkGeofence compares an asset's location with its home zone. Outside the zone an open alert
+// is raised, or the unresolved one updated; back inside, or without a zone, the unresolved alert
+// is resolved. Assets without coordinates are left as they are. The asset is locked first, so
+// concurrent location reports see each other's alert rather than raising a second one.
+func checkGeofence(c *fiber.Ctx, tx *gorm.DB, asset *models.Asset, source string) error {
+	now := time.Now()
+
+	if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+		First(&models.Asset{}, "id = ?", asset.ID).Error; err != nil {
+		return err
+	}
+
+	var alert models.GeofenceAlert
+	err := tx.Where("asset_id = ? AND status <> ?", asset.ID, models.GeofenceAlertResolved).First(&alert).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	unresolved := err == nil
+	resolve := func() error {
+		if !unresolved {
+			return nil
+		}
+		return tx.Model(&alert).Updates(map[string]interface{}{"status": models.GeofenceAlertResolved, "resolved_at": now}).Error
+	}
+
+	if asset.HomeGeofenceID == nil {
+		return resolve()
+	}
+	location, ok := assetLocation(asset)
+	if !ok {
+		return nil
+	}
+
+	var geofence models.Geofence
+	if err := tx.First(&geofence, "id = ?", *asset.HomeGeofenceID).Error; err != nil {
+		return err
+	}
+	polygon, err := geofence.Boundary.Polygon()
+	if err != nil {
+		return err
+	}
+	if polygon.Contains(location) {
+		return resolve()
+	}
+
+	var reportedBy *uuid.UUID
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		reportedBy = &userID
+	}
+	distance := geo.DistanceToPolygon(polygon, location)
+	outside := map[string]interface{}{
+		"source":         source,
+		"latitude":       location.Lat,
+		"longitude":      location.Lng,
+		"distance_km":    distance,
+		"reported_by_id": reportedBy,
+		"last_seen_at":   now,
+	}
+
+	if unresolved && alert.GeofenceID == geofence.ID {
+		return tx.Model(&alert).Updates(outside).Error
+	}
+	// An alert for a previous home zone no longer applies
+	if err := resolve(); err != nil {
+		return err
+	}
+	return tx.Create(&models.GeofenceAlert{
+		AssetID:      asset.ID,
+		GeofenceID:   geofence.ID,
+		Source:       source,
+		Latitude:     location.Lat,
+		Longitude:    location.Lng,
+		DistanceKm:   distance,
+		ReportedByID: reportedBy,
+		DetectedAt:   now,
+		LastSeenAt:   now,
+	}).Error
+}
+
+// geofenceRequest parses and validates the request body. When it is invalid the error response
+// is written and the returned request is nil.
+func geofenceRequest(c *fiber.Ctx) (*models.GeofenceRequest, error) {
+	var req models.GeofenceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+	if err := validate.Struct(req); err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	if _, err := req.Boundary.Polygon(); err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "boundary: " + err.Error(),
+		})
+	}
+	return &req, nil
+}
+
+// applyGeofenceRequest copies the request onto a geofence
+func applyGeofenceRequest(geofence *models.Geofence, req *models.GeofenceRequest) {
+	geofence.Name = req.Name
+	geofence.Kind = req.Kind
+	if geofence.Kind == "" {
+		geofence.Kind = models.GeofenceSite
+	}
+	geofence.Description = req.Description
+	geofence.Boundary = *req.Boundary
+}
+
+// geofenceSaveError writes the response for a failed geofence insert or update
+func geofenceSaveError(c *fiber.Ctx, err error) error {
+	if errors.Is(database.TranslateError(database.GetDB(), err), gorm.ErrDuplicatedKey) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   true,
+			"message": "Geofence with this name already exists",
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error":   true,
+		"message": "Failed to save geofence",
+	})
+}
+
+// GetGeofences godoc
+// @Summary List geofences
+// @Description Get a page of geofences, ordered by name
+// @Tags geofences
+// @Produce  json
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page (default 100)"
+// @Success 200 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /geofences [get]
+func GetGeofences(c *fiber.Ctx) error {
+	var geofences []models.Geofence
+	order := []filter.SortKey{{Column: "name"}}
+	pagination, err := listPage(c, database.GetDB().Model(&models.Geofence{}), order, maxPageLimit, &geofences)
+	if err != nil {
+		return pageError(c, err, "Failed to fetch geofences")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       geofences,
+		"message":    "Geofences retrieved successfully",
+		"pagination": pagination,
+	})
+}
+
+// GetGeofence godoc
+// @Summary Get a geofence
+// @Description Get a single geofence by ID
+// @Tags geofences
+// @Produce  json
+// @Param id path string true "Geofence ID"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /geofences/{id} [get]
+func GetGeofence(c *fiber.Ctx) error {
+	var geofence models.Geofence
+	if err := database.GetDB().First(&geofence, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   true,
+				"message": "Geofence not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to fetch geofence",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    geofence,
+		"message": "Geofence retrieved successfully",
+	})
+}
+
+// CreateGeofence godoc
+// @Summary Create a geofence
+// @Description Define a named zone, such as a site, yard or warehouse, by a GeoJSON Polygon
+// @Tags geofences
+// @Accept  json
+// @Produce  json
+// @Param request body models.GeofenceRequest true "Geofence"
+// @Success 201 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /geofences [post]
+func CreateGeofence(c *fiber.Ctx) error {
+	req, err := geofenceRequest(c)
+	if req == nil {
+		return err
+	}
+
+	var geofence models.Geofence
+	applyGeofenceRequest(&geofence, req)
+
+	if err := database.GetDB().Create(&geofence).Error; err != nil {
+		return geofenceSaveError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"error":   false,
+		"data":    geofence,
+		"message": "Geofence created successfully",
+	})
+}
+
+// UpdateGeofence godoc
+// @Summary Replace a geofence
+// @Description Replace a geofence's name, kind, description and boundary. Assets are checked
+// @Description against the new boundary the next time their location is reported.
+// @Tags geofences
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Geofence ID"
+// @Param request body models.GeofenceRequest true "Geofence"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /geofences/{id} [put]
+func UpdateGeofence(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var geofence models.Geofence
+	if err := db.First(&geofence, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   true,
+				"message": "Geofence not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to fetch geofence",
+		})
+	}
+
+	req, err := geofenceRequest(c)
+	if req == nil {
+		return err
+	}
+	applyGeofenceRequest(&geofence, req)
+
+	if err := db.Save(&geofence).Error; err != nil {
+		return geofenceSaveError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    geofence,
+		"message": "Geofence updated successfully",
+	})
+}
+
+// DeleteGeofence godoc
+// @Summary Delete a geofence
+// @Description Delete a geofence and its alerts. Geofences that are still the home zone of an
+// @Description asset cannot be deleted.
+// @Tags geofences
+// @Produce  json
+// @Param id path string true "Geofence ID"
+// @Success 200 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /geofences/{id} [delete]
+func DeleteGeofence(c *fiber.Ctx) error {
+	db := database.GetDB()
+	id := c.Params("id")
+
+	var assigned int64
+	if err := db.Unscoped().Model(&models.Asset{}).Where("home_geofence_id = ?", id).Count(&assigned).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to delete geofence",
+		})
+	}
+	if assigned > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   true,
+			"message": "Geofence is the home zone of assets; reassign them first",
+		})
+	}
+
+	var deleted int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("geofence_id = ?", id).Delete(&models.GeofenceAlert{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Geofence{}, "id = ?", id)
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to delete geofence",
+		})
+	}
+	if deleted == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   true,
+			"message": "Geofence not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"message": "Geofence deleted successfully",
+	})
+}
+
+// GetGeofenceAlerts godoc
+// @Summary List geofence alerts
+// @Description Get a paginated list of alerts for assets reported outside their home zone,
+// @Description most recently seen first
+// @Tags geofences
+// @Produce  json
+// @Param status query string false "open, acknowledged, resolved or unresolved"
+// @Param asset_id query string false "Asset ID"
+// @Param geofence_id query string false "Geofence ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /geofence-alerts [get]
+func GetGeofenceAlerts(c *fiber.Ctx) error {
+	query := database.GetDB().Model(&models.GeofenceAlert{})
+	switch status := c.Query("status"); status {
+	case "", "all":
+	case "unresolved":
+		query = query.Where("status <> ?", models.GeofenceAlertResolved)
+	default:
+		query = query.Where("status = ?", status)
+	}
+	if assetID := c.Query("asset_id"); assetID != "" {
+		query = query.Where("asset_id = ?", assetID)
+	}
+	if geofenceID := c.Query("geofence_id"); geofenceID != "" {
+		query = query.Where("geofence_id = ?", geofenceID)
+	}
+
+	var alerts []models.GeofenceAlert
+	order := []filter.SortKey{{Column: "last_seen_at", Desc: true}}
+	pagination, err := listPage(c, query, order, 20, &alerts, "Asset", "Geofence", "ReportedBy", "AcknowledgedBy")
+	if err != nil {
+		return pageError(c, err, "Failed to fetch geofence alerts")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       alerts,
+		"message":    "Geofence alerts retrieved successfully",
+		"pagination": pagination,
+	})
+}
+
+// AcknowledgeGeofenceAlert godoc
+// @Summary Acknowledge a geofence alert
+// @Description Mark an open alert as seen. It stays unresolved until the asset is reported back
+// @Description inside its home zone.
+// @Tags geofences
+// @Produce  json
+// @Param id path string true "Alert ID"
+// @Success 200 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /geofence-alerts/{id}/acknowledge [post]
+func AcknowledgeGeofenceAlert(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var alert models.GeofenceAlert
+	if err := db.First(&alert, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   true,
+				"message": "Geofence alert not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to fetch geofence alert",
+		})
+	}
+	if alert.Status != models.GeofenceAlertOpen {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   true,
+			"message": "Only open alerts can be acknowledged",
+		})
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"status": models.GeofenceAlertAcknowledged, "acknowledged_at": now}
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		updates["acknowledged_by_id"] = userID
+	}
+	result := db.Model(&alert).Where("status = ?", models.GeofenceAlertOpen).Updates(updates)
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to acknowledge geofence alert",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   true,
+			"message": "Only open alerts can be acknowledged",
+		})
+	}
+
+	db.Preload("Asset").Preload("Geofence").Preload("AcknowledgedBy").First(&alert, "id = ?", alert.ID)
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    alert,
+		"message": "Geofence alert acknowledged",
+	})
+}
@@ -0,0 +1,855 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/filter"
+	"sams-backend/internal/lifecycle"
+	"sams-backend/internal/middleware"
+	"sams-backend/internal/models"
+)
+
+// errCampaignClosed is returned when changing an inventory campaign that was already closed
+var errCampaignClosed = errors.New("inventory campaign is closed")
+
+// findCampaign loads an inventory campaign with its scope, auditors and the people involved
+func findCampaign(db *gorm.DB, id uuid.UUID) (*models.InventoryCampaign, error) {
+	var campaign models.InventoryCampaign
+	err := db.Preload("Department").Preload("Category").Preload("Geofence").Preload("Auditors.User").
+		Preload("CreatedBy").Preload("ClosedBy").First(&campaign, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+// managesCampaign reports whether the current user may run a campaign: campaigns scoped to a
+// department are run by its managers, others by any manager
+func managesCampaign(c *fiber.Ctx, db *gorm.DB, campaign *models.InventoryCampaign) bool {
+	if campaign.DepartmentID == nil {
+		role := middleware.GetCurrentUserRole(c)
+		return role == "admin" || role == "manager"
+	}
+	return managesDepartment(c, db, campaign.DepartmentID)
+}
+
+// campaignAuditors resolves the users assigned to a campaign, all of whom must be active
+func campaignAuditors(db *gorm.DB, campaignID uuid.UUID, userIDs []uuid.UUID) ([]models.InventoryCampaignAuditor, error) {
+	seen := map[uuid.UUID]bool{}
+	var unique []uuid.UUID
+	for _, id := range userIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	var users []models.User
+	if err := db.Where("id IN ?", unique).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	if len(users) != len(unique) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Auditor not found")
+	}
+
+	auditors := make([]models.InventoryCampaignAuditor, 0, len(users))
+	for _, user := range users {
+		if !user.IsActive {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Auditor "+user.Username+" is not active")
+		}
+		auditors = append(auditors, models.InventoryCampaignAuditor{CampaignID: campaignID, UserID: user.ID})
+	}
+	return auditors, nil
+}
+
+// inventoryError writes the response for a failed inventory campaign operation
+func inventoryError(c *fiber.Ctx, err error, message string) error {
+	var fiberErr *fiber.Error
+	var transitionErr *lifecycle.TransitionError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Inventory campaign not found"})
+	case errors.Is(err, errCampaignClosed):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Inventory campaign is closed"})
+	case errors.Is(err, errAssetVersionConflict), errors.Is(err, lifecycle.ErrConflict):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "An asset was modified at the same time; try again"})
+	case errors.As(err, &transitionErr):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": transitionErr.Error()})
+	case errors.As(err, &fiberErr):
+		return c.Status(fiberErr.Code).JSON(fiber.Map{"error": true, "message": fiberErr.Message})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": message})
+}
+
+// campaignID parses the campaign ID route parameter
+func campaignID(c *fiber.Ctx) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "Invalid inventory campaign ID")
+	}
+	return id, nil
+}
+
+// CreateInventoryCampaign godoc
+// @Summary Start an inventory campaign
+// @Description Start a physical inventory of the assets in a department, category, building or
+// @Description room, or home zone, assigning the auditors who will scan them. A campaign scoped
+// @Description to a department can only be started by a manager of that department.
+// @Tags inventory
+// @Accept  json
+// @Produce  json
+// @Param request body models.InventoryCampaignRequest true "Campaign"
+// @Success 201 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Router /inventory-campaigns [post]
+func CreateInventoryCampaign(c *fiber.Ctx) error {
+	db := database.GetDB()
+
+	var req models.InventoryCampaignRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	req.BuildingRoom = strings.TrimSpace(req.BuildingRoom)
+	if err := validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": err.Error()})
+	}
+
+	campaign := models.InventoryCampaign{
+		Name:         req.Name,
+		Description:  req.Description,
+		DepartmentID: req.DepartmentID,
+		CategoryID:   req.CategoryID,
+		BuildingRoom: req.BuildingRoom,
+		GeofenceID:   req.GeofenceID,
+		StartsAt:     time.Now(),
+		EndsAt:       req.EndsAt,
+		Status:       models.InventoryCampaignOpen,
+	}
+	if req.StartsAt != nil {
+		campaign.StartsAt = *req.StartsAt
+	}
+	if campaign.EndsAt != nil && !campaign.EndsAt.After(campaign.StartsAt) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "ends_at must be after starts_at"})
+	}
+
+	if req.DepartmentID != nil {
+		if err := db.First(&models.Department{}, "id = ?", *req.DepartmentID).Error; err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Department not found"})
+		}
+	}
+	if req.CategoryID != nil {
+		if err := db.First(&models.Category{}, "id = ?", *req.CategoryID).Error; err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Category not found"})
+		}
+	}
+	if req.GeofenceID != nil {
+		if err := db.First(&models.Geofence{}, "id = ?", *req.GeofenceID).Error; err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Geofence not found"})
+		}
+	}
+	if !managesCampaign(c, db, &campaign) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": true, "message": "Only a manager of the department can start its inventory"})
+	}
+
+	campaign.ID = uuid.New()
+	auditors, err := campaignAuditors(db, campaign.ID, req.AuditorIDs)
+	if err != nil {
+		return inventoryError(c, err, "Failed to fetch auditors")
+	}
+	campaign.Auditors = auditors
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		campaign.CreatedByID = &userID
+	}
+
+	if err := db.Create(&campaign).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to create inventory campaign"})
+	}
+
+	created, err := findCampaign(db, campaign.ID)
+	if err != nil {
+		return inventoryError(c, err, "Failed to fetch inventory campaign")
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"error":   false,
+		"data":    created,
+		"message": "Inventory campaign created successfully",
+	})
+}
+
+// GetInventoryCampaigns godoc
+// @Summary List inventory campaigns
+// @Description Get inventory campaigns, most recent first; ?status= limits them to open or closed
+// @Description campaigns and ?assigned=true to those the current user audits
+// @Tags inventory
+// @Produce  json
+// @Param status query string false "open or closed"
+// @Param assigned query bool false "Only campaigns the current user audits"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /inventory-campaigns [get]
+func GetInventoryCampaigns(c *fiber.Ctx) error {
+	db := database.GetDB()
+	query := db.Model(&models.InventoryCampaign{})
+	if status := c.Query("status"); status != "" && status != "all" {
+		query = query.Where("status = ?", status)
+	}
+	if c.QueryBool("assigned") {
+		userID, err := middleware.GetCurrentUserID(c)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": true, "message": "User not authenticated"})
+		}
+		query = query.Where("id IN (?)", db.Model(&models.InventoryCampaignAuditor{}).Select("campaign_id").Where("user_id = ?", userID))
+	}
+
+	var campaigns []models.InventoryCampaign
+	order := []filter.SortKey{{Column: "created_at", Desc: true}}
+	pagination, err := listPage(c, query, order, 20, &campaigns, "Department", "Category", "Geofence", "Auditors.User")
+	if err != nil {
+		return pageError(c, err, "Failed to fetch inventory campaigns")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       campaigns,
+		"message":    "Inventory campaigns retrieved successfully",
+		"pagination": pagination,
+	})
+}
+
+// GetInventoryCampaign godoc
+// @Summary Get an inventory campaign
+// @Description Get a single inventory campaign with its scope and auditors
+// @Tags inventory
+// @Produce  json
+// @Param id path string true "Campaign ID"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /inventory-campaigns/{id} [get]
+func GetInventoryCampaign(c *fiber.Ctx) error {
+	id, err := campaignID(c)
+	if err != nil {
+		return inventoryError(c, err, "")
+	}
+	campaign, err := findCampaign(database.GetDB(), id)
+	if err != nil {
+		return inventoryError(c, err, "Failed to fetch inventory campaign")
+	}
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    campaign,
+		"message": "Inventory campaign retrieved successfully",
+	})
+}
+
+// UpdateInventoryAuditors godoc
+// @Summary Replace the auditors of an inventory campaign
+// @Description Replace the users assigned to scan assets for an open campaign
+// @Tags inventory
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Campaign ID"
+// @Param request body models.InventoryAuditorsRequest true "Auditors"
+// @Success 200 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /inventory-campaigns/{id}/auditors [put]
+func UpdateInventoryAuditors(c *fiber.Ctx) error {
+	db := database.GetDB()
+	id, err := campaignID(c)
+	if err != nil {
+		return inventoryError(c, err, "")
+	}
+
+	var req models.InventoryAuditorsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+	if err := validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": err.Error()})
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var campaign models.InventoryCampaign
+		if err := tx.First(&campaign, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if campaign.Status != models.InventoryCampaignOpen {
+			return errCampaignClosed
+		}
+		if !managesCampaign(c, tx, &campaign) {
+			return fiber.NewError(fiber.StatusForbidden, "Only a manager of the department can change its inventory")
+		}
+
+		auditors, err := campaignAuditors(tx, campaign.ID, req.AuditorIDs)
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("campaign_id = ?", campaign.ID).Delete(&models.InventoryCampaignAuditor{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&auditors).Error
+	})
+	if err != nil {
+		return inventoryError(c, err, "Failed to update auditors")
+	}
+
+	campaign, err := findCampaign(db, id)
+	if err != nil {
+		return inventoryError(c, err, "Failed to fetch inventory campaign")
+	}
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    campaign,
+		"message": "Auditors updated successfully",
+	})
+}
+
+// CreateInventoryScan godoc
+// @Summary Submit an inventory scan
+// @Description Record a code scanned by one of the campaign's auditors, and where the item was
+// @Description found. Codes that match an asset also record a sighting of it; codes that match
+// @Description none are kept as items that are not in the register.
+// @Tags inventory
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Campaign ID"
+// @Param request body models.InventoryScanRequest true "Scanned code and observations"
+// @Success 201 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /inventory-campaigns/{id}/scans [post]
+func CreateInventoryScan(c *fiber.Ctx) error {
+	db := database.GetDB()
+	id, err := campaignID(c)
+	if err != nil {
+		return inventoryError(c, err, "")
+	}
+
+	var req models.InventoryScanRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+	req.Payload = strings.TrimSpace(req.Payload)
+	req.BuildingRoom = strings.TrimSpace(req.BuildingRoom)
+	if err := validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": err.Error()})
+	}
+	scannedAt, err := scanTime(&req.ScanRequest)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": err.Error()})
+	}
+
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": true, "message": "User not authenticated"})
+	}
+
+	var campaign models.InventoryCampaign
+	if err := db.First(&campaign, "id = ?", id).Error; err != nil {
+		return inventoryError(c, err, "Failed to fetch inventory campaign")
+	}
+	if campaign.Status != models.InventoryCampaignOpen {
+		return inventoryError(c, errCampaignClosed, "")
+	}
+	if scannedAt.Before(campaign.StartsAt) || (campaign.EndsAt != nil && scannedAt.After(*campaign.EndsAt)) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Scan falls outside the campaign period"})
+	}
+
+	var assigned int64
+	if err := db.Model(&models.InventoryCampaignAuditor{}).Where("campaign_id = ? AND user_id = ?", campaign.ID, userID).Count(&assigned).Error; err != nil {
+		return inventoryError(c, err, "Failed to check auditors")
+	}
+	if assigned == 0 {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": true, "message": "Only the campaign's auditors can submit scans"})
+	}
+
+	// A code that matches no asset is a finding in its own right
+	asset, err := resolveScan(db, req.Payload)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) && fiberErr.Code == fiber.StatusNotFound {
+		asset, err = nil, nil
+	}
+	if err != nil {
+		return inventoryError(c, err, "Failed to resolve scanned code")
+	}
+
+	scan := models.InventoryScan{
+		CampaignID:   campaign.ID,
+		Payload:      req.Payload,
+		BuildingRoom: req.BuildingRoom,
+		Latitude:     req.Latitude,
+		Longitude:    req.Longitude,
+		Condition:    req.Condition,
+		Notes:        req.Notes,
+		ScannedByID:  &userID,
+		ScannedAt:    scannedAt,
+	}
+	if asset != nil {
+		scan.AssetID = &asset.ID
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&scan).Error; err != nil {
+			return err
+		}
+		if asset == nil {
+			return nil
+		}
+		return recordSighting(c, tx, asset, &models.AssetSighting{
+			AssetID:     asset.ID,
+			Payload:     req.Payload,
+			ScannedByID: &userID,
+			ScannedAt:   scannedAt,
+			Latitude:    req.Latitude,
+			Longitude:   req.Longitude,
+			Condition:   req.Condition,
+			Notes:       req.Notes,
+		}, models.LocationSourceInventory)
+	})
+	if err != nil {
+		return inventoryError(c, err, "Failed to record scan")
+	}
+
+	message := "Scan recorded successfully"
+	if asset == nil {
+		message = "Scan recorded; the code matches no asset in the register"
+	} else {
+		db.Preload("Category").Preload("Department").First(asset, "id = ?", asset.ID)
+		scan.Asset = asset
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"error":   false,
+		"data":    scan,
+		"message": message,
+	})
+}
+
+// GetInventoryScans godoc
+// @Summary List inventory scans
+// @Description Get the scans submitted for a campaign, most recent first
+// @Tags inventory
+// @Produce  json
+// @Param id path string true "Campaign ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /inventory-campaigns/{id}/scans [get]
+func GetInventoryScans(c *fiber.Ctx) error {
+	id, err := campaignID(c)
+	if err != nil {
+		return inventoryError(c, err, "")
+	}
+
+	query := database.GetDB().Model(&models.InventoryScan{}).Where("campaign_id = ?", id)
+	var scans []models.InventoryScan
+	order := []filter.SortKey{{Column: "scanned_at", Desc: true}}
+	pagination, err := listPage(c, query, order, 50, &scans, "Asset", "ScannedBy")
+	if err != nil {
+		return pageError(c, err, "Failed to fetch inventory scans")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       scans,
+		"message":    "Inventory scans retrieved successfully",
+		"pagination": pagination,
+	})
+}
+
+// campaignAssets returns a query over the assets in a campaign's scope. Disposed assets are no
+// longer expected to be found.
+func campaignAssets(db *gorm.DB, campaign *models.InventoryCampaign) *gorm.DB {
+	query := db.Model(&models.Asset{}).Where("status <> ?", lifecycle.StatusDisposed)
+	if campaign.DepartmentID != nil {
+		query = query.Where("department_id = ?", *campaign.DepartmentID)
+	}
+	if campaign.CategoryID != nil {
+		query = query.Where("category_id = ?", *campaign.CategoryID)
+	}
+	if campaign.BuildingRoom != "" {
+		query = query.Where("LOWER(TRIM(building_room)) = LOWER(?)", campaign.BuildingRoom)
+	}
+	if campaign.GeofenceID != nil {
+		query = query.Where("home_geofence_id = ?", *campaign.GeofenceID)
+	}
+	return query
+}
+
+// campaignCovers reports whether an asset is in a campaign's scope, matching campaignAssets
+func campaignCovers(campaign *models.InventoryCampaign, asset *models.Asset) bool {
+	return asset.Status != lifecycle.StatusDisposed &&
+		(campaign.DepartmentID == nil || sameValue(asset.DepartmentID, campaign.DepartmentID)) &&
+		(campaign.CategoryID == nil || sameValue(asset.CategoryID, campaign.CategoryID)) &&
+		(campaign.BuildingRoom == "" || strings.EqualFold(strings.TrimSpace(asset.BuildingRoom), campaign.BuildingRoom)) &&
+		(campaign.GeofenceID == nil || sameValue(asset.HomeGeofenceID, campaign.GeofenceID))
+}
+
+// misplacement returns why an asset was found somewhere the register does not have it: in
+// another department's inventory, or in another building or room or home zone
+func misplacement(campaign *models.InventoryCampaign, asset *models.Asset, scan *models.InventoryScan) []string {
+	var reasons []string
+	if campaign.DepartmentID != nil && !sameValue(asset.DepartmentID, campaign.DepartmentID) {
+		reasons = append(reasons, "department")
+	}
+
+	foundIn := scan.BuildingRoom
+	if foundIn == "" {
+		foundIn = campaign.BuildingRoom
+	}
+	if (foundIn != "" && !strings.EqualFold(strings.TrimSpace(asset.BuildingRoom), foundIn)) ||
+		(campaign.GeofenceID != nil && !sameValue(asset.HomeGeofenceID, campaign.GeofenceID)) {
+		reasons = append(reasons, "location")
+	}
+	return reasons
+}
+
+// reconcileCampaign compares the scans of a campaign with the register. The latest scan of each
+// asset or unmatched code counts; assets in scope that were not scanned are missing.
+func reconcileCampaign(db *gorm.DB, campaign *models.InventoryCampaign) (*models.InventoryReconciliation, error) {
+	var scans []models.InventoryScan
+	if err := db.Preload("ScannedBy").Where("campaign_id = ?", campaign.ID).Order("scanned_at").Order("id").Find(&scans).Error; err != nil {
+		return nil, err
+	}
+
+	latest := map[uuid.UUID]*models.InventoryScan{}
+	unmatched := map[string]*models.InventoryScan{}
+	var assetIDs []uuid.UUID
+	for i := range scans {
+		scan := &scans[i]
+		if scan.AssetID == nil {
+			unmatched[strings.ToUpper(scan.Payload)] = scan
+			continue
+		}
+		if _, ok := latest[*scan.AssetID]; !ok {
+			assetIDs = append(assetIDs, *scan.AssetID)
+		}
+		latest[*scan.AssetID] = scan
+	}
+
+	report := &models.InventoryReconciliation{
+		Campaign:      campaign,
+		Found:         []models.InventoryReconciliationItem{},
+		Missing:       []models.InventoryReconciliationItem{},
+		WrongLocation: []models.InventoryReconciliationItem{},
+		NotInRegister: []models.InventoryReconciliationItem{},
+	}
+
+	var scanned []models.Asset
+	if len(assetIDs) > 0 {
+		if err := db.Preload("Category").Preload("Department").Where("id IN ?", assetIDs).Order("name").Find(&scanned).Error; err != nil {
+			return nil, err
+		}
+	}
+	for i := range scanned {
+		asset := &scanned[i]
+		scan := latest[asset.ID]
+		delete(latest, asset.ID)
+		item := models.InventoryReconciliationItem{
+			Result:  models.InventoryFound,
+			Asset:   asset,
+			Scan:    scan,
+			Reasons: misplacement(campaign, asset, scan),
+			InScope: campaignCovers(campaign, asset),
+		}
+		if len(item.Reasons) > 0 {
+			item.Result = models.InventoryWrongLocation
+			report.WrongLocation = append(report.WrongLocation, item)
+		} else {
+			report.Found = append(report.Found, item)
+		}
+	}
+
+	// Codes that matched no asset, and assets deleted from the register since they were scanned
+	for i := range scans {
+		scan := &scans[i]
+		if scan.AssetID == nil && unmatched[strings.ToUpper(scan.Payload)] != scan {
+			continue
+		}
+		if scan.AssetID != nil && latest[*scan.AssetID] != scan {
+			continue
+		}
+		report.NotInRegister = append(report.NotInRegister, models.InventoryReconciliationItem{
+			Result: models.InventoryNotInRegister,
+			Scan:   scan,
+		})
+	}
+
+	query := campaignAssets(db, campaign).Preload("Category").Preload("Department")
+	if len(assetIDs) > 0 {
+		query = query.Where("id NOT IN ?", assetIDs)
+	}
+	var missing []models.Asset
+	if err := query.Order("name").Find(&missing).Error; err != nil {
+		return nil, err
+	}
+	for i := range missing {
+		report.Missing = append(report.Missing, models.InventoryReconciliationItem{
+			Result:  models.InventoryMissing,
+			Asset:   &missing[i],
+			InScope: true,
+		})
+	}
+
+	report.Summary = map[string]int{
+		models.InventoryFound:         len(report.Found),
+		models.InventoryMissing:       len(report.Missing),
+		models.InventoryWrongLocation: len(report.WrongLocation),
+		models.InventoryNotInRegister: len(report.NotInRegister),
+	}
+	return report, nil
+}
+
+// GetInventoryReconciliation godoc
+// @Summary Get the reconciliation report of an inventory campaign
+// @Description List the assets found, missing and found in the wrong department or location,
+// @Description and the scanned codes that are not in the register, against the register as it
+// @Description stands now
+// @Tags inventory
+// @Produce  json
+// @Param id path string true "Campaign ID"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /inventory-campaigns/{id}/reconciliation [get]
+func GetInventoryReconciliation(c *fiber.Ctx) error {
+	db := database.GetDB()
+	id, err := campaignID(c)
+	if err != nil {
+		return inventoryError(c, err, "")
+	}
+
+	campaign, err := findCampaign(db, id)
+	if err != nil {
+		return inventoryError(c, err, "Failed to fetch inventory campaign")
+	}
+	report, err := reconcileCampaign(db, campaign)
+	if err != nil {
+		return inventoryError(c, err, "Failed to reconcile inventory campaign")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    report,
+		"message": "Reconciliation retrieved successfully",
+	})
+}
+
+// applyInventoryFix corrects an asset's location and status, recording the changes in its
+// history and status events. It reports whether the asset changed.
+func applyInventoryFix(c *fiber.Ctx, tx *gorm.DB, campaign *models.InventoryCampaign, fix models.InventoryFix, changedBy *uuid.UUID) (bool, error) {
+	var asset models.Asset
+	if err := tx.First(&asset, "id = ?", fix.AssetID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, fiber.NewError(fiber.StatusBadRequest, "Asset "+fix.AssetID.String()+" not found")
+		}
+		return false, err
+	}
+	if asset.Status == lifecycle.StatusDisposed {
+		return false, fiber.NewError(fiber.StatusConflict, "Asset "+asset.Name+" is disposed and cannot be changed")
+	}
+
+	before := asset
+	if fix.Address != "" {
+		asset.Address = fix.Address
+	}
+	if fix.BuildingRoom != "" {
+		asset.BuildingRoom = fix.BuildingRoom
+	}
+	if fix.Latitude != nil {
+		asset.Latitude = fix.Latitude
+		asset.Longitude = fix.Longitude
+	}
+	if asset.Address != before.Address || asset.BuildingRoom != before.BuildingRoom || locationChanged(&before, &asset) {
+		if err := saveAssetVersion(tx, &asset); err != nil {
+			return false, err
+		}
+		if locationChanged(&before, &asset) {
+			if err := checkGeofence(c, tx, &asset, models.LocationSourceInventory); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	if fix.Status != "" && fix.Status != asset.Status {
+		reason := strings.TrimSpace(fix.Reason)
+		if reason == "" {
+			reason = "Inventory campaign " + campaign.Name
+		}
+		if _, err := lifecycle.Transition(tx, &asset, fix.Status, reason, changedBy); err != nil {
+			return false, err
+		}
+	}
+
+	if err := recordAssetHistory(c, tx, before, asset); err != nil {
+		return false, err
+	}
+	return asset.Version != before.Version, nil
+}
+
+// CloseInventoryCampaign godoc
+// @Summary Close an inventory campaign
+// @Description Close a campaign, recording its reconciliation and applying fixes to the register.
+// @Description missing_status moves every missing asset to that status, update_locations moves
+// @Description assets found in the wrong location to where they were found, and fixes correct
+// @Description individual assets. Status changes go through the asset lifecycle and every change
+// @Description is recorded in the asset's history. Department differences are resolved through
+// @Description transfers.
+// @Tags inventory
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Campaign ID"
+// @Param request body models.InventoryCloseRequest false "Fixes to apply"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /inventory-campaigns/{id}/close [post]
+func CloseInventoryCampaign(c *fiber.Ctx) error {
+	db := database.GetDB()
+	id, err := campaignID(c)
+	if err != nil {
+		return inventoryError(c, err, "")
+	}
+
+	var req models.InventoryCloseRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+		}
+	}
+	if err := validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": err.Error()})
+	}
+	seen := map[uuid.UUID]bool{}
+	for i, fix := range req.Fixes {
+		if (fix.Latitude == nil) != (fix.Longitude == nil) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "latitude and longitude must be given together"})
+		}
+		if seen[fix.AssetID] {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Asset " + fix.AssetID.String() + " has more than one fix"})
+		}
+		seen[fix.AssetID] = true
+		req.Fixes[i].BuildingRoom = strings.TrimSpace(fix.BuildingRoom)
+	}
+
+	var closedBy *uuid.UUID
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		closedBy = &userID
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var campaign models.InventoryCampaign
+		if err := tx.First(&campaign, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if campaign.Status != models.InventoryCampaignOpen {
+			return errCampaignClosed
+		}
+		if !managesCampaign(c, tx, &campaign) {
+			return fiber.NewError(fiber.StatusForbidden, "Only a manager of the department can close its inventory")
+		}
+
+		report, err := reconcileCampaign(tx, &campaign)
+		if err != nil {
+			return err
+		}
+
+		// Fixes given for an asset replace the ones requested for all missing or misplaced assets
+		fixes := map[uuid.UUID]models.InventoryFix{}
+		var order []uuid.UUID
+		add := func(fix models.InventoryFix) {
+			if _, ok := fixes[fix.AssetID]; !ok {
+				order = append(order, fix.AssetID)
+			}
+			fixes[fix.AssetID] = fix
+		}
+
+		reconciled := map[uuid.UUID]bool{}
+		for _, item := range report.Found {
+			reconciled[item.Asset.ID] = true
+		}
+		for _, item := range report.Missing {
+			reconciled[item.Asset.ID] = true
+			if req.MissingStatus != "" && item.Asset.Status != req.MissingStatus {
+				add(models.InventoryFix{
+					AssetID: item.Asset.ID,
+					Status:  req.MissingStatus,
+					Reason:  "Not found in inventory campaign " + campaign.Name,
+				})
+			}
+		}
+		for _, item := range report.WrongLocation {
+			reconciled[item.Asset.ID] = true
+			if !req.UpdateLocations || item.Asset.Status == lifecycle.StatusDisposed {
+				continue
+			}
+			fix := models.InventoryFix{
+				AssetID:      item.Asset.ID,
+				BuildingRoom: item.Scan.BuildingRoom,
+				Latitude:     item.Scan.Latitude,
+				Longitude:    item.Scan.Longitude,
+			}
+			if fix.BuildingRoom == "" {
+				fix.BuildingRoom = campaign.BuildingRoom
+			}
+			if fix.BuildingRoom != "" || fix.Latitude != nil {
+				add(fix)
+			}
+		}
+		for _, fix := range req.Fixes {
+			if !reconciled[fix.AssetID] {
+				return fiber.NewError(fiber.StatusBadRequest, "Asset "+fix.AssetID.String()+" is not part of this campaign")
+			}
+			add(fix)
+		}
+
+		fixed := 0
+		for _, assetID := range order {
+			changed, err := applyInventoryFix(c, tx, &campaign, fixes[assetID], closedBy)
+			if err != nil {
+				return err
+			}
+			if changed {
+				fixed++
+			}
+		}
+
+		now := time.Now()
+		campaign.Status = models.InventoryCampaignClosed
+		campaign.ClosedByID = closedBy
+		campaign.ClosedAt = &now
+		campaign.CloseNotes = req.Notes
+		campaign.FoundCount = len(report.Found)
+		campaign.MissingCount = len(report.Missing)
+		campaign.WrongLocationCount = len(report.WrongLocation)
+		campaign.NotInRegisterCount = len(report.NotInRegister)
+		campaign.FixedAssetCount = fixed
+		return tx.Save(&campaign).Error
+	})
+	if err != nil {
+		return inventoryError(c, err, "Failed to close inventory campaign")
+	}
+
+	campaign, err := findCampaign(db, id)
+	if err != nil {
+		return inventoryError(c, err, "Failed to fetch inventory campaign")
+	}
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    campaign,
+		"message": "Inventory campaign closed",
+	})
+}
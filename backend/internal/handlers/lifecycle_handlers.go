@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/lifecycle"
+	"sams-backend/internal/middleware"
+	"sams-backend/internal/models"
+)
+
+// TransitionAsset godoc
+// @Summary Change asset lifecycle status
+// @Description Move an asset to a new status. Only allowed transitions are accepted, and moving
+// @Description to inactive requires a reason. Disposal goes through a disposal request instead.
+// @Description Each transition is recorded as a status event.
+// @Tags assets
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Asset ID"
+// @Param transition body models.AssetTransitionRequest true "Target status and reason"
+// @Success 200 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /assets/{id}/transitions [post]
+func TransitionAsset(c *fiber.Ctx) error {
+	db := database.GetDB()
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid asset ID",
+		})
+	}
+
+	var req models.AssetTransitionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	if lifecycle.RequiresWorkflow(req.Status) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   true,
+			"message": "Assets are disposed through POST /api/v1/assets/:id/disposals",
+		})
+	}
+
+	var asset models.Asset
+	if err := db.First(&asset, "id = ?", assetID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   true,
+				"message": "Asset not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to fetch asset",
+		})
+	}
+
+	var changedBy *uuid.UUID
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		changedBy = &userID
+	}
+
+	var event *models.AssetStatusEvent
+	err = db.Transaction(func(tx *gorm.DB) error {
+		before := asset
+		var err error
+		if event, err = lifecycle.Transition(tx, &asset, req.Status, req.Reason, changedBy); err != nil {
+			return err
+		}
+		return recordAssetHistory(c, tx, before, asset)
+	})
+	if err != nil {
+		var transitionErr *lifecycle.TransitionError
+		if errors.As(err, &transitionErr) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":   true,
+				"message": transitionErr.Error(),
+			})
+		}
+		if errors.Is(err, lifecycle.ErrConflict) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":   true,
+				"message": "Asset was modified by another request; try again",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to change asset status",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    event,
+		"message": "Asset status changed successfully",
+	})
+}
+
+// GetAssetTransitions godoc
+// @Summary Get asset lifecycle events
+// @Description Get the status events of an asset along with the transitions currently allowed
+// @Tags assets
+// @Produce  json
+// @Param id path string true "Asset ID"
+// @Success 200 {object} fiber.Map
+// @Router /assets/{id}/transitions [get]
+func GetAssetTransitions(c *fiber.Ctx) error {
+	db := database.GetDB()
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid asset ID",
+		})
+	}
+
+	var asset models.Asset
+	if err := db.Unscoped().First(&asset, "id = ?", assetID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   true,
+				"message": "Asset not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to fetch asset",
+		})
+	}
+
+	var events []models.AssetStatusEvent
+	if err := db.Preload("ChangedBy").Where("asset_id = ?", assetID).Order("created_at DESC").Find(&events).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to fetch status events",
+		})
+	}
+
+	allowed := []fiber.Map{}
+	for _, status := range lifecycle.AllowedTransitions(asset.Status) {
+		allowed = append(allowed, fiber.Map{
+			"status":            status,
+			"requires_reason":   lifecycle.RequiresReason(status),
+			"requires_workflow": lifecycle.RequiresWorkflow(status),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error": false,
+		"data": fiber.Map{
+			"status":  asset.Status,
+			"allowed": allowed,
+			"events":  events,
+		},
+		"message": "Status events retrieved successfully",
+	})
+}
@@ -0,0 +1,486 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/filter"
+	"sams-backend/internal/lifecycle"
+	"sams-backend/internal/maintenance"
+	"sams-backend/internal/middleware"
+	"sams-backend/internal/models"
+)
+
+// workOrderTransitions lists the statuses a work order may move to from each status.
+// Completion goes through CompleteWorkOrder so that costs and notes are captured.
+var workOrderTransitions = map[string][]string{
+	models.WorkOrderOpen:       {models.WorkOrderInProgress, models.WorkOrderOnHold, models.WorkOrderCancelled},
+	models.WorkOrderInProgress: {models.WorkOrderOnHold, models.WorkOrderCancelled},
+	models.WorkOrderOnHold:     {models.WorkOrderOpen, models.WorkOrderInProgress, models.WorkOrderCancelled},
+}
+
+// GetMaintenancePlans returns a page of maintenance plans, soonest due first, optionally filtered
+// by asset
+// @Failure 500 {object} fiber.Map
+// @Router /maintenance-plans [get]
+func GetMaintenancePlans(c *fiber.Ctx) error {
+	db := database.GetDB()
+	query := db.Model(&models.MaintenancePlan{})
+	if assetID := c.Query("asset_id"); assetID != "" {
+		query = query.Where("asset_id = ?", assetID)
+	}
+	if active := c.Query("active"); active != "" {
+		query = query.Where("is_active = ?", c.QueryBool("active"))
+	}
+
+	var plans []models.MaintenancePlan
+	order := []filter.SortKey{{Column: "next_due_at"}}
+	pagination, err := listPage(c, query, order, 20, &plans, "Asset", "DefaultAssignee")
+	if err != nil {
+		return pageError(c, err, "Failed to fetch maintenance plans")
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": plans, "pagination": pagination})
+}
+
+// GetMaintenancePlan returns a single maintenance plan by ID
+// @Failure 500 {object} fiber.Map
+// @Router /maintenance-plans/{id} [get]
+func GetMaintenancePlan(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var plan models.MaintenancePlan
+
+	if err := db.Preload("Asset").Preload("DefaultAssignee").First(&plan, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Maintenance plan not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch maintenance plan"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": plan})
+}
+
+// CreateMaintenancePlan creates a new preventive maintenance plan
+// @Failure 409 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /maintenance-plans [post]
+func CreateMaintenancePlan(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var plan models.MaintenancePlan
+	if err := c.BodyParser(&plan); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+
+	if plan.Name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Plan name is required"})
+	}
+	if err := maintenance.ValidatePlan(&plan); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": err.Error()})
+	}
+
+	var asset models.Asset
+	if err := db.First(&asset, "id = ?", plan.AssetID).Error; err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Asset not found"})
+	}
+	if asset.Status == lifecycle.StatusDisposed {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Disposed assets cannot be maintained"})
+	}
+
+	plan.ID = uuid.Nil
+	plan.IsActive = true
+	plan.NextDueAt = maintenance.InitialDue(&plan)
+	plan.LastGeneratedAt = nil
+	plan.LastCompletedAt = nil
+
+	if err := db.Create(&plan).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to create maintenance plan"})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"error": false, "data": plan})
+}
+
+// UpdateMaintenancePlan updates a maintenance plan, rescheduling it if its recurrence changed
+// @Failure 500 {object} fiber.Map
+// @Router /maintenance-plans/{id} [put]
+func UpdateMaintenancePlan(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var plan models.MaintenancePlan
+
+	if err := db.First(&plan, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Maintenance plan not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch maintenance plan"})
+	}
+
+	before := plan
+	if err := c.BodyParser(&plan); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+
+	// Identity and scheduling state are not editable
+	plan.ID = before.ID
+	plan.AssetID = before.AssetID
+	plan.NextDueAt = before.NextDueAt
+	plan.LastGeneratedAt = before.LastGeneratedAt
+	plan.LastCompletedAt = before.LastCompletedAt
+
+	if err := maintenance.ValidatePlan(&plan); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": err.Error()})
+	}
+
+	recurrenceChanged := plan.ScheduleType != before.ScheduleType ||
+		plan.RRule != before.RRule ||
+		!plan.StartDate.Equal(before.StartDate) ||
+		(plan.IntervalDays == nil) != (before.IntervalDays == nil) ||
+		(plan.IntervalDays != nil && *plan.IntervalDays != *before.IntervalDays)
+	if recurrenceChanged {
+		plan.NextDueAt = maintenance.InitialDue(&plan)
+	}
+
+	if err := db.Save(&plan).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to update maintenance plan"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": plan})
+}
+
+// DeleteMaintenancePlan deletes a maintenance plan; work orders already generated are kept
+// @Failure 500 {object} fiber.Map
+// @Router /maintenance-plans/{id} [delete]
+func DeleteMaintenancePlan(c *fiber.Ctx) error {
+	db := database.GetDB()
+
+	result := db.Delete(&models.MaintenancePlan{}, "id = ?", c.Params("id"))
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to delete maintenance plan"})
+	}
+
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Maintenance plan not found"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "message": "Maintenance plan deleted successfully"})
+}
+
+// GenerateWorkOrders runs the maintenance scheduler immediately
+// @Failure 500 {object} fiber.Map
+// @Router /maintenance-plans/generate [post]
+func GenerateWorkOrders(c *fiber.Ctx) error {
+	created, err := maintenance.GenerateDueWorkOrders(database.GetDB(), time.Now())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to generate work orders"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": fiber.Map{"created": created}})
+}
+
+// GetWorkOrders returns a paginated list of work orders
+// @Param status query string false "Work order status"
+// @Param asset_id query string false "Asset ID"
+// @Param assignee_id query string false "Assignee user ID"
+// @Param overdue query bool false "Only open work orders past their due date"
+// @Failure 500 {object} fiber.Map
+// @Router /work-orders [get]
+func GetWorkOrders(c *fiber.Ctx) error {
+	db := database.GetDB()
+	query := db.Model(&models.WorkOrder{})
+	if status := c.Query("status"); status != "" && status != "all" {
+		query = query.Where("status = ?", status)
+	}
+	if assetID := c.Query("asset_id"); assetID != "" {
+		query = query.Where("asset_id = ?", assetID)
+	}
+	if assigneeID := c.Query("assignee_id"); assigneeID != "" {
+		query = query.Where("assignee_id = ?", assigneeID)
+	}
+	if c.QueryBool("overdue") {
+		query = query.Where("status NOT IN ? AND due_date < ?", []string{models.WorkOrderCompleted, models.WorkOrderCancelled}, time.Now())
+	}
+
+	var workOrders []models.WorkOrder
+	order := []filter.SortKey{{Column: "due_date"}, {Column: "created_at", Desc: true}}
+	pagination, err := listPage(c, query, order, 20, &workOrders, "Asset", "Assignee")
+	if err != nil {
+		return pageError(c, err, "Failed to fetch work orders")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       workOrders,
+		"message":    "Work orders retrieved successfully",
+		"pagination": pagination,
+	})
+}
+
+// GetWorkOrder returns a single work order by ID
+// @Failure 500 {object} fiber.Map
+// @Router /work-orders/{id} [get]
+func GetWorkOrder(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var workOrder models.WorkOrder
+
+	if err := db.Preload("Asset").Preload("Plan").Preload("Assignee").First(&workOrder, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Work order not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch work order"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": workOrder})
+}
+
+// CreateWorkOrder creates an ad-hoc (corrective) work order
+// @Failure 409 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /work-orders [post]
+func CreateWorkOrder(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var workOrder models.WorkOrder
+	if err := c.BodyParser(&workOrder); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+
+	if workOrder.Title == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Work order title is required"})
+	}
+
+	var asset models.Asset
+	if err := db.First(&asset, "id = ?", workOrder.AssetID).Error; err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Asset not found"})
+	}
+	if asset.Status == lifecycle.StatusDisposed {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Disposed assets cannot be maintained"})
+	}
+
+	workOrder.ID = uuid.Nil
+	workOrder.PlanID = nil
+	workOrder.Status = models.WorkOrderOpen
+	workOrder.StartedAt = nil
+	workOrder.CompletedAt = nil
+	workOrder.CompletedByID = nil
+
+	if err := db.Create(&workOrder).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to create work order"})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"error": false, "data": workOrder})
+}
+
+// UpdateWorkOrder updates the details of an open work order (title, description, assignee, due date, costs)
+// @Failure 500 {object} fiber.Map
+// @Router /work-orders/{id} [put]
+func UpdateWorkOrder(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var workOrder models.WorkOrder
+
+	if err := db.First(&workOrder, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Work order not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch work order"})
+	}
+
+	if workOrder.IsClosed() {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Closed work orders cannot be edited"})
+	}
+
+	before := workOrder
+	if err := c.BodyParser(&workOrder); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+
+	// Lifecycle fields only change through the status and complete endpoints
+	workOrder.ID = before.ID
+	workOrder.AssetID = before.AssetID
+	workOrder.PlanID = before.PlanID
+	workOrder.Status = before.Status
+	workOrder.StartedAt = before.StartedAt
+	workOrder.CompletedAt = before.CompletedAt
+	workOrder.CompletedByID = before.CompletedByID
+
+	if workOrder.Title == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Work order title is required"})
+	}
+
+	if err := db.Save(&workOrder).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to update work order"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": workOrder})
+}
+
+// UpdateWorkOrderStatus moves a work order through its lifecycle (start, hold, reopen, cancel)
+// @Failure 500 {object} fiber.Map
+// @Router /work-orders/{id}/status [post]
+func UpdateWorkOrderStatus(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var req models.WorkOrderStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": err.Error()})
+	}
+
+	var workOrder models.WorkOrder
+	if err := db.First(&workOrder, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Work order not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch work order"})
+	}
+
+	if !canWorkOnOrder(c, &workOrder) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": true, "message": "Insufficient permissions"})
+	}
+
+	if !isAllowedTransition(workOrderTransitions, workOrder.Status, req.Status) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Cannot change work order from " + workOrder.Status + " to " + req.Status})
+	}
+
+	now := time.Now()
+	workOrder.Status = req.Status
+	if req.Status == models.WorkOrderInProgress && workOrder.StartedAt == nil {
+		workOrder.StartedAt = &now
+	}
+	if req.Notes != "" {
+		workOrder.CompletionNotes = req.Notes
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&workOrder).Error; err != nil {
+			return err
+		}
+		if workOrder.Status == models.WorkOrderCancelled {
+			return maintenance.RescheduleAfterClose(tx, &workOrder, now)
+		}
+		return nil
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to update work order status"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": workOrder})
+}
+
+// CompleteWorkOrder completes a work order, recording costs and notes. When the asset is in
+// maintenance and no other work order on it is in progress, its status is moved out of maintenance.
+// @Failure 409 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /work-orders/{id}/complete [post]
+func CompleteWorkOrder(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var req models.WorkOrderCompleteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": err.Error()})
+	}
+
+	var workOrder models.WorkOrder
+	if err := db.First(&workOrder, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Work order not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch work order"})
+	}
+
+	if !canWorkOnOrder(c, &workOrder) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": true, "message": "Insufficient permissions"})
+	}
+
+	if workOrder.IsClosed() {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Work order is already " + workOrder.Status})
+	}
+
+	now := time.Now()
+	workOrder.Status = models.WorkOrderCompleted
+	workOrder.CompletedAt = &now
+	if workOrder.StartedAt == nil {
+		workOrder.StartedAt = &now
+	}
+	workOrder.LabourHours = req.LabourHours
+	workOrder.LabourCost = req.LabourCost
+	workOrder.PartsCost = req.PartsCost
+	workOrder.CompletionNotes = req.CompletionNotes
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		workOrder.CompletedByID = &userID
+	}
+
+	assetStatus := req.AssetStatus
+	if assetStatus == "" {
+		assetStatus = "active"
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&workOrder).Error; err != nil {
+			return err
+		}
+		if err := maintenance.RescheduleAfterClose(tx, &workOrder, now); err != nil {
+			return err
+		}
+
+		var asset models.Asset
+		if err := tx.First(&asset, "id = ?", workOrder.AssetID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return err
+		}
+		if asset.Status != lifecycle.StatusMaintenance || assetStatus == lifecycle.StatusMaintenance {
+			return nil
+		}
+
+		var inProgress int64
+		if err := tx.Model(&models.WorkOrder{}).
+			Where("asset_id = ? AND id <> ? AND status = ?", asset.ID, workOrder.ID, models.WorkOrderInProgress).
+			Count(&inProgress).Error; err != nil {
+			return err
+		}
+		if inProgress > 0 {
+			return nil
+		}
+
+		before := asset
+		reason := "Work order completed: " + workOrder.Title
+		if _, err := lifecycle.Transition(tx, &asset, assetStatus, reason, workOrder.CompletedByID); err != nil {
+			return err
+		}
+		return recordAssetHistory(c, tx, before, asset)
+	})
+	if errors.Is(err, lifecycle.ErrConflict) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Asset was modified by another request; try again"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to complete work order"})
+	}
+
+	db.Preload("Asset").First(&workOrder, "id = ?", workOrder.ID)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": workOrder, "message": "Work order completed successfully"})
+}
+
+// canWorkOnOrder reports whether the current user may progress a work order:
+// managers and admins can progress any work order, users only those assigned to them
+func canWorkOnOrder(c *fiber.Ctx, workOrder *models.WorkOrder) bool {
+	role := middleware.GetCurrentUserRole(c)
+	if role == "admin" || role == "manager" {
+		return true
+	}
+
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return false
+	}
+	return workOrder.AssigneeID != nil && *workOrder.AssigneeID == userID
+}
+
+// isAllowedTransition reports whether a status transition appears in the given transition table
+func isAllowedTransition(transitions map[string][]string, from, to string) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
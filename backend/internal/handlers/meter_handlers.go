@@ -0,0 +1,612 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/filter"
+	"sams-backend/internal/lifecycle"
+	"sams-backend/internal/meters"
+	"sams-backend/internal/middleware"
+	"sams-backend/internal/models"
+)
+
+// findMeter loads the meter in the route. When it is not found the error response is written and
+// the returned meter is nil.
+func findMeter(c *fiber.Ctx) (*models.Meter, error) {
+	var meter models.Meter
+	if err := database.GetDB().First(&meter, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Meter not found"})
+		}
+		return nil, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch meter"})
+	}
+	return &meter, nil
+}
+
+// meterSaveError writes the response for a failed meter or meter rule insert or update
+func meterSaveError(c *fiber.Ctx, err error, message string) error {
+	var fiberErr *fiber.Error
+	var readingErr *meters.ReadingError
+	err = database.TranslateError(database.GetDB(), err)
+	switch {
+	case errors.As(err, &fiberErr):
+		return c.Status(fiberErr.Code).JSON(fiber.Map{"error": true, "message": fiberErr.Message})
+	case errors.As(err, &readingErr):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": true, "message": readingErr.Message})
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "The asset already has a meter with this name"})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": message})
+}
+
+// meterRequest parses and validates the request body. When it is invalid the error response is
+// written and the returned request is nil.
+func meterRequest(c *fiber.Ctx) (*models.MeterRequest, error) {
+	var req models.MeterRequest
+	if err := c.BodyParser(&req); err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	req.Unit = strings.TrimSpace(req.Unit)
+	if err := validate.Struct(req); err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": err.Error()})
+	}
+	if req.Kind == "" {
+		req.Kind = models.MeterCumulative
+	}
+	return &req, nil
+}
+
+// GetAssetMeters godoc
+// @Summary List asset meters
+// @Description Get the meters of an asset with their latest reading and rules
+// @Tags meters
+// @Produce  json
+// @Param id path string true "Asset ID"
+// @Success 200 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /assets/{id}/meters [get]
+func GetAssetMeters(c *fiber.Ctx) error {
+	var assetMeters []models.Meter
+	if err := database.GetDB().Preload("Rules").Where("asset_id = ?", c.Params("id")).Order("name").Find(&assetMeters).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch meters"})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    assetMeters,
+		"message": "Meters retrieved successfully",
+	})
+}
+
+// GetMeter godoc
+// @Summary Get a meter
+// @Description Get a meter with its latest reading and rules
+// @Tags meters
+// @Produce  json
+// @Param id path string true "Meter ID"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /meters/{id} [get]
+func GetMeter(c *fiber.Ctx) error {
+	var meter models.Meter
+	if err := database.GetDB().Preload("Asset").Preload("Rules").First(&meter, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Meter not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch meter"})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    meter,
+		"message": "Meter retrieved successfully",
+	})
+}
+
+// CreateMeter godoc
+// @Summary Add a meter to an asset
+// @Description Add a cumulative meter, such as run-hours or an odometer, or a gauge to an asset
+// @Tags meters
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Asset ID"
+// @Param request body models.MeterRequest true "Meter"
+// @Success 201 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /assets/{id}/meters [post]
+func CreateMeter(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var asset models.Asset
+	if err := db.Select("id", "status").First(&asset, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Asset not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch asset"})
+	}
+	if asset.Status == lifecycle.StatusDisposed {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Disposed assets cannot be metered"})
+	}
+
+	req, err := meterRequest(c)
+	if req == nil {
+		return err
+	}
+
+	meter := models.Meter{AssetID: asset.ID, Name: req.Name, Unit: req.Unit, Kind: req.Kind}
+	if err := db.Create(&meter).Error; err != nil {
+		return meterSaveError(c, err, "Failed to create meter")
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"error":   false,
+		"data":    meter,
+		"message": "Meter created successfully",
+	})
+}
+
+// UpdateMeter godoc
+// @Summary Replace a meter
+// @Description Rename a meter or change its unit. The kind of a meter cannot change once it has
+// @Description readings.
+// @Tags meters
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Meter ID"
+// @Param request body models.MeterRequest true "Meter"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /meters/{id} [put]
+func UpdateMeter(c *fiber.Ctx) error {
+	meter, err := findMeter(c)
+	if meter == nil {
+		return err
+	}
+	req, err := meterRequest(c)
+	if req == nil {
+		return err
+	}
+
+	if req.Kind != meter.Kind && meter.LastReadAt != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "The kind of a meter with readings cannot change"})
+	}
+	if req.Kind != models.MeterCumulative {
+		var rules int64
+		if err := database.GetDB().Model(&models.MeterRule{}).Where("meter_id = ?", meter.ID).Count(&rules).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to update meter"})
+		}
+		if rules > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Only cumulative meters can have rules"})
+		}
+	}
+
+	meter.Name = req.Name
+	meter.Unit = req.Unit
+	meter.Kind = req.Kind
+	if err := database.GetDB().Model(meter).Select("name", "unit", "kind").Updates(meter).Error; err != nil {
+		return meterSaveError(c, err, "Failed to update meter")
+	}
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    meter,
+		"message": "Meter updated successfully",
+	})
+}
+
+// DeleteMeter godoc
+// @Summary Delete a meter
+// @Description Delete a meter along with its rules. Its readings and events are kept.
+// @Tags meters
+// @Produce  json
+// @Param id path string true "Meter ID"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /meters/{id} [delete]
+func DeleteMeter(c *fiber.Ctx) error {
+	meter, err := findMeter(c)
+	if meter == nil {
+		return err
+	}
+
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("meter_id = ?", meter.ID).Delete(&models.MeterRule{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(meter).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to delete meter"})
+	}
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"message": "Meter deleted successfully",
+	})
+}
+
+// GetMeterReadings godoc
+// @Summary List meter readings
+// @Description Get the readings of a meter, latest first
+// @Tags meters
+// @Produce  json
+// @Param id path string true "Meter ID"
+// @Param since query string false "Only readings taken at or after this time (RFC 3339)"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Router /meters/{id}/readings [get]
+func GetMeterReadings(c *fiber.Ctx) error {
+	query := database.GetDB().Model(&models.MeterReading{}).Where("meter_id = ?", c.Params("id"))
+	if since := c.Query("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "since must be an RFC 3339 time"})
+		}
+		query = query.Where("read_at >= ?", t)
+	}
+
+	var readings []models.MeterReading
+	order := []filter.SortKey{{Column: "read_at", Desc: true}}
+	pagination, err := listPage(c, query, order, 50, &readings, "RecordedBy")
+	if err != nil {
+		return pageError(c, err, "Failed to fetch meter readings")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       readings,
+		"message":    "Meter readings retrieved successfully",
+		"pagination": pagination,
+	})
+}
+
+// CreateMeterReading godoc
+// @Summary Submit a meter reading
+// @Description Record a reading of a meter, taken now or earlier. A reading of a cumulative meter
+// @Description cannot be below an earlier reading or above a later one. The latest reading of a
+// @Description cumulative meter raises a maintenance-due event for every rule whose due value it
+// @Description reaches; the events are returned with the reading.
+// @Tags meters
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Meter ID"
+// @Param request body models.MeterReadingRequest true "Reading"
+// @Success 201 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Failure 422 {object} fiber.Map
+// @Router /meters/{id}/readings [post]
+func CreateMeterReading(c *fiber.Ctx) error {
+	var req models.MeterReadingRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+	if err := validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": err.Error()})
+	}
+
+	meter, err := findMeter(c)
+	if meter == nil {
+		return err
+	}
+
+	db := database.GetDB()
+	var asset models.Asset
+	if err := db.Select("id", "status").First(&asset, "id = ?", meter.AssetID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Asset not found"})
+	}
+	if asset.Status == lifecycle.StatusDisposed {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Disposed assets cannot be metered"})
+	}
+
+	now := time.Now()
+	reading := models.MeterReading{Value: req.Value, ReadAt: now, Notes: req.Notes}
+	if req.ReadAt != nil {
+		if req.ReadAt.After(now.Add(time.Minute)) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "read_at cannot be in the future"})
+		}
+		reading.ReadAt = *req.ReadAt
+	}
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		reading.RecordedByID = &userID
+	}
+
+	var events []models.MaintenanceDueEvent
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		events, err = meters.Record(tx, meter, &reading)
+		return err
+	})
+	if err != nil {
+		return meterSaveError(c, err, "Failed to record meter reading")
+	}
+
+	if events == nil {
+		events = []models.MaintenanceDueEvent{}
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"error": false,
+		"data": fiber.Map{
+			"reading": reading,
+			"events":  events,
+		},
+		"message": "Meter reading recorded successfully",
+	})
+}
+
+// saveMeterRule validates the request body onto a rule of the meter and inserts or updates it.
+// The rule's next due value is worked out again from the meter's latest reading.
+func saveMeterRule(c *fiber.Ctx, meter *models.Meter, rule *models.MeterRule, message string, status int) error {
+	var req models.MeterRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if err := validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": err.Error()})
+	}
+	if meter.Kind != models.MeterCumulative {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Only cumulative meters can have rules"})
+	}
+
+	db := database.GetDB()
+	if req.DefaultAssigneeID != nil {
+		var assignee models.User
+		if err := db.Select("id").First(&assignee, "id = ?", *req.DefaultAssigneeID).Error; err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Default assignee not found"})
+		}
+	}
+
+	rule.MeterID = meter.ID
+	rule.AssetID = meter.AssetID
+	rule.Name = req.Name
+	rule.Description = req.Description
+	rule.Interval = meters.Round(req.Interval)
+	rule.LeadValue = meters.Round(req.LeadValue)
+	rule.CreateWorkOrder = req.CreateWorkOrder
+	rule.DefaultAssigneeID = req.DefaultAssigneeID
+	rule.IsActive = req.IsActive == nil || *req.IsActive
+
+	var latest float64
+	if meter.LastValue != nil {
+		latest = *meter.LastValue
+	}
+	switch {
+	case req.StartValue != nil:
+		rule.StartValue = meters.Round(*req.StartValue)
+	case rule.ID == uuid.Nil:
+		rule.StartValue = latest
+	}
+	rule.NextDueValue = meters.NextDue(rule, latest)
+	if rule.ID == uuid.Nil {
+		rule.ID = uuid.New()
+	} else {
+		var raised *float64
+		if err := db.Model(&models.MaintenanceDueEvent{}).Where("rule_id = ?", rule.ID).
+			Select("MAX(due_value)").Scan(&raised).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to save meter rule"})
+		}
+		if raised != nil && rule.NextDueValue <= *raised {
+			rule.NextDueValue = meters.NextDueAfter(rule, *raised)
+		}
+	}
+
+	if err := db.Omit("DefaultAssignee").Save(rule).Error; err != nil {
+		return meterSaveError(c, err, "Failed to save meter rule")
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"error":   false,
+		"data":    rule,
+		"message": message,
+	})
+}
+
+// CreateMeterRule godoc
+// @Summary Add a meter rule
+// @Description Make maintenance due every interval of a cumulative meter, such as every 500
+// @Description hours. The rule counts from start_value, which defaults to the latest reading,
+// @Description and raises its event lead_value units early.
+// @Tags meters
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Meter ID"
+// @Param request body models.MeterRuleRequest true "Rule"
+// @Success 201 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Router /meters/{id}/rules [post]
+func CreateMeterRule(c *fiber.Ctx) error {
+	meter, err := findMeter(c)
+	if meter == nil {
+		return err
+	}
+	return saveMeterRule(c, meter, &models.MeterRule{}, "Meter rule created successfully", fiber.StatusCreated)
+}
+
+// findMeterRule loads a rule of the meter in the route. When either is not found the error
+// response is written and the returned rule is nil.
+func findMeterRule(c *fiber.Ctx) (*models.Meter, *models.MeterRule, error) {
+	meter, err := findMeter(c)
+	if meter == nil {
+		return nil, nil, err
+	}
+	var rule models.MeterRule
+	if err := database.GetDB().First(&rule, "id = ? AND meter_id = ?", c.Params("ruleId"), meter.ID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Meter rule not found"})
+		}
+		return nil, nil, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch meter rule"})
+	}
+	return meter, &rule, nil
+}
+
+// UpdateMeterRule godoc
+// @Summary Replace a meter rule
+// @Description Replace a meter rule. Its next due value is worked out again from the latest reading.
+// @Tags meters
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Meter ID"
+// @Param ruleId path string true "Rule ID"
+// @Param request body models.MeterRuleRequest true "Rule"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /meters/{id}/rules/{ruleId} [put]
+func UpdateMeterRule(c *fiber.Ctx) error {
+	meter, rule, err := findMeterRule(c)
+	if rule == nil {
+		return err
+	}
+	return saveMeterRule(c, meter, rule, "Meter rule updated successfully", fiber.StatusOK)
+}
+
+// DeleteMeterRule godoc
+// @Summary Delete a meter rule
+// @Tags meters
+// @Produce  json
+// @Param id path string true "Meter ID"
+// @Param ruleId path string true "Rule ID"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /meters/{id}/rules/{ruleId} [delete]
+func DeleteMeterRule(c *fiber.Ctx) error {
+	_, rule, err := findMeterRule(c)
+	if rule == nil {
+		return err
+	}
+	if err := database.GetDB().Delete(rule).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to delete meter rule"})
+	}
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"message": "Meter rule deleted successfully",
+	})
+}
+
+// GetAssetUsage godoc
+// @Summary Get asset usage statistics
+// @Description Get the readings of each meter of an asset over the last days: their count and
+// @Description range, and for cumulative meters the usage, the average usage per day and when
+// @Description each rule is expected to fall due at that rate
+// @Tags meters
+// @Produce  json
+// @Param id path string true "Asset ID"
+// @Param days query int false "Period in days, 1 to 3650 (default 90)"
+// @Success 200 {array} meters.Usage
+// @Failure 400 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /assets/{id}/usage [get]
+func GetAssetUsage(c *fiber.Ctx) error {
+	days := c.QueryInt("days", 90)
+	if days < 1 || days > 3650 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "days must be between 1 and 3650"})
+	}
+
+	db := database.GetDB()
+	var asset models.Asset
+	if err := db.Select("id").First(&asset, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Asset not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch asset"})
+	}
+
+	var assetMeters []models.Meter
+	if err := db.Where("asset_id = ?", asset.ID).Order("name").Find(&assetMeters).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch meters"})
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	results := make([]meters.Usage, 0, len(assetMeters))
+	for i := range assetMeters {
+		usage, err := meters.MeterUsage(db, &assetMeters[i], since)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to get usage statistics"})
+		}
+		results = append(results, *usage)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": results})
+}
+
+// GetMaintenanceDueEvents godoc
+// @Summary List maintenance-due events
+// @Description Get the events raised by meter rules, newest first
+// @Tags meters
+// @Produce  json
+// @Param status query string false "open or acknowledged"
+// @Param asset_id query string false "Asset ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /maintenance-due-events [get]
+func GetMaintenanceDueEvents(c *fiber.Ctx) error {
+	query := database.GetDB().Model(&models.MaintenanceDueEvent{})
+	if status := c.Query("status"); status != "" && status != "all" {
+		query = query.Where("status = ?", status)
+	}
+	if assetID := c.Query("asset_id"); assetID != "" {
+		query = query.Where("asset_id = ?", assetID)
+	}
+
+	var events []models.MaintenanceDueEvent
+	order := []filter.SortKey{{Column: "created_at", Desc: true}}
+	pagination, err := listPage(c, query, order, 20, &events, "Asset", "Meter", "Rule", "WorkOrder", "AcknowledgedBy")
+	if err != nil {
+		return pageError(c, err, "Failed to fetch maintenance-due events")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       events,
+		"message":    "Maintenance-due events retrieved successfully",
+		"pagination": pagination,
+	})
+}
+
+// AcknowledgeMaintenanceDueEvent godoc
+// @Summary Acknowledge a maintenance-due event
+// @Tags meters
+// @Produce  json
+// @Param id path string true "Event ID"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /maintenance-due-events/{id}/acknowledge [post]
+func AcknowledgeMaintenanceDueEvent(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var event models.MaintenanceDueEvent
+	if err := db.First(&event, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Maintenance-due event not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch maintenance-due event"})
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"status": models.MaintenanceDueAcknowledged, "acknowledged_at": now}
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		updates["acknowledged_by_id"] = userID
+	}
+	result := db.Model(&event).Where("status = ?", models.MaintenanceDueOpen).Updates(updates)
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to acknowledge maintenance-due event"})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Only open events can be acknowledged"})
+	}
+
+	db.Preload("Meter").Preload("Rule").Preload("WorkOrder").Preload("AcknowledgedBy").First(&event, "id = ?", event.ID)
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    event,
+		"message": "Maintenance-due event acknowledged successfully",
+	})
+}
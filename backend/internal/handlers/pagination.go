@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"sams-backend/internal/filter"
+)
+
+// maxPageLimit bounds the limit parameter of every list endpoint
+const maxPageLimit = 100
+
+// pageCursor is the decoded form of an after/before cursor: the sort order it was issued for and
+// the sort values of the row it points at
+type pageCursor struct {
+	Order  string            `json:"o"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// pageRequested reports whether a request asks for a page by number, size or cursor. Short
+// reference lists that callers load in full are only paginated when it does.
+func pageRequested(c *fiber.Ctx) bool {
+	return c.Query("page") != "" || c.Query("limit") != "" || c.Query("after") != "" || c.Query("before") != ""
+}
+
+// listPage loads one page of query results into dest, a pointer to a slice of models, and returns
+// the pagination block of the response. The primary key is appended to order so it is stable.
+//
+// Pages are numbered (page, limit) by default, with a total count unless count=false. Passing the
+// next_cursor or prev_cursor of a previous response as after or before switches to keyset
+// pagination, which stays fast however deep the page. Errors of type *fiber.Error are caused by
+// invalid parameters.
+func listPage(c *fiber.Ctx, query *gorm.DB, order []filter.SortKey, defaultLimit int, dest interface{}, preloads ...string) (fiber.Map, error) {
+	limit, _ := strconv.Atoi(c.Query("limit", strconv.Itoa(defaultLimit)))
+	if limit < 1 || limit > maxPageLimit {
+		limit = defaultLimit
+	}
+
+	after, before := c.Query("after"), c.Query("before")
+	if after != "" && before != "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Use either after or before, not both")
+	}
+
+	stmt := &gorm.Statement{DB: query}
+	if err := stmt.Parse(dest); err != nil {
+		return nil, err
+	}
+
+	keys := append(append([]filter.SortKey{}, order...), filter.SortKey{Column: "id"})
+	fields := make([]*schema.Field, len(keys))
+	// Cursors hold column values, so orders on expressions are paged by number only
+	cursors := true
+	for i, key := range keys {
+		if key.Expr != nil {
+			cursors = false
+			continue
+		}
+		if fields[i] = stmt.Schema.LookUpField(key.Column); fields[i] == nil {
+			return nil, fmt.Errorf("cannot paginate on %s", key.Column)
+		}
+	}
+	signature := orderClause(keys)
+	if !cursors && (after != "" || before != "") {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Cursors are not available for this sort order; use page instead")
+	}
+
+	pagination := fiber.Map{"limit": limit}
+	if c.QueryBool("count", true) {
+		var total int64
+		if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, err
+		}
+		pagination["total"] = total
+		pagination["total_pages"] = int((total + int64(limit) - 1) / int64(limit))
+	}
+
+	page := query.Session(&gorm.Session{})
+	for _, preload := range preloads {
+		page = page.Preload(preload)
+	}
+
+	// With before the order is reversed to read the rows preceding the cursor, and the rows are
+	// flipped back afterwards
+	backwards := before != ""
+	hasPrevious := after != ""
+	if raw := after + before; raw != "" {
+		var cursor pageCursor
+		decoded, err := base64.RawURLEncoding.DecodeString(raw)
+		if err == nil {
+			err = json.Unmarshal(decoded, &cursor)
+		}
+		if err != nil || cursor.Order != signature || len(cursor.Values) != len(keys) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid cursor, or the cursor was issued for a different sort order")
+		}
+
+		values := make([]interface{}, len(keys))
+		for i, raw := range cursor.Values {
+			if string(raw) == "null" {
+				continue
+			}
+			value := reflect.New(fields[i].FieldType)
+			if err := json.Unmarshal(raw, value.Interface()); err != nil {
+				return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid cursor")
+			}
+			values[i] = value.Elem().Interface()
+		}
+
+		if backwards {
+			reversed := make([]filter.SortKey, len(keys))
+			for i, key := range keys {
+				reversed[i] = filter.SortKey{Column: key.Column, Desc: !key.Desc}
+			}
+			keys = reversed
+		}
+		condition, vars := keysetCondition(keys, values)
+		page = page.Where(condition, vars...)
+	} else {
+		pageNumber, _ := strconv.Atoi(c.Query("page", "1"))
+		if pageNumber < 1 {
+			pageNumber = 1
+		}
+		pagination["page"] = pageNumber
+		page = page.Offset((pageNumber - 1) * limit)
+		hasPrevious = pageNumber > 1
+	}
+
+	// One extra row tells whether there is another page
+	if err := page.Clauses(orderBy(keys)).Limit(limit + 1).Find(dest).Error; err != nil {
+		return nil, err
+	}
+
+	rows := reflect.ValueOf(dest).Elem()
+	hasMore := rows.Len() > limit
+	if hasMore {
+		rows.Set(rows.Slice(0, limit))
+	}
+	hasNext := hasMore
+	if backwards {
+		swap := reflect.Swapper(rows.Interface())
+		for i, j := 0, rows.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+		hasPrevious, hasNext = hasMore, true
+	}
+
+	pagination["has_more"] = hasNext
+	if rows.Len() > 0 && cursors {
+		if hasNext {
+			pagination["next_cursor"] = encodeCursor(c, signature, fields, rows.Index(rows.Len()-1))
+		}
+		if hasPrevious {
+			pagination["prev_cursor"] = encodeCursor(c, signature, fields, rows.Index(0))
+		}
+	}
+
+	return pagination, nil
+}
+
+// encodeCursor returns the opaque cursor pointing at a row
+func encodeCursor(c *fiber.Ctx, signature string, fields []*schema.Field, row reflect.Value) string {
+	cursor := pageCursor{Order: signature, Values: make([]json.RawMessage, len(fields))}
+	for i, field := range fields {
+		value, _ := field.ValueOf(c.Context(), row)
+		cursor.Values[i], _ = json.Marshal(value)
+	}
+	encoded, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// keysetCondition returns the condition selecting the rows that sort after the given key values.
+// NULLs follow the PostgreSQL default: last in ascending order and first in descending order.
+func keysetCondition(keys []filter.SortKey, values []interface{}) (string, []interface{}) {
+	var alternatives []string
+	var vars []interface{}
+
+	for i, key := range keys {
+		var parts []string
+		var partVars []interface{}
+
+		// All preceding keys are equal to the cursor row
+		for j := 0; j < i; j++ {
+			if values[j] == nil {
+				parts = append(parts, keys[j].Column+" IS NULL")
+			} else {
+				parts = append(parts, keys[j].Column+" = ?")
+				partVars = append(partVars, values[j])
+			}
+		}
+
+		// and this key sorts after it
+		nullsFirst := key.Desc
+		comparison := " > ?"
+		if key.Desc {
+			comparison = " < ?"
+		}
+		switch {
+		case values[i] == nil && nullsFirst:
+			parts = append(parts, key.Column+" IS NOT NULL")
+		case values[i] == nil:
+			// Nothing sorts after NULL when NULLs come last
+			continue
+		case nullsFirst:
+			parts = append(parts, key.Column+comparison)
+			partVars = append(partVars, values[i])
+		default:
+			parts = append(parts, "("+key.Column+comparison+" OR "+key.Column+" IS NULL)")
+			partVars = append(partVars, values[i])
+		}
+
+		alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
+		vars = append(vars, partVars...)
+	}
+
+	if len(alternatives) == 0 {
+		return "1 = 0", nil
+	}
+	return strings.Join(alternatives, " OR "), vars
+}
+
+// orderClause renders sort keys as an ORDER BY clause; it also serves as the signature of the
+// order in cursors
+func orderClause(keys []filter.SortKey) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key.Column + " ASC"
+		if key.Desc {
+			parts[i] = key.Column + " DESC"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// orderBy returns the ORDER BY clause of the sort keys, including keys on expressions
+func orderBy(keys []filter.SortKey) clause.OrderBy {
+	parts := make([]string, len(keys))
+	var vars []interface{}
+	for i, key := range keys {
+		parts[i] = key.Column
+		if key.Expr != nil {
+			parts[i] = "?"
+			vars = append(vars, key.Expr)
+		}
+		if key.Desc {
+			parts[i] += " DESC"
+		} else {
+			parts[i] += " ASC"
+		}
+	}
+	return clause.OrderBy{Expression: clause.Expr{SQL: strings.Join(parts, ", "), Vars: vars}}
+}
+
+// pageError writes the response for an error returned by listPage
+func pageError(c *fiber.Ctx, err error, message string) error {
+	if fiberErr, ok := err.(*fiber.Error); ok {
+		return c.Status(fiberErr.Code).JSON(fiber.Map{
+			"error":   true,
+			"message": fiberErr.Message,
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error":   true,
+		"message": message,
+	})
+}
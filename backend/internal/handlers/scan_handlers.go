@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/filter"
+	"sams-backend/internal/lifecycle"
+	"sams-backend/internal/middleware"
+	"sams-backend/internal/models"
+)
+
+// scanUUID matches an asset ID anywhere in a scanned payload, such as at the end of a QR deep link
+var scanUUID = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+
+// resolveScan finds the asset a scanned payload identifies. QR codes carry a link containing the
+// asset ID; barcodes carry a serial or tag number, which Code 39 upper-cases, so numbers are
+// matched exactly first and then ignoring case.
+func resolveScan(db *gorm.DB, payload string) (*models.Asset, error) {
+	if matches := scanUUID.FindAllString(payload, -1); len(matches) > 0 {
+		var asset models.Asset
+		err := db.First(&asset, "id = ?", strings.ToLower(matches[len(matches)-1])).Error
+		if err == nil {
+			return &asset, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	for _, condition := range []string{
+		"serial_number = @code OR asset_tag = @code",
+		"UPPER(serial_number) = UPPER(@code) OR UPPER(asset_tag) = UPPER(@code)",
+	} {
+		var assets []models.Asset
+		if err := db.Where(condition, map[string]interface{}{"code": payload}).Limit(2).Find(&assets).Error; err != nil {
+			return nil, err
+		}
+		switch len(assets) {
+		case 1:
+			return &assets[0], nil
+		case 2:
+			return nil, fiber.NewError(fiber.StatusConflict, "Scanned code matches more than one asset")
+		}
+	}
+	return nil, fiber.NewError(fiber.StatusNotFound, "No asset matches the scanned code")
+}
+
+// scanTime checks the observations of a scan and returns when it was made: now, or the time of
+// a scan made offline
+func scanTime(req *models.ScanRequest) (time.Time, error) {
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return time.Time{}, errors.New("latitude and longitude must be given together")
+	}
+
+	now := time.Now()
+	if req.ScannedAt == nil {
+		return now, nil
+	}
+	if req.ScannedAt.After(now.Add(time.Minute)) {
+		return time.Time{}, errors.New("scanned_at cannot be in the future")
+	}
+	return *req.ScannedAt, nil
+}
+
+// recordSighting stores a sighting of an asset and updates the asset's last seen details, along
+// with the coordinates and condition observed. Disposed assets are locked, and a sighting made
+// offline that is older than the last one only adds to the record.
+func recordSighting(c *fiber.Ctx, tx *gorm.DB, asset *models.Asset, sighting *models.AssetSighting, source string) error {
+	if err := tx.Create(sighting).Error; err != nil {
+		return err
+	}
+	if asset.Status == lifecycle.StatusDisposed || (asset.LastSeenAt != nil && sighting.ScannedAt.Before(*asset.LastSeenAt)) {
+		return nil
+	}
+
+	before := *asset
+	scannedAt := sighting.ScannedAt
+	asset.LastSeenAt = &scannedAt
+	asset.LastSeenByID = sighting.ScannedByID
+	if sighting.Latitude != nil {
+		asset.Latitude = sighting.Latitude
+		asset.Longitude = sighting.Longitude
+	}
+	if sighting.Condition != "" {
+		asset.Condition = sighting.Condition
+	}
+	if err := saveAssetVersion(tx, asset); err != nil {
+		return err
+	}
+	if sighting.Latitude != nil {
+		if err := checkGeofence(c, tx, asset, source); err != nil {
+			return err
+		}
+	}
+	return recordAssetHistory(c, tx, before, *asset)
+}
+
+// CreateScan godoc
+// @Summary Record a scan
+// @Description Resolve a scanned QR deep link, barcode or serial number to an asset and record a
+// @Description sighting by the current user. The most recent sighting sets the asset's last seen
+// @Description date, and its coordinates and condition, when given, update the asset.
+// @Tags scans
+// @Accept  json
+// @Produce  json
+// @Param request body models.ScanRequest true "Scanned code and observations"
+// @Success 201 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /scans [post]
+func CreateScan(c *fiber.Ctx) error {
+	db := database.GetDB()
+
+	var req models.ScanRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+	req.Payload = strings.TrimSpace(req.Payload)
+	if err := validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	scannedAt, err := scanTime(&req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	asset, err := resolveScan(db, req.Payload)
+	if err != nil {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			return c.Status(fiberErr.Code).JSON(fiber.Map{
+				"error":   true,
+				"message": fiberErr.Message,
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to resolve scanned code",
+		})
+	}
+
+	sighting := models.AssetSighting{
+		AssetID:   asset.ID,
+		Payload:   req.Payload,
+		ScannedAt: scannedAt,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Condition: req.Condition,
+		Notes:     req.Notes,
+	}
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		sighting.ScannedByID = &userID
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return recordSighting(c, tx, asset, &sighting, models.LocationSourceScan)
+	})
+	if err != nil {
+		if errors.Is(err, errAssetVersionConflict) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":   true,
+				"message": "Asset was modified while the scan was recorded; scan again",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to record scan",
+		})
+	}
+
+	db.Preload("Category").Preload("Department").Preload("LastSeenBy").First(asset, "id = ?", asset.ID)
+	sighting.Asset = asset
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"error":   false,
+		"data":    sighting,
+		"message": "Scan recorded successfully",
+	})
+}
+
+// GetAssetSightings godoc
+// @Summary Get asset sightings
+// @Description Get the scans of an asset, most recent first
+// @Tags scans
+// @Produce  json
+// @Param id path string true "Asset ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Param after query string false "Cursor from next_cursor for the following page"
+// @Param before query string false "Cursor from prev_cursor for the previous page"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /assets/{id}/sightings [get]
+func GetAssetSightings(c *fiber.Ctx) error {
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid asset ID",
+		})
+	}
+
+	query := database.GetDB().Model(&models.AssetSighting{}).Where("asset_id = ?", assetID)
+	var sightings []models.AssetSighting
+	order := []filter.SortKey{{Column: "scanned_at", Desc: true}}
+	pagination, err := listPage(c, query, order, 20, &sightings, "ScannedBy")
+	if err != nil {
+		return pageError(c, err, "Failed to fetch sightings")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       sightings,
+		"message":    "Sightings retrieved successfully",
+		"pagination": pagination,
+	})
+}
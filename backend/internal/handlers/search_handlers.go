@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/middleware"
+	"sams-backend/internal/search"
+)
+
+// maxSearchLimit bounds the number of results returned per type
+const maxSearchLimit = 50
+
+// Search godoc
+// @Summary Search across records
+// @Description Search assets, categories, departments and users together. Results are grouped by
+// @Description type and ranked by relevance, with matched words wrapped in <mark> in the snippet.
+// @Description Users are only searched for admins.
+// @Tags search
+// @Produce  json
+// @Param q query string true "Search term"
+// @Param types query string false "Types to search, comma-separated: assets, categories, departments, users"
+// @Param limit query int false "Results per type (default 5)"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /search [get]
+func Search(c *fiber.Ctx) error {
+	query := search.Parse(c.Query("q"))
+	if query.Empty() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Search term q is required",
+		})
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "5"))
+	if limit < 1 || limit > maxSearchLimit {
+		limit = 5
+	}
+
+	isAdmin := middleware.GetCurrentUserRole(c) == "admin"
+	sources := []*search.Source{}
+	if names := queryValues(c, "types"); len(names) > 0 {
+		for _, name := range names {
+			source, ok := search.Lookup(name)
+			if !ok {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error":   true,
+					"message": "Unknown search type " + name,
+				})
+			}
+			if source == search.Users && !isAdmin {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error":   true,
+					"message": "Insufficient permissions",
+				})
+			}
+			sources = append(sources, source)
+		}
+	} else {
+		for _, source := range search.Sources {
+			if source != search.Users || isAdmin {
+				sources = append(sources, source)
+			}
+		}
+	}
+
+	db := database.GetDB()
+	results := fiber.Map{}
+	for _, source := range sources {
+		hits, err := source.Search(db, query, limit)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   true,
+				"message": "Failed to search " + source.Type,
+			})
+		}
+		results[source.Type] = hits
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    results,
+		"message": "Search completed successfully",
+	})
+}
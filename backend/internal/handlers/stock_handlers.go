@@ -0,0 +1,640 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/filter"
+	"sams-backend/internal/lifecycle"
+	"sams-backend/internal/middleware"
+	"sams-backend/internal/models"
+	"sams-backend/internal/stock"
+)
+
+// stockSaveError writes the response for a failed insert or update of a part or stock location
+func stockSaveError(c *fiber.Ctx, err error, what string) error {
+	var fiberErr *fiber.Error
+	err = database.TranslateError(database.GetDB(), err)
+	switch {
+	case errors.As(err, &fiberErr):
+		return c.Status(fiberErr.Code).JSON(fiber.Map{"error": true, "message": fiberErr.Message})
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": what + " already exists"})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to save " + strings.ToLower(what)})
+}
+
+// savePart validates the request body onto a part and inserts or updates it
+func savePart(c *fiber.Ctx, part *models.Part, message string, status int) error {
+	var req models.PartRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+	req.PartNumber = strings.TrimSpace(req.PartNumber)
+	req.Name = strings.TrimSpace(req.Name)
+	if err := validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": err.Error()})
+	}
+
+	db := database.GetDB()
+	if req.SupplierID != nil {
+		var vendor models.Vendor
+		if err := db.First(&vendor, "id = ?", *req.SupplierID).Error; err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Supplier not found"})
+		}
+		if !vendor.IsSupplier {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Vendor " + vendor.Name + " is not a supplier"})
+		}
+	}
+
+	part.PartNumber = req.PartNumber
+	part.Name = req.Name
+	part.Description = req.Description
+	part.Unit = req.Unit
+	if part.Unit == "" {
+		part.Unit = "each"
+	}
+	part.SupplierID = req.SupplierID
+	// The average cost of stock on hand moves with receipts; the request only sets it while the
+	// part has never been received
+	var received int64
+	if err := db.Model(&models.StockMovement{}).Where("part_id = ? AND kind = ?", part.ID, models.StockReceive).Count(&received).Error; err != nil {
+		return stockSaveError(c, err, "Part")
+	}
+	if received == 0 {
+		part.UnitCost = req.UnitCost
+	}
+
+	if err := db.Omit("Supplier", "Levels").Save(part).Error; err != nil {
+		return stockSaveError(c, err, "Part")
+	}
+
+	db.Preload("Supplier").Preload("Levels.Location").First(part, "id = ?", part.ID)
+	return c.Status(status).JSON(fiber.Map{
+		"error":   false,
+		"data":    part,
+		"message": message,
+	})
+}
+
+// GetParts godoc
+// @Summary List parts
+// @Description Get spare parts and consumables with their stock levels, ordered by part number
+// @Tags stock
+// @Produce  json
+// @Param search query string false "Part number or name contains"
+// @Param supplier_id query string false "Supplier vendor ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /parts [get]
+func GetParts(c *fiber.Ctx) error {
+	query := database.GetDB().Model(&models.Part{})
+	if term := strings.TrimSpace(c.Query("search")); term != "" {
+		query = query.Where("part_number ILIKE ? OR name ILIKE ?", "%"+term+"%", "%"+term+"%")
+	}
+	if supplierID := c.Query("supplier_id"); supplierID != "" {
+		id, err := uuid.Parse(supplierID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid supplier ID"})
+		}
+		query = query.Where("supplier_id = ?", id)
+	}
+
+	var parts []models.Part
+	order := []filter.SortKey{{Column: "part_number"}}
+	pagination, err := listPage(c, query, order, 20, &parts, "Supplier", "Levels.Location")
+	if err != nil {
+		return pageError(c, err, "Failed to fetch parts")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       parts,
+		"message":    "Parts retrieved successfully",
+		"pagination": pagination,
+	})
+}
+
+// GetPart godoc
+// @Summary Get a part
+// @Description Get a part with its stock level at each location
+// @Tags stock
+// @Produce  json
+// @Param id path string true "Part ID"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /parts/{id} [get]
+func GetPart(c *fiber.Ctx) error {
+	var part models.Part
+	if err := database.GetDB().Preload("Supplier").Preload("Levels.Location").First(&part, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Part not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch part"})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    part,
+		"message": "Part retrieved successfully",
+	})
+}
+
+// CreatePart godoc
+// @Summary Create a part
+// @Tags stock
+// @Accept  json
+// @Produce  json
+// @Param request body models.PartRequest true "Part"
+// @Success 201 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /parts [post]
+func CreatePart(c *fiber.Ctx) error {
+	part := models.Part{ID: uuid.New()}
+	return savePart(c, &part, "Part created successfully", fiber.StatusCreated)
+}
+
+// UpdatePart godoc
+// @Summary Replace a part
+// @Description Replace a part's details. The unit cost can only be set until stock of the part
+// @Description is first received; from then on it is the moving average of the receipts.
+// @Tags stock
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Part ID"
+// @Param request body models.PartRequest true "Part"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /parts/{id} [put]
+func UpdatePart(c *fiber.Ctx) error {
+	var part models.Part
+	if err := database.GetDB().First(&part, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Part not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch part"})
+	}
+	return savePart(c, &part, "Part updated successfully", fiber.StatusOK)
+}
+
+// DeletePart godoc
+// @Summary Delete a part
+// @Description Delete a part that has no stock on hand
+// @Tags stock
+// @Produce  json
+// @Param id path string true "Part ID"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /parts/{id} [delete]
+func DeletePart(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var part models.Part
+	if err := db.First(&part, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Part not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch part"})
+	}
+
+	var stocked int64
+	if err := db.Model(&models.StockLevel{}).Where("part_id = ? AND quantity_on_hand > 0", part.ID).Count(&stocked).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to check stock on hand"})
+	}
+	if stocked > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Part still has stock on hand"})
+	}
+
+	if err := db.Delete(&part).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to delete part"})
+	}
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"message": "Part deleted successfully",
+	})
+}
+
+// saveStockLocation validates the request body onto a stock location and inserts or updates it
+func saveStockLocation(c *fiber.Ctx, location *models.StockLocation, message string, status int) error {
+	var req models.StockLocationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if err := validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": err.Error()})
+	}
+
+	location.Name = req.Name
+	location.BuildingRoom = req.BuildingRoom
+	location.Description = req.Description
+	if err := database.GetDB().Save(location).Error; err != nil {
+		return stockSaveError(c, err, "Stock location")
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"error":   false,
+		"data":    location,
+		"message": message,
+	})
+}
+
+// GetStockLocations godoc
+// @Summary List stock locations
+// @Tags stock
+// @Produce  json
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /stock-locations [get]
+func GetStockLocations(c *fiber.Ctx) error {
+	var locations []models.StockLocation
+	order := []filter.SortKey{{Column: "name"}}
+	pagination, err := listPage(c, database.GetDB().Model(&models.StockLocation{}), order, 50, &locations)
+	if err != nil {
+		return pageError(c, err, "Failed to fetch stock locations")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       locations,
+		"message":    "Stock locations retrieved successfully",
+		"pagination": pagination,
+	})
+}
+
+// CreateStockLocation godoc
+// @Summary Create a stock location
+// @Tags stock
+// @Accept  json
+// @Produce  json
+// @Param request body models.StockLocationRequest true "Stock location"
+// @Success 201 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /stock-locations [post]
+func CreateStockLocation(c *fiber.Ctx) error {
+	location := models.StockLocation{ID: uuid.New()}
+	return saveStockLocation(c, &location, "Stock location created successfully", fiber.StatusCreated)
+}
+
+// UpdateStockLocation godoc
+// @Summary Replace a stock location
+// @Tags stock
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Stock location ID"
+// @Param request body models.StockLocationRequest true "Stock location"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /stock-locations/{id} [put]
+func UpdateStockLocation(c *fiber.Ctx) error {
+	var location models.StockLocation
+	if err := database.GetDB().First(&location, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Stock location not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch stock location"})
+	}
+	return saveStockLocation(c, &location, "Stock location updated successfully", fiber.StatusOK)
+}
+
+// DeleteStockLocation godoc
+// @Summary Delete a stock location
+// @Description Delete a stock location that holds no stock
+// @Tags stock
+// @Produce  json
+// @Param id path string true "Stock location ID"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /stock-locations/{id} [delete]
+func DeleteStockLocation(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var location models.StockLocation
+	if err := db.First(&location, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Stock location not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch stock location"})
+	}
+
+	var stocked int64
+	if err := db.Model(&models.StockLevel{}).Where("location_id = ? AND quantity_on_hand > 0", location.ID).Count(&stocked).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to check stock on hand"})
+	}
+	if stocked > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Stock location still holds stock"})
+	}
+
+	if err := db.Delete(&location).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to delete stock location"})
+	}
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"message": "Stock location deleted successfully",
+	})
+}
+
+// UpdateStockRule godoc
+// @Summary Set a reorder rule
+// @Description Set the reorder point and reorder quantity of a part at a stock location. A
+// @Description reorder point of zero turns the rule off.
+// @Tags stock
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Part ID"
+// @Param locationId path string true "Stock location ID"
+// @Param request body models.StockRuleRequest true "Reorder rule"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /parts/{id}/stock/{locationId} [put]
+func UpdateStockRule(c *fiber.Ctx) error {
+	var req models.StockRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+	if err := validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": err.Error()})
+	}
+	if req.ReorderPoint > 0 && req.ReorderQuantity <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "reorder_quantity must be positive when a reorder point is set"})
+	}
+
+	db := database.GetDB()
+	var part models.Part
+	if err := db.Select("id").First(&part, "id = ?", c.Params("id")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Part not found"})
+	}
+	var location models.StockLocation
+	if err := db.Select("id").First(&location, "id = ?", c.Params("locationId")).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Stock location not found"})
+	}
+
+	level := models.StockLevel{
+		PartID:          part.ID,
+		LocationID:      location.ID,
+		ReorderPoint:    req.ReorderPoint,
+		ReorderQuantity: req.ReorderQuantity,
+	}
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "part_id"}, {Name: "location_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reorder_point", "reorder_quantity", "updated_at"}),
+	}).Create(&level).Error
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to save reorder rule"})
+	}
+
+	level = models.StockLevel{}
+	db.Preload("Part").Preload("Location").First(&level, "part_id = ? AND location_id = ?", part.ID, location.ID)
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    level,
+		"message": "Reorder rule saved successfully",
+	})
+}
+
+// LowStockItem is a stock level at or below its reorder point and the quantity due to be
+// ordered: the reorder quantity, or the multiple of it that brings the stock back above the
+// reorder point
+type LowStockItem struct {
+	models.StockLevel
+	Shortfall     float64 `json:"shortfall"`
+	OrderQuantity float64 `json:"order_quantity"`
+}
+
+// GetLowStock godoc
+// @Summary Get the low stock report
+// @Description Get the stock levels at or below their reorder point, largest shortfall first
+// @Tags stock
+// @Produce  json
+// @Param location_id query string false "Stock location ID"
+// @Param supplier_id query string false "Supplier vendor ID"
+// @Success 200 {array} LowStockItem
+// @Failure 500 {object} fiber.Map
+// @Router /stock-levels/low [get]
+func GetLowStock(c *fiber.Ctx) error {
+	query := database.GetDB().Model(&models.StockLevel{}).
+		Where("reorder_point > 0 AND quantity_on_hand <= reorder_point").
+		Where("part_id IN (?)", database.GetDB().Model(&models.Part{}).Select("id"))
+	if locationID := c.Query("location_id"); locationID != "" {
+		id, err := uuid.Parse(locationID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid stock location ID"})
+		}
+		query = query.Where("location_id = ?", id)
+	}
+	if supplierID := c.Query("supplier_id"); supplierID != "" {
+		id, err := uuid.Parse(supplierID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid supplier ID"})
+		}
+		query = query.Where("part_id IN (?)", database.GetDB().Model(&models.Part{}).Select("id").Where("supplier_id = ?", id))
+	}
+
+	var levels []models.StockLevel
+	if err := query.Preload("Part.Supplier").Preload("Location").Order("reorder_point - quantity_on_hand DESC").Find(&levels).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to get low stock report"})
+	}
+
+	items := make([]LowStockItem, len(levels))
+	for i, level := range levels {
+		shortfall := level.ReorderPoint - level.QuantityOnHand
+		items[i] = LowStockItem{
+			StockLevel:    level,
+			Shortfall:     shortfall,
+			OrderQuantity: (math.Floor(shortfall/level.ReorderQuantity) + 1) * level.ReorderQuantity,
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": items})
+}
+
+// stockMovementTarget resolves what an issue is used on: the work order, whose asset it is
+// charged to, or the asset. Users may only issue parts to work orders assigned to them.
+func stockMovementTarget(c *fiber.Ctx, db *gorm.DB, req *models.StockMovementRequest, movement *models.StockMovement) error {
+	if req.WorkOrderID != nil {
+		var workOrder models.WorkOrder
+		if err := db.First(&workOrder, "id = ?", *req.WorkOrderID).Error; err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Work order not found")
+		}
+		if !canWorkOnOrder(c, &workOrder) {
+			return fiber.NewError(fiber.StatusForbidden, "Insufficient permissions")
+		}
+		if workOrder.Status == models.WorkOrderCancelled {
+			return fiber.NewError(fiber.StatusConflict, "Parts cannot be issued to a cancelled work order")
+		}
+		if req.AssetID != nil && *req.AssetID != workOrder.AssetID {
+			return fiber.NewError(fiber.StatusBadRequest, "asset_id does not match the work order's asset")
+		}
+		movement.WorkOrderID = &workOrder.ID
+		movement.AssetID = &workOrder.AssetID
+	} else if req.AssetID != nil {
+		if role := middleware.GetCurrentUserRole(c); role != "admin" && role != "manager" {
+			return fiber.NewError(fiber.StatusForbidden, "Insufficient permissions")
+		}
+		movement.AssetID = req.AssetID
+	} else {
+		return fiber.NewError(fiber.StatusBadRequest, "An issue needs an asset_id or work_order_id")
+	}
+
+	var asset models.Asset
+	if err := db.Select("id", "status").First(&asset, "id = ?", *movement.AssetID).Error; err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Asset not found")
+	}
+	if asset.Status == lifecycle.StatusDisposed {
+		return fiber.NewError(fiber.StatusConflict, "Parts cannot be issued to a disposed asset")
+	}
+	return nil
+}
+
+// CreateStockMovement godoc
+// @Summary Record a stock movement
+// @Description Receive stock into a location, issue it to an asset or work order, or adjust
+// @Description the quantity on hand after a count. Receipts and adjustments are made by
+// @Description managers; users may issue parts to the work orders assigned to them. Issued
+// @Description parts are charged at the part's average cost to the asset's cost of ownership.
+// @Tags stock
+// @Accept  json
+// @Produce  json
+// @Param request body models.StockMovementRequest true "Stock movement"
+// @Success 201 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 403 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /stock-movements [post]
+func CreateStockMovement(c *fiber.Ctx) error {
+	var req models.StockMovementRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+	if err := validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": err.Error()})
+	}
+
+	db := database.GetDB()
+	movement := models.StockMovement{
+		PartID:     req.PartID,
+		LocationID: req.LocationID,
+		Kind:       req.Kind,
+		Quantity:   req.Quantity,
+		Reference:  req.Reference,
+		Notes:      req.Notes,
+	}
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		movement.CreatedByID = &userID
+	}
+
+	var part models.Part
+	if err := db.First(&part, "id = ?", req.PartID).Error; err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Part not found"})
+	}
+	var location models.StockLocation
+	if err := db.Select("id").First(&location, "id = ?", req.LocationID).Error; err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Stock location not found"})
+	}
+
+	role := middleware.GetCurrentUserRole(c)
+	switch req.Kind {
+	case models.StockIssue:
+		if req.Quantity < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "quantity must be positive"})
+		}
+		if err := stockMovementTarget(c, db, &req, &movement); err != nil {
+			return pageError(c, err, "Failed to record stock movement")
+		}
+		movement.Quantity = -req.Quantity
+	default:
+		if role != "admin" && role != "manager" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": true, "message": "Insufficient permissions"})
+		}
+		if req.AssetID != nil || req.WorkOrderID != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Only issues are made to an asset or work order"})
+		}
+		if req.Kind == models.StockReceive {
+			if req.Quantity < 0 {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "quantity must be positive"})
+			}
+			// A receipt without a price comes in at the part's current cost
+			movement.UnitCost = part.UnitCost
+			if req.UnitCost != nil {
+				movement.UnitCost = *req.UnitCost
+			}
+		} else if strings.TrimSpace(req.Notes) == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "An adjustment needs notes explaining it"})
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return stock.Move(tx, &movement)
+	})
+	if err != nil {
+		if errors.Is(err, stock.ErrInsufficientStock) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Insufficient stock of " + part.PartNumber + " at this location"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to record stock movement"})
+	}
+
+	db.Preload("Part").Preload("Location").Preload("Asset").Preload("WorkOrder").Preload("CreatedBy").First(&movement, "id = ?", movement.ID)
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"error":   false,
+		"data":    movement,
+		"message": "Stock movement recorded successfully",
+	})
+}
+
+// GetStockMovements godoc
+// @Summary List stock movements
+// @Description Get stock movements, newest first
+// @Tags stock
+// @Produce  json
+// @Param part_id query string false "Part ID"
+// @Param location_id query string false "Stock location ID"
+// @Param kind query string false "receive, issue or adjust"
+// @Param asset_id query string false "Asset the parts were issued to"
+// @Param work_order_id query string false "Work order the parts were issued to"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Router /stock-movements [get]
+func GetStockMovements(c *fiber.Ctx) error {
+	query := database.GetDB().Model(&models.StockMovement{})
+	for _, param := range []string{"part_id", "location_id", "asset_id", "work_order_id"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid " + param})
+		}
+		query = query.Where(param+" = ?", id)
+	}
+	if kind := c.Query("kind"); kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	var movements []models.StockMovement
+	order := []filter.SortKey{{Column: "created_at", Desc: true}}
+	pagination, err := listPage(c, query, order, 20, &movements, "Part", "Location", "CreatedBy")
+	if err != nil {
+		return pageError(c, err, "Failed to fetch stock movements")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       movements,
+		"message":    "Stock movements retrieved successfully",
+		"pagination": pagination,
+	})
+}
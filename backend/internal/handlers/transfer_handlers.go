@@ -0,0 +1,444 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/filter"
+	"sams-backend/internal/lifecycle"
+	"sams-backend/internal/middleware"
+	"sams-backend/internal/models"
+)
+
+// managesDepartment reports whether the current user may act for the given department: admins
+// act for every department, managers only for the department they belong to
+func managesDepartment(c *fiber.Ctx, db *gorm.DB, departmentID *uuid.UUID) bool {
+	role := middleware.GetCurrentUserRole(c)
+	if role == "admin" {
+		return true
+	}
+	if role != "manager" || departmentID == nil {
+		return false
+	}
+
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		return false
+	}
+
+	var user models.User
+	if err := db.Select("id", "department_id").First(&user, "id = ?", userID).Error; err != nil {
+		return false
+	}
+	return user.DepartmentID != nil && *user.DepartmentID == *departmentID
+}
+
+// CreateAssetTransfer godoc
+// @Summary Request an inter-department transfer
+// @Description Request that an asset move to another department. Only a manager of the asset's
+// @Description current department (or an admin) can initiate, and the asset keeps its department
+// @Description until a manager of the receiving department accepts.
+// @Tags transfers
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Asset ID"
+// @Param request body models.TransferCreateRequest true "Transfer request"
+// @Success 201 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /assets/{id}/transfers [post]
+func CreateAssetTransfer(c *fiber.Ctx) error {
+	db := database.GetDB()
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid asset ID",
+		})
+	}
+
+	var req models.TransferCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	var asset models.Asset
+	if err := db.First(&asset, "id = ?", assetID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   true,
+				"message": "Asset not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to fetch asset",
+		})
+	}
+
+	if asset.Status == lifecycle.StatusDisposed {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   true,
+			"message": "Disposed assets cannot be transferred",
+		})
+	}
+
+	if !managesDepartment(c, db, asset.DepartmentID) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":   true,
+			"message": "Only a manager of the asset's department can request a transfer",
+		})
+	}
+
+	if asset.DepartmentID != nil && *asset.DepartmentID == req.ToDepartmentID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Asset already belongs to this department",
+		})
+	}
+
+	var department models.Department
+	if err := db.First(&department, "id = ?", req.ToDepartmentID).Error; err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Receiving department not found",
+		})
+	}
+
+	var pending int64
+	if err := db.Model(&models.AssetTransfer{}).Where("asset_id = ? AND status = ?", assetID, models.TransferPending).Count(&pending).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to check transfers",
+		})
+	}
+	if pending > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":   true,
+			"message": "Asset already has a pending transfer",
+		})
+	}
+
+	transfer := models.AssetTransfer{
+		AssetID:          assetID,
+		FromDepartmentID: asset.DepartmentID,
+		ToDepartmentID:   req.ToDepartmentID,
+		Reason:           req.Reason,
+		Status:           models.TransferPending,
+	}
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		transfer.RequestedByID = &userID
+	}
+
+	if err := db.Create(&transfer).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to create transfer",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"error":   false,
+		"data":    transfer,
+		"message": "Transfer requested successfully",
+	})
+}
+
+// GetAssetTransfers returns the transfer history of an asset
+// @Failure 500 {object} fiber.Map
+// @Router /assets/{id}/transfers [get]
+func GetAssetTransfers(c *fiber.Ctx) error {
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid asset ID",
+		})
+	}
+
+	query := database.GetDB().Model(&models.AssetTransfer{}).Where("asset_id = ?", assetID)
+	return listTransfers(c, query)
+}
+
+// GetDepartmentTransfers returns the transfers into and out of a department;
+// ?direction=incoming or outgoing limits it to one side
+// @Failure 500 {object} fiber.Map
+// @Router /departments/{id}/transfers [get]
+func GetDepartmentTransfers(c *fiber.Ctx) error {
+	departmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid department ID",
+		})
+	}
+
+	query := database.GetDB().Model(&models.AssetTransfer{})
+	switch c.Query("direction") {
+	case "incoming":
+		query = query.Where("to_department_id = ?", departmentID)
+	case "outgoing":
+		query = query.Where("from_department_id = ?", departmentID)
+	case "":
+		query = query.Where("to_department_id = ? OR from_department_id = ?", departmentID, departmentID)
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Direction must be incoming or outgoing",
+		})
+	}
+	return listTransfers(c, query)
+}
+
+// listTransfers paginates a transfer query; ?status= limits it to one transfer status
+func listTransfers(c *fiber.Ctx, query *gorm.DB) error {
+	if status := c.Query("status"); status != "" && status != "all" {
+		query = query.Where("status = ?", status)
+	}
+
+	var transfers []models.AssetTransfer
+	order := []filter.SortKey{{Column: "created_at", Desc: true}}
+	pagination, err := listPage(c, query, order, 20, &transfers, "Asset", "FromDepartment", "ToDepartment", "RequestedBy", "RespondedBy")
+	if err != nil {
+		return pageError(c, err, "Failed to fetch transfers")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       transfers,
+		"message":    "Transfers retrieved successfully",
+		"pagination": pagination,
+	})
+}
+
+// GetTransfer returns a single transfer by ID
+// @Failure 500 {object} fiber.Map
+// @Router /transfers/{id} [get]
+func GetTransfer(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var transfer models.AssetTransfer
+
+	if err := db.Preload("Asset").Preload("FromDepartment").Preload("ToDepartment").
+		Preload("RequestedBy").Preload("RespondedBy").
+		First(&transfer, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Transfer not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch transfer"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": transfer})
+}
+
+// errTransferNotPending is returned when responding to a transfer that was already decided
+var errTransferNotPending = errors.New("transfer is no longer pending")
+
+// AcceptTransfer godoc
+// @Summary Accept a transfer
+// @Description Accept a pending transfer as a manager of the receiving department. The asset moves
+// @Description to the receiving department, and its location, condition and value at handover are
+// @Description recorded on the transfer. Location and condition given here update the asset.
+// @Tags transfers
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Transfer ID"
+// @Param request body models.TransferResponseRequest false "Handover details"
+// @Success 200 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /transfers/{id}/accept [post]
+func AcceptTransfer(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var req models.TransferResponseRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+	if err := validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": err.Error()})
+	}
+
+	var responderID *uuid.UUID
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		responderID = &userID
+	}
+
+	now := time.Now()
+	var transfer models.AssetTransfer
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&transfer, "id = ?", c.Params("id")).Error; err != nil {
+			return err
+		}
+		if transfer.Status != models.TransferPending {
+			return errTransferNotPending
+		}
+		if !managesDepartment(c, tx, &transfer.ToDepartmentID) {
+			return fiber.NewError(fiber.StatusForbidden, "Only a manager of the receiving department can accept a transfer")
+		}
+
+		var asset models.Asset
+		if err := tx.First(&asset, "id = ?", transfer.AssetID).Error; err != nil {
+			return err
+		}
+		if asset.Status == lifecycle.StatusDisposed {
+			return fiber.NewError(fiber.StatusConflict, "Disposed assets cannot be transferred")
+		}
+		if !sameDepartment(asset.DepartmentID, transfer.FromDepartmentID) {
+			return fiber.NewError(fiber.StatusConflict, "Asset has changed department since the transfer was requested")
+		}
+
+		before := asset
+		asset.DepartmentID = &transfer.ToDepartmentID
+		if req.Address != "" {
+			asset.Address = req.Address
+		}
+		if req.BuildingRoom != "" {
+			asset.BuildingRoom = req.BuildingRoom
+		}
+		if req.Latitude != nil && req.Longitude != nil {
+			asset.Latitude = req.Latitude
+			asset.Longitude = req.Longitude
+		}
+		if req.Condition != "" {
+			asset.Condition = req.Condition
+		}
+		if err := saveAssetVersion(tx, &asset); err != nil {
+			return err
+		}
+		if locationChanged(&before, &asset) {
+			if err := checkGeofence(c, tx, &asset, models.LocationSourceTransfer); err != nil {
+				return err
+			}
+		}
+		if err := recordAssetHistory(c, tx, before, asset); err != nil {
+			return err
+		}
+
+		transfer.Status = models.TransferAccepted
+		transfer.RespondedByID = responderID
+		transfer.RespondedAt = &now
+		transfer.ResponseNotes = req.Notes
+		transfer.HandoverAddress = asset.Address
+		transfer.HandoverBuildingRoom = asset.BuildingRoom
+		transfer.HandoverLatitude = asset.Latitude
+		transfer.HandoverLongitude = asset.Longitude
+		transfer.HandoverCondition = asset.Condition
+		transfer.HandoverValue = asset.CurrentValue
+		return tx.Save(&transfer).Error
+	})
+	if err != nil {
+		return transferResponseError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": transfer, "message": "Transfer accepted"})
+}
+
+// RejectTransfer godoc
+// @Summary Reject a transfer
+// @Description Reject a pending transfer as a manager of the receiving department
+// @Tags transfers
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Transfer ID"
+// @Param request body models.TransferResponseRequest false "Notes"
+// @Success 200 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /transfers/{id}/reject [post]
+func RejectTransfer(c *fiber.Ctx) error {
+	return closeTransfer(c, models.TransferRejected, func(db *gorm.DB, transfer *models.AssetTransfer) bool {
+		return managesDepartment(c, db, &transfer.ToDepartmentID)
+	})
+}
+
+// CancelTransfer godoc
+// @Summary Cancel a transfer
+// @Description Withdraw a pending transfer as its requester or a manager of the source department
+// @Tags transfers
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Transfer ID"
+// @Param request body models.TransferResponseRequest false "Notes"
+// @Success 200 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /transfers/{id}/cancel [post]
+func CancelTransfer(c *fiber.Ctx) error {
+	return closeTransfer(c, models.TransferCancelled, func(db *gorm.DB, transfer *models.AssetTransfer) bool {
+		if userID, err := middleware.GetCurrentUserID(c); err == nil && transfer.RequestedByID != nil && *transfer.RequestedByID == userID {
+			return true
+		}
+		return managesDepartment(c, db, transfer.FromDepartmentID)
+	})
+}
+
+// closeTransfer moves a pending transfer to rejected or cancelled when allowed reports that the
+// current user may do so
+func closeTransfer(c *fiber.Ctx, status string, allowed func(db *gorm.DB, transfer *models.AssetTransfer) bool) error {
+	db := database.GetDB()
+	var req models.TransferResponseRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+
+	var responderID *uuid.UUID
+	if userID, err := middleware.GetCurrentUserID(c); err == nil {
+		responderID = &userID
+	}
+
+	now := time.Now()
+	var transfer models.AssetTransfer
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&transfer, "id = ?", c.Params("id")).Error; err != nil {
+			return err
+		}
+		if transfer.Status != models.TransferPending {
+			return errTransferNotPending
+		}
+		if !allowed(tx, &transfer) {
+			return fiber.NewError(fiber.StatusForbidden, "Insufficient permissions for this transfer")
+		}
+
+		transfer.Status = status
+		transfer.RespondedByID = responderID
+		transfer.RespondedAt = &now
+		transfer.ResponseNotes = req.Notes
+		return tx.Save(&transfer).Error
+	})
+	if err != nil {
+		return transferResponseError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": transfer, "message": "Transfer " + status})
+}
+
+// transferResponseError maps an error from responding to a transfer to a response
+func transferResponseError(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Transfer not found"})
+	case errors.Is(err, errTransferNotPending):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Transfer is no longer pending"})
+	case errors.As(err, &fiberErr):
+		return c.Status(fiberErr.Code).JSON(fiber.Map{"error": true, "message": fiberErr.Message})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to update transfer"})
+}
+
+// sameDepartment reports whether two optional department IDs refer to the same department
+func sameDepartment(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/filter"
+	"sams-backend/internal/middleware"
+	"sams-backend/internal/trash"
+)
+
+// trashKind resolves the :type route parameter, returning an error response when the type is
+// unknown or restricted to admins
+func trashKind(c *fiber.Ctx) (*trash.Kind, error) {
+	kind, ok := trash.Lookup(c.Params("type"))
+	if !ok {
+		return nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   true,
+			"message": "Trash type must be assets, categories, departments or users",
+		})
+	}
+	if kind.AdminOnly && middleware.GetCurrentUserRole(c) != "admin" {
+		return nil, c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":   true,
+			"message": "Insufficient permissions",
+		})
+	}
+	return kind, nil
+}
+
+// GetTrash godoc
+// @Summary List deleted records
+// @Description Get a paginated list of deleted assets, categories, departments or users, most
+// @Description recently deleted first
+// @Tags trash
+// @Produce  json
+// @Param type path string true "assets, categories, departments or users"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Param after query string false "Cursor from next_cursor; switches to keyset pagination"
+// @Param before query string false "Cursor from prev_cursor; switches to keyset pagination"
+// @Param count query bool false "Set to false to skip the total count"
+// @Success 200 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /trash/{type} [get]
+func GetTrash(c *fiber.Ctx) error {
+	kind, err := trashKind(c)
+	if kind == nil {
+		return err
+	}
+
+	records := kind.NewList()
+	order := []filter.SortKey{{Column: "deleted_at", Desc: true}}
+	pagination, err := listPage(c, kind.Query(database.GetDB()), order, 20, records)
+	if err != nil {
+		return pageError(c, err, "Failed to fetch trash")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       kind.Present(records),
+		"message":    "Trash retrieved successfully",
+		"pagination": pagination,
+	})
+}
+
+// RestoreFromTrash godoc
+// @Summary Restore a deleted record
+// @Description Take a record out of the trash. Restoring fails with 409 when it would clash with
+// @Description live data, such as a serial number reused since the delete.
+// @Tags trash
+// @Produce  json
+// @Param type path string true "assets, categories, departments or users"
+// @Param id path string true "Record ID"
+// @Success 200 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /trash/{type}/{id}/restore [post]
+func RestoreFromTrash(c *fiber.Ctx) error {
+	kind, err := trashKind(c)
+	if kind == nil {
+		return err
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid ID",
+		})
+	}
+
+	record, err := kind.Restore(database.GetDB(), id.String())
+	if err != nil {
+		var conflict *trash.ConflictError
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   true,
+				"message": "Record not found in trash",
+			})
+		case errors.As(err, &conflict):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":     true,
+				"message":   "Record cannot be restored",
+				"conflicts": conflict.Conflicts,
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to restore record",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    record,
+		"message": "Record restored successfully",
+	})
+}
+
+// PurgeFromTrash godoc
+// @Summary Permanently delete a record
+// @Description Permanently delete a record in the trash. Deleting an asset also deletes its
+// @Description custody, sightings, alerts, meters and maintenance records; its history and status
+// @Description events are kept. Assets with disposal requests, transfers, inventory scans,
+// @Description contracts or stock movements cannot be purged.
+// @Tags trash
+// @Produce  json
+// @Param type path string true "assets, categories, departments or users"
+// @Param id path string true "Record ID"
+// @Success 200 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /trash/{type}/{id} [delete]
+func PurgeFromTrash(c *fiber.Ctx) error {
+	kind, err := trashKind(c)
+	if kind == nil {
+		return err
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid ID",
+		})
+	}
+
+	if err := kind.PurgeOne(database.GetDB(), id.String()); err != nil {
+		var retained *trash.RetainedError
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   true,
+				"message": "Record not found in trash",
+			})
+		case errors.As(err, &retained):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":    true,
+				"message":  "Record has records that are kept and cannot be purged",
+				"retained": retained.Records,
+			})
+		case errors.Is(err, gorm.ErrForeignKeyViolated):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":   true,
+				"message": "Record is still referenced by other records and cannot be purged",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to purge record",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"message": "Record permanently deleted",
+	})
+}
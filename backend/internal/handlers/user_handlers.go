@@ -2,12 +2,12 @@ package handlers
 
 import (
 	"errors"
-	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
+	"sams-backend/internal/filter"
 	"sams-backend/internal/models"
 	// "sams-backend/internal/database" // Removed unused import
 
@@ -29,35 +29,32 @@ func NewUserHandler(db *gorm.DB) *UserHandler {
 
 // GetUsers retrieves all users with pagination
 func (h *UserHandler) GetUsers(c *fiber.Ctx) error {
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 	search := c.Query("search", "")
 
-	offset := (page - 1) * limit
-
-	var users []models.User
-	var total int64
-
-	query := h.db.Model(&models.User{}).Preload("Department")
+	query := h.db.Model(&models.User{})
 
 	if search != "" {
 		query = query.Where("username ILIKE ? OR email ILIKE ? OR first_name ILIKE ? OR last_name ILIKE ?",
 			"%"+search+"%", "%"+search+"%", "%"+search+"%", "%"+search+"%")
 	}
 
-	// Get total count
-	if err := query.Count(&total).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to count users",
-		})
-	}
-
-	// Get users with pagination
-	if err := query.Offset(offset).Limit(limit).Order("created_at DESC").Find(&users).Error; err != nil {
+	var users []models.User
+	order := []filter.SortKey{{Column: "created_at", Desc: true}}
+	pagination, err := listPage(c, query, order, 10, &users, "Department")
+	if err != nil {
+		if fiberErr, ok := err.(*fiber.Error); ok {
+			return c.Status(fiberErr.Code).JSON(fiber.Map{
+				"error": fiberErr.Message,
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch users",
 		})
 	}
+	// Older clients read the page count as pages
+	if totalPages, ok := pagination["total_pages"]; ok {
+		pagination["pages"] = totalPages
+	}
 
 	// Convert to response format
 	var userResponses []models.UserResponse
@@ -79,13 +76,8 @@ func (h *UserHandler) GetUsers(c *fiber.Ctx) error {
 	}
 
 	return c.JSON(fiber.Map{
-		"data": userResponses,
-		"pagination": fiber.Map{
-			"page":  page,
-			"limit": limit,
-			"total": total,
-			"pages": (total + int64(limit) - 1) / int64(limit),
-		},
+		"data":       userResponses,
+		"pagination": pagination,
 	})
 }
 
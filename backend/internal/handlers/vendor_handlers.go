@@ -0,0 +1,508 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/filter"
+	"sams-backend/internal/lifecycle"
+	"sams-backend/internal/models"
+)
+
+// vendorRoleColumns maps the role query parameter to the vendor column holding the role
+var vendorRoleColumns = map[string]string{
+	"manufacturer":     "is_manufacturer",
+	"supplier":         "is_supplier",
+	"service_provider": "is_service_provider",
+}
+
+// linkAssetVendors checks that the vendors an asset is linked to exist and hold the matching
+// role. An asset linked to a manufacturer without a manufacturer name takes the vendor's name.
+func linkAssetVendors(db *gorm.DB, asset *models.Asset) *fiber.Error {
+	if asset.ManufacturerID != nil {
+		var vendor models.Vendor
+		if err := db.First(&vendor, "id = ?", *asset.ManufacturerID).Error; err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Manufacturer not found")
+		}
+		if !vendor.IsManufacturer {
+			return fiber.NewError(fiber.StatusBadRequest, "Vendor "+vendor.Name+" is not a manufacturer")
+		}
+		if strings.TrimSpace(asset.Manufacturer) == "" && len(vendor.Name) <= 100 {
+			asset.Manufacturer = vendor.Name
+		}
+	}
+	if asset.SupplierID != nil {
+		var vendor models.Vendor
+		if err := db.First(&vendor, "id = ?", *asset.SupplierID).Error; err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Supplier not found")
+		}
+		if !vendor.IsSupplier {
+			return fiber.NewError(fiber.StatusBadRequest, "Vendor "+vendor.Name+" is not a supplier")
+		}
+	}
+	return nil
+}
+
+// vendorRequest parses and validates the request body. When it is invalid the error response
+// is written and the returned request is nil.
+func vendorRequest(c *fiber.Ctx) (*models.VendorRequest, error) {
+	var req models.VendorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid request body",
+		})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if err := validate.Struct(req); err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	if !req.IsManufacturer && !req.IsSupplier && !req.IsServiceProvider {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "A vendor needs at least one role: manufacturer, supplier or service provider",
+		})
+	}
+	return &req, nil
+}
+
+// applyVendorRequest copies the request onto a vendor
+func applyVendorRequest(vendor *models.Vendor, req *models.VendorRequest) {
+	vendor.Name = req.Name
+	vendor.IsManufacturer = req.IsManufacturer
+	vendor.IsSupplier = req.IsSupplier
+	vendor.IsServiceProvider = req.IsServiceProvider
+	vendor.Website = req.Website
+	vendor.Email = req.Email
+	vendor.Phone = req.Phone
+	vendor.Address = req.Address
+	vendor.Notes = req.Notes
+}
+
+// saveVendor inserts or updates a vendor, rejecting a name already used by another vendor in
+// any letter case
+func saveVendor(c *fiber.Ctx, vendor *models.Vendor, message string, status int) error {
+	db := database.GetDB()
+	var existing int64
+	if err := db.Model(&models.Vendor{}).Where("LOWER(name) = LOWER(?) AND id <> ?", vendor.Name, vendor.ID).Count(&existing).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to save vendor"})
+	}
+	if existing > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Vendor with this name already exists"})
+	}
+
+	if err := db.Save(vendor).Error; err != nil {
+		if errors.Is(database.TranslateError(db, err), gorm.ErrDuplicatedKey) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Vendor with this name already exists"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to save vendor"})
+	}
+
+	db.Preload("Contacts").First(vendor, "id = ?", vendor.ID)
+	return c.Status(status).JSON(fiber.Map{
+		"error":   false,
+		"data":    vendor,
+		"message": message,
+	})
+}
+
+// GetVendors godoc
+// @Summary List vendors
+// @Description Get vendors ordered by name
+// @Tags vendors
+// @Produce  json
+// @Param role query string false "manufacturer, supplier or service_provider"
+// @Param search query string false "Name contains"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 500 {object} fiber.Map
+// @Router /vendors [get]
+func GetVendors(c *fiber.Ctx) error {
+	query := database.GetDB().Model(&models.Vendor{})
+	if role := c.Query("role"); role != "" {
+		column, ok := vendorRoleColumns[role]
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Role must be manufacturer, supplier or service_provider"})
+		}
+		query = query.Where(column+" = ?", true)
+	}
+	if term := strings.TrimSpace(c.Query("search")); term != "" {
+		query = query.Where("name ILIKE ?", "%"+term+"%")
+	}
+
+	var vendors []models.Vendor
+	order := []filter.SortKey{{Column: "name"}}
+	pagination, err := listPage(c, query, order, 20, &vendors, "Contacts")
+	if err != nil {
+		return pageError(c, err, "Failed to fetch vendors")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       vendors,
+		"message":    "Vendors retrieved successfully",
+		"pagination": pagination,
+	})
+}
+
+// GetVendor godoc
+// @Summary Get a vendor
+// @Description Get a single vendor with its contacts
+// @Tags vendors
+// @Produce  json
+// @Param id path string true "Vendor ID"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /vendors/{id} [get]
+func GetVendor(c *fiber.Ctx) error {
+	var vendor models.Vendor
+	if err := database.GetDB().Preload("Contacts").First(&vendor, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Vendor not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch vendor"})
+	}
+
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"data":    vendor,
+		"message": "Vendor retrieved successfully",
+	})
+}
+
+// CreateVendor godoc
+// @Summary Create a vendor
+// @Description Register a manufacturer, supplier or service provider
+// @Tags vendors
+// @Accept  json
+// @Produce  json
+// @Param request body models.VendorRequest true "Vendor"
+// @Success 201 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /vendors [post]
+func CreateVendor(c *fiber.Ctx) error {
+	req, err := vendorRequest(c)
+	if req == nil {
+		return err
+	}
+
+	vendor := models.Vendor{ID: uuid.New()}
+	applyVendorRequest(&vendor, req)
+	return saveVendor(c, &vendor, "Vendor created successfully", fiber.StatusCreated)
+}
+
+// UpdateVendor godoc
+// @Summary Replace a vendor
+// @Description Replace a vendor's name, roles and company details. A role cannot be removed
+// @Description while assets are linked to the vendor in that role.
+// @Tags vendors
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Vendor ID"
+// @Param request body models.VendorRequest true "Vendor"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /vendors/{id} [put]
+func UpdateVendor(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var vendor models.Vendor
+	if err := db.First(&vendor, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Vendor not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch vendor"})
+	}
+
+	req, err := vendorRequest(c)
+	if req == nil {
+		return err
+	}
+
+	for _, role := range []struct {
+		dropped bool
+		column  string
+		name    string
+	}{
+		{vendor.IsManufacturer && !req.IsManufacturer, "manufacturer_id", "manufacturer"},
+		{vendor.IsSupplier && !req.IsSupplier, "supplier_id", "supplier"},
+	} {
+		if !role.dropped {
+			continue
+		}
+		var linked int64
+		// Assets in the trash count, as they would fail to save once restored
+		if err := db.Unscoped().Model(&models.Asset{}).Where(role.column+" = ?", vendor.ID).Count(&linked).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to check linked assets"})
+		}
+		if linked > 0 {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Vendor is the " + role.name + " of linked assets"})
+		}
+	}
+
+	applyVendorRequest(&vendor, req)
+	return saveVendor(c, &vendor, "Vendor updated successfully", fiber.StatusOK)
+}
+
+// DeleteVendor godoc
+// @Summary Delete a vendor
+// @Description Delete a vendor that no asset or contract, including those in the trash, is linked to
+// @Tags vendors
+// @Produce  json
+// @Param id path string true "Vendor ID"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Failure 409 {object} fiber.Map
+// @Router /vendors/{id} [delete]
+func DeleteVendor(c *fiber.Ctx) error {
+	db := database.GetDB()
+	var vendor models.Vendor
+	if err := db.First(&vendor, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Vendor not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch vendor"})
+	}
+
+	var assets, contracts int64
+	// Assets and contracts in the trash count, as they would fail to save once restored
+	if err := db.Unscoped().Model(&models.Asset{}).Where("manufacturer_id = ? OR supplier_id = ?", vendor.ID, vendor.ID).Count(&assets).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to check linked assets"})
+	}
+	if err := db.Unscoped().Model(&models.Contract{}).Where("vendor_id = ?", vendor.ID).Count(&contracts).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to check linked contracts"})
+	}
+	if assets > 0 || contracts > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": true, "message": "Vendor is linked to assets or contracts"})
+	}
+
+	if err := db.Delete(&vendor).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to delete vendor"})
+	}
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"message": "Vendor deleted successfully",
+	})
+}
+
+// saveVendorContact inserts or updates a contact from the request body. A primary contact
+// replaces the vendor's previous one.
+func saveVendorContact(c *fiber.Ctx, contact *models.VendorContact, message string, status int) error {
+	var req models.VendorContactRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid request body"})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if err := validate.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": err.Error()})
+	}
+
+	contact.Name = req.Name
+	contact.Title = req.Title
+	contact.Email = req.Email
+	contact.Phone = req.Phone
+	contact.IsPrimary = req.IsPrimary
+	contact.Notes = req.Notes
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if contact.IsPrimary {
+			if err := tx.Model(&models.VendorContact{}).Where("vendor_id = ? AND id <> ?", contact.VendorID, contact.ID).
+				Update("is_primary", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(contact).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to save contact"})
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"error":   false,
+		"data":    contact,
+		"message": message,
+	})
+}
+
+// CreateVendorContact godoc
+// @Summary Add a vendor contact
+// @Description Add a person to reach at a vendor
+// @Tags vendors
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Vendor ID"
+// @Param request body models.VendorContactRequest true "Contact"
+// @Success 201 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /vendors/{id}/contacts [post]
+func CreateVendorContact(c *fiber.Ctx) error {
+	var vendor models.Vendor
+	if err := database.GetDB().Select("id").First(&vendor, "id = ?", c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Vendor not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch vendor"})
+	}
+
+	contact := models.VendorContact{ID: uuid.New(), VendorID: vendor.ID}
+	return saveVendorContact(c, &contact, "Contact created successfully", fiber.StatusCreated)
+}
+
+// findVendorContact loads a contact of the vendor in the route. When it is not found the error
+// response is written and the returned contact is nil.
+func findVendorContact(c *fiber.Ctx) (*models.VendorContact, error) {
+	var contact models.VendorContact
+	if err := database.GetDB().First(&contact, "id = ? AND vendor_id = ?", c.Params("contactId"), c.Params("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": true, "message": "Contact not found"})
+		}
+		return nil, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to fetch contact"})
+	}
+	return &contact, nil
+}
+
+// UpdateVendorContact godoc
+// @Summary Replace a vendor contact
+// @Tags vendors
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Vendor ID"
+// @Param contactId path string true "Contact ID"
+// @Param request body models.VendorContactRequest true "Contact"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /vendors/{id}/contacts/{contactId} [put]
+func UpdateVendorContact(c *fiber.Ctx) error {
+	contact, err := findVendorContact(c)
+	if contact == nil {
+		return err
+	}
+	return saveVendorContact(c, contact, "Contact updated successfully", fiber.StatusOK)
+}
+
+// DeleteVendorContact godoc
+// @Summary Delete a vendor contact
+// @Tags vendors
+// @Produce  json
+// @Param id path string true "Vendor ID"
+// @Param contactId path string true "Contact ID"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} fiber.Map
+// @Router /vendors/{id}/contacts/{contactId} [delete]
+func DeleteVendorContact(c *fiber.Ctx) error {
+	contact, err := findVendorContact(c)
+	if contact == nil {
+		return err
+	}
+	if err := database.GetDB().Delete(contact).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to delete contact"})
+	}
+	return c.JSON(fiber.Map{
+		"error":   false,
+		"message": "Contact deleted successfully",
+	})
+}
+
+// GetVendorAssets godoc
+// @Summary Get vendor assets
+// @Description Get the assets a vendor manufactured or supplied
+// @Tags vendors
+// @Produce  json
+// @Param id path string true "Vendor ID"
+// @Param role query string false "manufacturer or supplier"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Router /vendors/{id}/assets [get]
+func GetVendorAssets(c *fiber.Ctx) error {
+	vendorID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Invalid vendor ID"})
+	}
+
+	query := database.GetDB().Model(&models.Asset{})
+	switch c.Query("role") {
+	case "":
+		query = query.Where("manufacturer_id = ? OR supplier_id = ?", vendorID, vendorID)
+	case "manufacturer":
+		query = query.Where("manufacturer_id = ?", vendorID)
+	case "supplier":
+		query = query.Where("supplier_id = ?", vendorID)
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Role must be manufacturer or supplier"})
+	}
+
+	var assets []models.Asset
+	order := []filter.SortKey{{Column: "name"}}
+	pagination, err := listPage(c, query, order, 20, &assets, "Category", "Department")
+	if err != nil {
+		return pageError(c, err, "Failed to fetch vendor assets")
+	}
+
+	return c.JSON(fiber.Map{
+		"error":      false,
+		"data":       assets,
+		"message":    "Vendor assets retrieved successfully",
+		"pagination": pagination,
+	})
+}
+
+// VendorSummary is the number and value of the assets linked to a vendor. An asset the vendor
+// both manufactured and supplied is counted once in the totals.
+type VendorSummary struct {
+	VendorID           uuid.UUID `json:"vendor_id"`
+	Name               string    `json:"name"`
+	ManufacturedAssets int64     `json:"manufactured_assets"`
+	SuppliedAssets     int64     `json:"supplied_assets"`
+	AssetCount         int64     `json:"asset_count"`
+	AcquisitionCost    float64   `json:"acquisition_cost"`
+	CurrentValue       float64   `json:"current_value"`
+}
+
+// GetVendorSummary godoc
+// @Summary Get asset summary by vendor
+// @Description Get the number, acquisition cost and current value of the assets each vendor
+// @Description manufactured or supplied, highest value first. Disposed assets are left out.
+// @Tags vendors
+// @Produce  json
+// @Param role query string false "manufacturer, supplier or service_provider"
+// @Success 200 {array} VendorSummary
+// @Failure 500 {object} fiber.Map
+// @Router /vendors/summary [get]
+func GetVendorSummary(c *fiber.Ctx) error {
+	query := database.GetDB().Table("vendors").
+		Select("vendors.id AS vendor_id, vendors.name, "+
+			"COUNT(assets.id) FILTER (WHERE assets.manufacturer_id = vendors.id) AS manufactured_assets, "+
+			"COUNT(assets.id) FILTER (WHERE assets.supplier_id = vendors.id) AS supplied_assets, "+
+			"COUNT(assets.id) AS asset_count, "+
+			"COALESCE(SUM(assets.acquisition_cost), 0) AS acquisition_cost, "+
+			"COALESCE(SUM(assets.current_value), 0) AS current_value").
+		Joins("LEFT JOIN assets ON (assets.manufacturer_id = vendors.id OR assets.supplier_id = vendors.id) "+
+			"AND assets.deleted_at IS NULL AND assets.status <> ?", lifecycle.StatusDisposed).
+		Where("vendors.deleted_at IS NULL")
+	if role := c.Query("role"); role != "" {
+		column, ok := vendorRoleColumns[role]
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": true, "message": "Role must be manufacturer, supplier or service_provider"})
+		}
+		query = query.Where("vendors."+column+" = ?", true)
+	}
+
+	var results []VendorSummary
+	if err := query.Group("vendors.id, vendors.name").Order("current_value DESC, vendors.name").Scan(&results).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": true, "message": "Failed to get vendor summary"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"error": false, "data": results})
+}
@@ -0,0 +1,93 @@
+package labels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// Symbologies
+const (
+	SymbologyQR         = "qr"
+	SymbologyCode128    = "code128"
+	SymbologyCode39     = "code39"
+	SymbologyDataMatrix = "datamatrix"
+)
+
+// Barcode is an encoded symbol as a grid of modules, dark where true. Linear barcodes have a
+// single row, which is drawn at whatever bar height suits the output.
+type Barcode struct {
+	Symbology string
+	// Text is the human-readable interpretation printed with the symbol
+	Text    string
+	Modules [][]bool
+	// QuietZone is the blank margin the symbol needs on each side, in modules
+	QuietZone int
+}
+
+// Linear reports whether the barcode is a one-dimensional row of bars
+func (b Barcode) Linear() bool {
+	return len(b.Modules) == 1
+}
+
+// Width returns the width of the symbol in modules, excluding the quiet zone
+func (b Barcode) Width() int {
+	if len(b.Modules) == 0 {
+		return 0
+	}
+	return len(b.Modules[0])
+}
+
+// Encode encodes text as a Code 128, Code 39 or DataMatrix barcode
+func Encode(symbology, text string) (Barcode, error) {
+	if text == "" {
+		return Barcode{}, fmt.Errorf("nothing to encode")
+	}
+	switch strings.ToLower(symbology) {
+	case SymbologyCode128:
+		return encodeCode128(text)
+	case SymbologyCode39:
+		return encodeCode39(text)
+	case SymbologyDataMatrix:
+		return encodeDataMatrix(text)
+	}
+	return Barcode{}, fmt.Errorf("unknown barcode type %q: use code128, code39 or datamatrix", symbology)
+}
+
+// QR encodes content as a QR code. QR codes carry links rather than text meant to be read, so
+// the barcode has no human-readable text.
+func QR(content string, level qrcode.RecoveryLevel) (Barcode, error) {
+	code, err := qrcode.New(content, level)
+	if err != nil {
+		return Barcode{}, err
+	}
+	code.DisableBorder = true
+	return Barcode{Symbology: SymbologyQR, Modules: code.Bitmap(), QuietZone: 4}, nil
+}
+
+// linearModules expands alternating bar and space widths, starting with a bar, into modules
+func linearModules(widths []int) [][]bool {
+	var row []bool
+	for i, width := range widths {
+		for j := 0; j < width; j++ {
+			row = append(row, i%2 == 0)
+		}
+	}
+	return [][]bool{row}
+}
+
+// runs calls fn for each horizontal run of dark modules in a row
+func runs(row []bool, fn func(start, length int)) {
+	for x := 0; x < len(row); {
+		if !row[x] {
+			x++
+			continue
+		}
+		start := x
+		for x < len(row) && row[x] {
+			x++
+		}
+		fn(start, x-start)
+	}
+}
@@ -0,0 +1,93 @@
+package labels
+
+import (
+	"fmt"
+)
+
+// code128Patterns are the bar and space widths of each Code 128 symbol value, ending with the
+// start codes A, B and C and the stop pattern
+var code128Patterns = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128CodeB  = 100
+	code128CodeC  = 99
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// encodeCode128 encodes printable ASCII using code set B, switching to code set C, which packs
+// two digits into each symbol, for runs of digits long enough to make the switch worthwhile
+func encodeCode128(text string) (Barcode, error) {
+	for i := 0; i < len(text); i++ {
+		if text[i] < 32 || text[i] > 126 {
+			return Barcode{}, fmt.Errorf("code128 can only encode printable ASCII characters")
+		}
+	}
+
+	var values []int
+	setC := false
+	for i := 0; i < len(text); {
+		digits := digitRun(text[i:])
+		// A run pays for the switch to C when it is at least four digits at the start or end,
+		// or six in the middle; an odd digit is left in code set B
+		worthwhile := digits >= 6 || (digits >= 4 && (i == 0 || i+digits == len(text)))
+		switch {
+		case !setC && worthwhile:
+			if i == 0 {
+				values = append(values, code128StartC)
+			} else {
+				values = append(values, code128CodeC)
+			}
+			setC = true
+		case setC && digits < 2:
+			values = append(values, code128CodeB)
+			setC = false
+		case i == 0:
+			values = append(values, code128StartB)
+		}
+
+		if setC {
+			values = append(values, int(text[i]-'0')*10+int(text[i+1]-'0'))
+			i += 2
+		} else {
+			values = append(values, int(text[i])-32)
+			i++
+		}
+	}
+
+	checksum := values[0]
+	for i, value := range values[1:] {
+		checksum += (i + 1) * value
+	}
+	values = append(values, checksum%103, code128Stop)
+
+	var widths []int
+	for _, value := range values {
+		for _, w := range code128Patterns[value] {
+			widths = append(widths, int(w-'0'))
+		}
+	}
+	return Barcode{Symbology: SymbologyCode128, Text: text, Modules: linearModules(widths), QuietZone: 10}, nil
+}
+
+// digitRun returns the number of leading digits in s
+func digitRun(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,120 @@
+package labels
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCode128Values(t *testing.T) {
+	tests := []struct {
+		text string
+		want []int
+	}{
+		// Start B, P J J 1 2 3 C, checksum, stop
+		{"PJJ123C", []int{104, 48, 42, 42, 17, 18, 19, 35, 55, 106}},
+		// Start C, 12 34 56, checksum, stop
+		{"123456", []int{105, 12, 34, 56, 44, 106}},
+		// An odd digit at the end of a leading run is left in code set B
+		{"12345", []int{105, 12, 34, 100, 21, 54, 106}},
+		// Four trailing digits pay for the switch to code set C
+		{"AB12345678", []int{104, 33, 34, 99, 12, 34, 56, 78, 57, 106}},
+		// Four digits in the middle do not
+		{"A1234B", []int{104, 33, 17, 18, 19, 20, 34, 90, 106}},
+	}
+	for _, tt := range tests {
+		code, err := encodeCode128(tt.text)
+		if err != nil {
+			t.Fatalf("encodeCode128(%q): %v", tt.text, err)
+		}
+		if got := code128Values(t, code.Modules[0]); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("encodeCode128(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCode128Patterns(t *testing.T) {
+	// Value 0, start A, B and C and stop, as given in ISO/IEC 15417
+	for value, want := range map[int]string{0: "212222", 103: "211412", 104: "211214", 105: "211232", 106: "2331112"} {
+		if code128Patterns[value] != want {
+			t.Errorf("pattern %d = %s, want %s", value, code128Patterns[value], want)
+		}
+	}
+
+	seen := map[string]int{}
+	for value, pattern := range code128Patterns {
+		modules, bars := 0, 0
+		for i, w := range pattern {
+			modules += int(w - '0')
+			if i%2 == 0 {
+				bars += int(w - '0')
+			}
+		}
+		if want := 11 + 2*(value/code128Stop); modules != want {
+			t.Errorf("pattern %d is %d modules wide, want %d", value, modules, want)
+		}
+		// Each symbol's bars cover an even number of modules
+		if bars%2 != 0 {
+			t.Errorf("pattern %d has bars %d modules wide", value, bars)
+		}
+		if other, ok := seen[pattern]; ok {
+			t.Errorf("patterns %d and %d are both %s", other, value, pattern)
+		}
+		seen[pattern] = value
+	}
+}
+
+func TestCode128Rejects(t *testing.T) {
+	for _, text := range []string{"tab\there", "café"} {
+		if _, err := encodeCode128(text); err == nil {
+			t.Errorf("encodeCode128(%q): expected an error", text)
+		}
+	}
+}
+
+// code128Values reads the symbol values back from a row of modules
+func code128Values(t *testing.T, row []bool) []int {
+	t.Helper()
+	lookup := map[string]int{}
+	for value, pattern := range code128Patterns {
+		lookup[pattern] = value
+	}
+
+	widths := moduleWidths(row)
+	var values []int
+	for len(widths) > 0 {
+		n := 6
+		if len(widths) == 7 {
+			n = 7
+		}
+		if len(widths) < n {
+			t.Fatalf("%d widths left over", len(widths))
+		}
+		var pattern strings.Builder
+		for _, w := range widths[:n] {
+			pattern.WriteString(strconv.Itoa(w))
+		}
+		value, ok := lookup[pattern.String()]
+		if !ok {
+			t.Fatalf("unknown pattern %s", pattern.String())
+		}
+		values = append(values, value)
+		widths = widths[n:]
+	}
+	return values
+}
+
+// moduleWidths returns the widths of the alternating bars and spaces of a row of modules
+func moduleWidths(row []bool) []int {
+	var widths []int
+	for i := 0; i < len(row); {
+		j := i
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+		widths = append(widths, j-i)
+		i = j
+	}
+	return widths
+}
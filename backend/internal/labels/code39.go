@@ -0,0 +1,51 @@
+package labels
+
+import (
+	"fmt"
+	"strings"
+)
+
+// code39Patterns are the nine alternating bar and space elements of each Code 39 character,
+// narrow (n) or wide (w). The asterisk is reserved for the start and stop character.
+var code39Patterns = map[rune]string{
+	'0': "nnnwwnwnn", '1': "wnnwnnnnw", '2': "nnwwnnnnw", '3': "wnwwnnnnn", '4': "nnnwwnnnw",
+	'5': "wnnwwnnnn", '6': "nnwwwnnnn", '7': "nnnwnnwnw", '8': "wnnwnnwnn", '9': "nnwwnnwnn",
+	'A': "wnnnnwnnw", 'B': "nnwnnwnnw", 'C': "wnwnnwnnn", 'D': "nnnnwwnnw", 'E': "wnnnwwnnn",
+	'F': "nnwnwwnnn", 'G': "nnnnnwwnw", 'H': "wnnnnwwnn", 'I': "nnwnnwwnn", 'J': "nnnnwwwnn",
+	'K': "wnnnnnnww", 'L': "nnwnnnnww", 'M': "wnwnnnnwn", 'N': "nnnnwnnww", 'O': "wnnnwnnwn",
+	'P': "nnwnwnnwn", 'Q': "nnnnnnwww", 'R': "wnnnnnwwn", 'S': "nnwnnnwwn", 'T': "nnnnwnwwn",
+	'U': "wwnnnnnnw", 'V': "nwwnnnnnw", 'W': "wwwnnnnnn", 'X': "nwnnwnnnw", 'Y': "wwnnwnnnn",
+	'Z': "nwwnwnnnn", '-': "nwnnnnwnw", '.': "wwnnnnwnn", ' ': "nwwnnnwnn", '$': "nwnwnwnnn",
+	'/': "nwnwnnnwn", '+': "nwnnnwnwn", '%': "nnnwnwnwn", '*': "nwnnwnwnn",
+}
+
+// code39Wide is the width of a wide element in modules; narrow elements are one module
+const code39Wide = 3
+
+// encodeCode39 encodes upper-case letters, digits and - . space $ / + %. Lower-case letters
+// are upper-cased, as Code 39 has none.
+func encodeCode39(text string) (Barcode, error) {
+	text = strings.ToUpper(text)
+	for _, r := range text {
+		if _, ok := code39Patterns[r]; !ok || r == '*' {
+			return Barcode{}, fmt.Errorf("code39 cannot encode %q", r)
+		}
+	}
+
+	var widths []int
+	for _, r := range "*" + text + "*" {
+		pattern := code39Patterns[r]
+		if len(widths) > 0 {
+			// Narrow gap between characters
+			widths = append(widths, 1)
+		}
+		for _, element := range pattern {
+			if element == 'w' {
+				widths = append(widths, code39Wide)
+			} else {
+				widths = append(widths, 1)
+			}
+		}
+	}
+	return Barcode{Symbology: SymbologyCode39, Text: text, Modules: linearModules(widths), QuietZone: 10}, nil
+}
@@ -0,0 +1,82 @@
+package labels
+
+import (
+	"reflect"
+	"testing"
+)
+
+// code39Reference are characters in the usual binary rendering of Code 39, one digit per module
+// with wide elements two modules wide
+var code39Reference = map[rune]string{
+	'*': "100101101101",
+	'0': "101001101101",
+	'1': "110100101011",
+	'2': "101100101011",
+	'9': "101100101101",
+	'A': "110101001011",
+}
+
+func TestCode39(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"A", "A"},
+		{"a19", "A19"},
+		{"20", "20"},
+	}
+	for _, tt := range tests {
+		code, err := encodeCode39(tt.text)
+		if err != nil {
+			t.Fatalf("encodeCode39(%q): %v", tt.text, err)
+		}
+		if code.Text != tt.want {
+			t.Errorf("encodeCode39(%q) text = %q, want %q", tt.text, code.Text, tt.want)
+		}
+
+		var want []int
+		for i, r := range "*" + tt.want + "*" {
+			if i > 0 {
+				want = append(want, 1)
+			}
+			for _, w := range moduleWidths(referenceModules(code39Reference[r])) {
+				if w == 2 {
+					w = code39Wide
+				}
+				want = append(want, w)
+			}
+		}
+		if got := moduleWidths(code.Modules[0]); !reflect.DeepEqual(got, want) {
+			t.Errorf("encodeCode39(%q) = %v, want %v", tt.text, got, want)
+		}
+	}
+}
+
+func TestCode39Patterns(t *testing.T) {
+	for r, pattern := range code39Patterns {
+		wide := 0
+		for _, element := range pattern {
+			if element == 'w' {
+				wide++
+			}
+		}
+		if len(pattern) != 9 || wide != 3 {
+			t.Errorf("%q: pattern %s should have nine elements, three of them wide", r, pattern)
+		}
+	}
+}
+
+func TestCode39Rejects(t *testing.T) {
+	for _, text := range []string{"A*B", "A_B", "#1"} {
+		if _, err := encodeCode39(text); err == nil {
+			t.Errorf("encodeCode39(%q): expected an error", text)
+		}
+	}
+}
+
+func referenceModules(binary string) []bool {
+	row := make([]bool, len(binary))
+	for i, c := range binary {
+		row[i] = c == '1'
+	}
+	return row
+}
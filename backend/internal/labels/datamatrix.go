@@ -0,0 +1,267 @@
+package labels
+
+import (
+	"fmt"
+)
+
+// dataMatrixSize is an ECC 200 square symbol size. Symbols from 32 x 32 are split into 2 x 2
+// data regions, each surrounded by its own finder pattern.
+type dataMatrixSize struct {
+	size       int
+	regions    int
+	dataWords  int
+	errorWords int
+}
+
+// dataMatrixSizes are the square symbols that use a single Reed-Solomon block
+var dataMatrixSizes = []dataMatrixSize{
+	{10, 1, 3, 5}, {12, 1, 5, 7}, {14, 1, 8, 10}, {16, 1, 12, 12}, {18, 1, 18, 14},
+	{20, 1, 22, 18}, {22, 1, 30, 20}, {24, 1, 36, 24}, {26, 1, 44, 28}, {32, 2, 62, 36},
+	{36, 2, 86, 42}, {40, 2, 114, 48}, {44, 2, 144, 56}, {48, 2, 174, 68},
+}
+
+// encodeDataMatrix encodes text as an ECC 200 DataMatrix in the smallest square symbol that
+// holds it
+func encodeDataMatrix(text string) (Barcode, error) {
+	data := dataMatrixASCII([]byte(text))
+
+	var size dataMatrixSize
+	for _, candidate := range dataMatrixSizes {
+		if candidate.dataWords >= len(data) {
+			size = candidate
+			break
+		}
+	}
+	if size.size == 0 {
+		return Barcode{}, fmt.Errorf("text is too long for a datamatrix")
+	}
+
+	// The first pad codeword is 129; later ones are scrambled by position so that padding does
+	// not form a visible pattern
+	if len(data) < size.dataWords {
+		data = append(data, 129)
+	}
+	for len(data) < size.dataWords {
+		pad := 129 + (149*(len(data)+1))%253 + 1
+		if pad > 254 {
+			pad -= 254
+		}
+		data = append(data, byte(pad))
+	}
+	codewords := append(data, reedSolomon(data, size.errorWords)...)
+
+	return Barcode{
+		Symbology: SymbologyDataMatrix,
+		Text:      text,
+		Modules:   dataMatrixModules(size, codewords),
+		QuietZone: 1,
+	}, nil
+}
+
+// dataMatrixASCII applies ASCII encodation: pairs of digits share a codeword, other bytes take
+// one, and bytes above 127 are preceded by an upper shift
+func dataMatrixASCII(text []byte) []byte {
+	var data []byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case isDigit(c) && i+1 < len(text) && isDigit(text[i+1]):
+			data = append(data, 130+(c-'0')*10+(text[i+1]-'0'))
+			i++
+		case c > 127:
+			data = append(data, 235, c-127)
+		default:
+			data = append(data, c+1)
+		}
+	}
+	return data
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// gfExp and gfLog are the antilog and log tables of GF(256) with the DataMatrix field
+// polynomial x^8 + x^5 + x^3 + x^2 + 1
+var gfExp, gfLog = func() ([255]byte, [256]byte) {
+	var exp [255]byte
+	var log [256]byte
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = byte(x)
+		log[x] = byte(i)
+		x <<= 1
+		if x >= 256 {
+			x ^= 0x12d
+		}
+	}
+	return exp, log
+}()
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[(int(gfLog[a])+int(gfLog[b]))%255]
+}
+
+// reedSolomon returns n error correction codewords for data
+func reedSolomon(data []byte, n int) []byte {
+	// The generator polynomial has roots a^1 to a^n; its coefficients run from x^n down
+	generator := []byte{1}
+	for i := 1; i <= n; i++ {
+		next := make([]byte, len(generator)+1)
+		for j, coefficient := range generator {
+			next[j] ^= coefficient
+			next[j+1] ^= gfMul(coefficient, gfExp[i])
+		}
+		generator = next
+	}
+
+	ecc := make([]byte, n)
+	for _, d := range data {
+		factor := d ^ ecc[0]
+		copy(ecc, ecc[1:])
+		ecc[n-1] = 0
+		for i := range ecc {
+			ecc[i] ^= gfMul(generator[i+1], factor)
+		}
+	}
+	return ecc
+}
+
+// dataMatrixModules places codewords in the symbol and adds the finder patterns
+func dataMatrixModules(size dataMatrixSize, codewords []byte) [][]bool {
+	regionSize := size.size/size.regions - 2
+	mappingSize := regionSize * size.regions
+	placement := dataMatrixPlacement(mappingSize, mappingSize)
+
+	modules := make([][]bool, size.size)
+	for row := range modules {
+		modules[row] = make([]bool, size.size)
+	}
+
+	for row := 0; row < mappingSize; row++ {
+		for col := 0; col < mappingSize; col++ {
+			value := placement[row*mappingSize+col]
+			dark := value == 1
+			if value >= 10 {
+				codeword, bit := value/10-1, value%10
+				dark = codewords[codeword]>>(8-bit)&1 == 1
+			}
+			// Skip the finder pattern around each region
+			symbolRow := row/regionSize*(regionSize+2) + row%regionSize + 1
+			symbolCol := col/regionSize*(regionSize+2) + col%regionSize + 1
+			modules[symbolRow][symbolCol] = dark
+		}
+	}
+
+	// Each region has a solid left and bottom edge, and alternating top and right edges
+	step := regionSize + 2
+	for top := 0; top < size.size; top += step {
+		for left := 0; left < size.size; left += step {
+			for i := 0; i < step; i++ {
+				modules[top+i][left] = true
+				modules[top+step-1][left+i] = true
+				modules[top][left+i] = i%2 == 0
+				modules[top+i][left+step-1] = i%2 == 1
+			}
+		}
+	}
+	return modules
+}
+
+// dataMatrixPlacement follows the ECC 200 placement algorithm, returning for each module of the
+// mapping matrix 10 * codeword number + bit number (1 being the most significant bit), or 1
+// for the fixed dark modules that fill an unused corner
+func dataMatrixPlacement(nrow, ncol int) []int {
+	array := make([]int, nrow*ncol)
+
+	module := func(row, col, chr, bit int) {
+		if row < 0 {
+			row += nrow
+			col += 4 - (nrow+4)%8
+		}
+		if col < 0 {
+			col += ncol
+			row += 4 - (ncol+4)%8
+		}
+		array[row*ncol+col] = 10*chr + bit
+	}
+	utah := func(row, col, chr int) {
+		module(row-2, col-2, chr, 1)
+		module(row-2, col-1, chr, 2)
+		module(row-1, col-2, chr, 3)
+		module(row-1, col-1, chr, 4)
+		module(row-1, col, chr, 5)
+		module(row, col-2, chr, 6)
+		module(row, col-1, chr, 7)
+		module(row, col, chr, 8)
+	}
+	// corner places a codeword whose eight bits are split by the edges of the matrix
+	corner := func(chr int, positions [8][2]int) {
+		for i, p := range positions {
+			module(p[0], p[1], chr, i+1)
+		}
+	}
+
+	chr, row, col := 1, 4, 0
+	for {
+		if row == nrow && col == 0 {
+			corner(chr, [8][2]int{{nrow - 1, 0}, {nrow - 1, 1}, {nrow - 1, 2}, {0, ncol - 2}, {0, ncol - 1}, {1, ncol - 1}, {2, ncol - 1}, {3, ncol - 1}})
+			chr++
+		}
+		if row == nrow-2 && col == 0 && ncol%4 != 0 {
+			corner(chr, [8][2]int{{nrow - 3, 0}, {nrow - 2, 0}, {nrow - 1, 0}, {0, ncol - 4}, {0, ncol - 3}, {0, ncol - 2}, {0, ncol - 1}, {1, ncol - 1}})
+			chr++
+		}
+		if row == nrow-2 && col == 0 && ncol%8 == 4 {
+			corner(chr, [8][2]int{{nrow - 3, 0}, {nrow - 2, 0}, {nrow - 1, 0}, {0, ncol - 2}, {0, ncol - 1}, {1, ncol - 1}, {2, ncol - 1}, {3, ncol - 1}})
+			chr++
+		}
+		if row == nrow+4 && col == 2 && ncol%8 == 0 {
+			corner(chr, [8][2]int{{nrow - 1, 0}, {nrow - 1, ncol - 1}, {0, ncol - 3}, {0, ncol - 2}, {0, ncol - 1}, {1, ncol - 3}, {1, ncol - 2}, {1, ncol - 1}})
+			chr++
+		}
+
+		// Sweep up and to the right
+		for {
+			if row < nrow && col >= 0 && array[row*ncol+col] == 0 {
+				utah(row, col, chr)
+				chr++
+			}
+			row -= 2
+			col += 2
+			if row < 0 || col >= ncol {
+				break
+			}
+		}
+		row++
+		col += 3
+
+		// Sweep down and to the left
+		for {
+			if row >= 0 && col < ncol && array[row*ncol+col] == 0 {
+				utah(row, col, chr)
+				chr++
+			}
+			row += 2
+			col -= 2
+			if row >= nrow || col < 0 {
+				break
+			}
+		}
+		row += 3
+		col++
+
+		if row >= nrow && col >= ncol {
+			break
+		}
+	}
+
+	if array[nrow*ncol-1] == 0 {
+		array[nrow*ncol-1] = 1
+		array[nrow*ncol-ncol-2] = 1
+	}
+	return array
+}
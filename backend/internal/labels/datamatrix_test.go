@@ -0,0 +1,155 @@
+package labels
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDataMatrixASCII(t *testing.T) {
+	tests := []struct {
+		text string
+		want []byte
+	}{
+		{"123456", []byte{142, 164, 186}},
+		{"12345", []byte{142, 164, 54}},
+		{"A1B", []byte{66, 50, 67}},
+		{"ASSET-0042", []byte{66, 84, 84, 70, 85, 46, 130, 172}},
+		{"é", []byte{235, 68, 235, 42}},
+	}
+	for _, tt := range tests {
+		if got := dataMatrixASCII([]byte(tt.text)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("dataMatrixASCII(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+// The codewords of "123456" in a 10 x 10 symbol, from the worked example of ISO/IEC 16022
+func TestReedSolomonISOExample(t *testing.T) {
+	data := []byte{142, 164, 186}
+	want := []byte{114, 25, 5, 88, 102}
+	if got := reedSolomon(data, 5); !reflect.DeepEqual(got, want) {
+		t.Errorf("reedSolomon(%v, 5) = %v, want %v", data, got, want)
+	}
+}
+
+// A codeword block is a multiple of the generator, so it vanishes at each of the generator's
+// roots a^1 to a^n
+func TestReedSolomonRoots(t *testing.T) {
+	for _, size := range dataMatrixSizes {
+		data := make([]byte, size.dataWords)
+		for i := range data {
+			data[i] = byte(i*37 + size.size)
+		}
+		block := append(data, reedSolomon(data, size.errorWords)...)
+		for root := 1; root <= size.errorWords; root++ {
+			var value byte
+			for _, codeword := range block {
+				value = gfMul(value, gfExp[root]) ^ codeword
+			}
+			if value != 0 {
+				t.Errorf("%d x %d: block evaluates to %d at a^%d", size.size, size.size, value, root)
+			}
+		}
+	}
+}
+
+// Every bit of every codeword is placed exactly once, the codewords placed are exactly those
+// the symbol holds, and only the unused corner of sizes that have one is left without a bit
+func TestDataMatrixPlacement(t *testing.T) {
+	for _, size := range dataMatrixSizes {
+		mappingSize := (size.size/size.regions - 2) * size.regions
+		placement := dataMatrixPlacement(mappingSize, mappingSize)
+
+		seen := map[int]bool{}
+		codewords := 0
+		for i, value := range placement {
+			if value < 10 {
+				if row, col := i/mappingSize, i%mappingSize; row < mappingSize-2 || col < mappingSize-2 {
+					t.Errorf("%d x %d: module %d,%d left without a bit", size.size, size.size, row, col)
+				}
+				continue
+			}
+			if seen[value] {
+				t.Errorf("%d x %d: codeword %d bit %d placed twice", size.size, size.size, value/10, value%10)
+			}
+			seen[value] = true
+			if value/10 > codewords {
+				codewords = value / 10
+			}
+		}
+		if want := size.dataWords + size.errorWords; codewords != want || len(seen) != 8*want {
+			t.Errorf("%d x %d: placed %d bits of %d codewords, want %d codewords", size.size, size.size, len(seen), codewords, want)
+		}
+	}
+}
+
+func TestDataMatrixSymbol(t *testing.T) {
+	code, err := encodeDataMatrix("123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"#.#.#.#.#.",
+		"##..#.##.#",
+		"##.....#..",
+		"##...###.#",
+		"##....#...",
+		"#.....####",
+		"###.##....",
+		"####.##..#",
+		"#..###.#..",
+		"##########",
+	}
+	if got := matrixRows(code.Modules); !reflect.DeepEqual(got, want) {
+		t.Errorf("123456:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// Each region of a symbol has a solid left and bottom edge and alternating top and right edges
+func TestDataMatrixFinderPattern(t *testing.T) {
+	for _, size := range dataMatrixSizes {
+		code, err := encodeDataMatrix(strings.Repeat("7", 2*size.dataWords))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(code.Modules) != size.size {
+			t.Fatalf("%d digit pairs: got a %d x %d symbol, want %d x %d", size.dataWords, len(code.Modules), len(code.Modules), size.size, size.size)
+		}
+		step := size.size / size.regions
+		for top := 0; top < size.size; top += step {
+			for left := 0; left < size.size; left += step {
+				for i := 0; i < step; i++ {
+					if !code.Modules[top+i][left] || !code.Modules[top+step-1][left+i] {
+						t.Errorf("%d x %d: solid edge of region at %d,%d broken at %d", size.size, size.size, top, left, i)
+					}
+					if code.Modules[top][left+i] != (i%2 == 0) || code.Modules[top+i][left+step-1] != (i%2 == 1) {
+						t.Errorf("%d x %d: alternating edge of region at %d,%d broken at %d", size.size, size.size, top, left, i)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDataMatrixTooLong(t *testing.T) {
+	if _, err := encodeDataMatrix(strings.Repeat("A", 175)); err == nil {
+		t.Error("expected an error for text longer than the largest symbol")
+	}
+}
+
+func matrixRows(modules [][]bool) []string {
+	rows := make([]string, len(modules))
+	for i, row := range modules {
+		var b strings.Builder
+		for _, dark := range row {
+			if dark {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		rows[i] = b.String()
+	}
+	return rows
+}
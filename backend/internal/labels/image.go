@@ -0,0 +1,128 @@
+package labels
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	"image/draw"
+	"image/png"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// ImageOptions control how a barcode is rendered as an image. Sizes are in pixels.
+type ImageOptions struct {
+	// Scale is the width of one module
+	Scale int
+	// BarHeight is the height of the bars of a linear barcode
+	BarHeight int
+	// Text prints the human-readable text below the symbol
+	Text bool
+}
+
+// imageGeometry is the placement of a barcode and its text in an image
+type imageGeometry struct {
+	width, height int
+	quiet         int
+	symbolHeight  int
+	// textScale enlarges the 7 x 13 pixel font to suit the module size
+	textScale int
+	textTop   int
+}
+
+func geometry(code Barcode, opts ImageOptions) imageGeometry {
+	g := imageGeometry{quiet: code.QuietZone * opts.Scale, textScale: max(1, opts.Scale/2)}
+	g.symbolHeight = len(code.Modules) * opts.Scale
+	if code.Linear() {
+		g.symbolHeight = opts.BarHeight
+	}
+	g.width = code.Width()*opts.Scale + 2*g.quiet
+	g.height = g.symbolHeight + 2*g.quiet
+	if opts.Text && code.Text != "" {
+		g.textTop = g.quiet + g.symbolHeight + g.textScale*2
+		g.height = g.textTop + basicfont.Face7x13.Height*g.textScale + g.quiet
+		textWidth := len([]rune(code.Text))*basicfont.Face7x13.Advance*g.textScale + 2*g.quiet
+		g.width = max(g.width, textWidth)
+	}
+	return g
+}
+
+// PNG renders a barcode as a black and white PNG image
+func PNG(code Barcode, opts ImageOptions) ([]byte, error) {
+	g := geometry(code, opts)
+	img := image.NewGray(image.Rect(0, 0, g.width, g.height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	left := (g.width - code.Width()*opts.Scale) / 2
+	moduleHeight := opts.Scale
+	if code.Linear() {
+		moduleHeight = g.symbolHeight
+	}
+	for y, row := range code.Modules {
+		runs(row, func(start, length int) {
+			rect := image.Rect(left+start*opts.Scale, g.quiet+y*moduleHeight,
+				left+(start+length)*opts.Scale, g.quiet+(y+1)*moduleHeight)
+			draw.Draw(img, rect, image.Black, image.Point{}, draw.Src)
+		})
+	}
+
+	if g.textTop > 0 {
+		drawText(img, code.Text, g)
+	}
+
+	out := &bytes.Buffer{}
+	if err := png.Encode(out, img); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// drawText draws the human-readable text centred below the symbol, rendering it at the font's
+// own size and enlarging it pixel by pixel
+func drawText(img *image.Gray, text string, g imageGeometry) {
+	face := basicfont.Face7x13
+	width := font.MeasureString(face, text).Ceil()
+	small := image.NewGray(image.Rect(0, 0, width, face.Height))
+	draw.Draw(small, small.Bounds(), image.White, image.Point{}, draw.Src)
+	drawer := font.Drawer{Dst: small, Src: image.Black, Face: face, Dot: fixed.P(0, face.Ascent)}
+	drawer.DrawString(text)
+
+	left := (g.width - width*g.textScale) / 2
+	for y := 0; y < face.Height*g.textScale; y++ {
+		for x := 0; x < width*g.textScale; x++ {
+			img.SetGray(left+x, g.textTop+y, small.GrayAt(x/g.textScale, y/g.textScale))
+		}
+	}
+}
+
+// SVG renders a barcode as an SVG image
+func SVG(code Barcode, opts ImageOptions) []byte {
+	g := geometry(code, opts)
+	left := (g.width - code.Width()*opts.Scale) / 2
+	moduleHeight := opts.Scale
+	if code.Linear() {
+		moduleHeight = g.symbolHeight
+	}
+
+	out := &bytes.Buffer{}
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		g.width, g.height, g.width, g.height)
+	fmt.Fprintf(out, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, g.width, g.height)
+	for y, row := range code.Modules {
+		runs(row, func(start, length int) {
+			fmt.Fprintf(out, "M%d %dh%dv%dh-%dz", left+start*opts.Scale, g.quiet+y*moduleHeight,
+				length*opts.Scale, moduleHeight, length*opts.Scale)
+		})
+	}
+	out.WriteString(`"/>`)
+	if g.textTop > 0 {
+		size := basicfont.Face7x13.Height * g.textScale
+		fmt.Fprintf(out, `<text x="%d" y="%d" font-family="monospace" font-size="%d" text-anchor="middle">%s</text>`,
+			g.width/2, g.textTop+basicfont.Face7x13.Ascent*g.textScale, size, html.EscapeString(code.Text))
+	}
+	out.WriteString(`</svg>`)
+	return out.Bytes()
+}
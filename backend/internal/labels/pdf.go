@@ -0,0 +1,190 @@
+package labels
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// pointsPerMM converts millimetres to PDF points
+const pointsPerMM = 72 / 25.4
+
+// document is a minimal PDF writer. Label sheets only need filled rectangles and single lines
+// of text, so it supports just those, using the standard Helvetica fonts that every PDF reader
+// provides without embedding.
+type document struct {
+	// Page size in points
+	width, height float64
+	pages         []*bytes.Buffer
+}
+
+func newDocument(widthMM, heightMM float64) *document {
+	return &document{width: widthMM * pointsPerMM, height: heightMM * pointsPerMM}
+}
+
+func (d *document) addPage() {
+	page := &bytes.Buffer{}
+	// Fill with black
+	page.WriteString("0 g\n")
+	d.pages = append(d.pages, page)
+}
+
+func (d *document) current() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.addPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// rect fills a rectangle positioned in millimetres from the top-left corner of the page
+func (d *document) rect(x, y, w, h float64) {
+	fmt.Fprintf(d.current(), "%.3f %.3f %.3f %.3f re f\n",
+		x*pointsPerMM, d.height-(y+h)*pointsPerMM, w*pointsPerMM, h*pointsPerMM)
+}
+
+// text draws a line of text with its baseline y millimetres from the top of the page. The size
+// is in points.
+func (d *document) text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.current(), "BT /%s %.2f Tf %.3f %.3f Td (%s) Tj ET\n",
+		font, size, x*pointsPerMM, d.height-y*pointsPerMM, escapeText(s))
+}
+
+// bytes assembles the PDF file
+func (d *document) bytes() []byte {
+	if len(d.pages) == 0 {
+		d.addPage()
+	}
+
+	// Objects 1 to 4 are the catalog, page tree and fonts; each page then takes two objects,
+	// the page and its content stream
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	}
+	kids := make([]string, len(d.pages))
+	for i, page := range d.pages {
+		pageObject := len(objects) + 1
+		kids[i] = fmt.Sprintf("%d 0 R", pageObject)
+
+		content := &bytes.Buffer{}
+		w := zlib.NewWriter(content)
+		w.Write(page.Bytes())
+		w.Close()
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.3f %.3f] "+
+				"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				d.width, d.height, pageObject+1),
+			fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", content.Len(), content.String()),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages))
+
+	out := &bytes.Buffer{}
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := out.Len()
+	fmt.Fprintf(out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+// encodeText converts text to the WinAnsi encoding used by the fonts. Characters it cannot
+// represent are replaced with a question mark.
+func encodeText(s string) []byte {
+	encoded := make([]byte, 0, len(s))
+	for _, r := range s {
+		b, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok {
+			b = '?'
+		}
+		encoded = append(encoded, b)
+	}
+	return encoded
+}
+
+// escapeText encodes text as the contents of a PDF string literal
+func escapeText(s string) string {
+	var sb strings.Builder
+	for _, b := range encodeText(s) {
+		switch {
+		case b == '(' || b == ')' || b == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(b)
+		case b < 0x20 || b > 0x7e:
+			fmt.Fprintf(&sb, "\\%03o", b)
+		default:
+			sb.WriteByte(b)
+		}
+	}
+	return sb.String()
+}
+
+// helveticaWidths and helveticaBoldWidths are the advance widths, in thousandths of the font
+// size, of the printable ASCII characters from space to tilde
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// textWidth returns the width of text in millimetres at the given size in points. Characters
+// outside ASCII are measured as a digit, which is close for accented letters.
+func textWidth(s string, size float64, bold bool) float64 {
+	widths := &helveticaWidths
+	if bold {
+		widths = &helveticaBoldWidths
+	}
+	total := 0
+	for _, b := range encodeText(s) {
+		if b >= 0x20 && b <= 0x7e {
+			total += widths[b-0x20]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) / 1000 * size / pointsPerMM
+}
+
+// fitText shortens text with an ellipsis until it fits within width millimetres
+func fitText(s string, size float64, bold bool, width float64) string {
+	if textWidth(s, size, bold) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		shortened := strings.TrimRight(string(runes), " ") + "..."
+		if textWidth(shortened, size, bold) <= width {
+			return shortened
+		}
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+// Package labels renders the codes and printable label sheets used to tag assets. QR codes are
+// produced as PNG or SVG images, and label sheets as PDF documents laid out for standard
+// Avery-style label stock.
+package labels
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
+)
+
+// DefaultLinkTemplate is the deep link encoded in asset QR codes when none is configured
+const DefaultLinkTemplate = "http://localhost:3000/assets/{id}"
+
+// CheckLinkTemplate reports a link template without {id}, which would give every asset the same
+// QR code
+func CheckLinkTemplate(template string) error {
+	if !strings.Contains(template, "{id}") {
+		return fmt.Errorf("%q has no {id} placeholder for the asset ID", template)
+	}
+	return nil
+}
+
+// DeepLink substitutes an asset ID for {id} in a link template
+func DeepLink(template string, id uuid.UUID) string {
+	if template == "" {
+		template = DefaultLinkTemplate
+	}
+	return strings.ReplaceAll(template, "{id}", id.String())
+}
+
+// ParseLevel parses a QR error correction level: L, M, Q or H, which restore about 7, 15, 25
+// and 30 percent of a damaged code
+func ParseLevel(s string) (qrcode.RecoveryLevel, error) {
+	switch strings.ToUpper(s) {
+	case "L":
+		return qrcode.Low, nil
+	case "", "M":
+		return qrcode.Medium, nil
+	case "Q":
+		return qrcode.High, nil
+	case "H":
+		return qrcode.Highest, nil
+	}
+	return qrcode.Medium, fmt.Errorf("invalid error correction level %q: use L, M, Q or H", s)
+}
+
+// QRPNG renders content as a square PNG QR code of size pixels
+func QRPNG(content string, level qrcode.RecoveryLevel, size int) ([]byte, error) {
+	return qrcode.Encode(content, level, size)
+}
+
+// QRSVG renders content as a square SVG QR code of size pixels
+func QRSVG(content string, level qrcode.RecoveryLevel, size int) ([]byte, error) {
+	code, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, err
+	}
+	bitmap := code.Bitmap()
+	modules := len(bitmap)
+
+	out := &bytes.Buffer{}
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		size, size, modules, modules)
+	fmt.Fprintf(out, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, modules, modules)
+	// Each run of dark modules in a row is one rectangle
+	for y, row := range bitmap {
+		runs(row, func(start, length int) {
+			fmt.Fprintf(out, "M%d %dh%dv1h-%dz", start, y, length, length)
+		})
+	}
+	out.WriteString(`"/></svg>`)
+	return out.Bytes(), nil
+}
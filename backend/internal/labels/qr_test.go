@@ -0,0 +1,33 @@
+package labels
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestDeepLink(t *testing.T) {
+	id := uuid.MustParse("6f1c2a9e-3b5d-4c8e-9f0a-1b2c3d4e5f60")
+	tests := []struct {
+		template, want string
+	}{
+		{"", "http://localhost:3000/assets/6f1c2a9e-3b5d-4c8e-9f0a-1b2c3d4e5f60"},
+		{"https://assets.example.com/a/{id}?scan=1", "https://assets.example.com/a/6f1c2a9e-3b5d-4c8e-9f0a-1b2c3d4e5f60?scan=1"},
+	}
+	for _, tt := range tests {
+		if got := DeepLink(tt.template, id); got != tt.want {
+			t.Errorf("DeepLink(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestCheckLinkTemplate(t *testing.T) {
+	if err := CheckLinkTemplate(DefaultLinkTemplate); err != nil {
+		t.Errorf("default template: %v", err)
+	}
+	for _, template := range []string{"https://assets.example.com/a/", "https://assets.example.com/a/{ID}"} {
+		if err := CheckLinkTemplate(template); err == nil {
+			t.Errorf("CheckLinkTemplate(%q): expected an error", template)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package labels
+
+import (
+	"math"
+	"strings"
+)
+
+// Layout describes a sheet of label stock. Dimensions are in millimetres.
+type Layout struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	PageWidth   float64 `json:"page_width"`
+	PageHeight  float64 `json:"page_height"`
+	TopMargin   float64 `json:"top_margin"`
+	LeftMargin  float64 `json:"left_margin"`
+	LabelWidth  float64 `json:"label_width"`
+	LabelHeight float64 `json:"label_height"`
+	// HorizontalPitch and VerticalPitch are the distances between the same edge of neighbouring
+	// labels, so they include the gap between labels
+	HorizontalPitch float64 `json:"horizontal_pitch"`
+	VerticalPitch   float64 `json:"vertical_pitch"`
+	Columns         int     `json:"columns"`
+	Rows            int     `json:"rows"`
+}
+
+// PerPage returns the number of labels on a sheet
+func (l Layout) PerPage() int {
+	return l.Columns * l.Rows
+}
+
+// DefaultLayout is used when no layout is requested
+const DefaultLayout = "L7160"
+
+// Layouts are the supported label stocks: Avery A4 sheets (L codes) and US Letter sheets
+var Layouts = []Layout{
+	{Name: "L7160", Description: "A4, 21 labels of 63.5 x 38.1 mm", PageWidth: 210, PageHeight: 297,
+		TopMargin: 15.15, LeftMargin: 7.2, LabelWidth: 63.5, LabelHeight: 38.1,
+		HorizontalPitch: 66.04, VerticalPitch: 38.1, Columns: 3, Rows: 7},
+	{Name: "L7159", Description: "A4, 24 labels of 63.5 x 33.9 mm", PageWidth: 210, PageHeight: 297,
+		TopMargin: 12.9, LeftMargin: 7.25, LabelWidth: 63.5, LabelHeight: 33.9,
+		HorizontalPitch: 66.0, VerticalPitch: 33.9, Columns: 3, Rows: 8},
+	{Name: "L7163", Description: "A4, 14 labels of 99.1 x 38.1 mm", PageWidth: 210, PageHeight: 297,
+		TopMargin: 15.15, LeftMargin: 4.65, LabelWidth: 99.1, LabelHeight: 38.1,
+		HorizontalPitch: 101.6, VerticalPitch: 38.1, Columns: 2, Rows: 7},
+	{Name: "L7651", Description: "A4, 65 labels of 38.1 x 21.2 mm", PageWidth: 210, PageHeight: 297,
+		TopMargin: 10.7, LeftMargin: 4.75, LabelWidth: 38.1, LabelHeight: 21.2,
+		HorizontalPitch: 40.6, VerticalPitch: 21.2, Columns: 5, Rows: 13},
+	{Name: "5160", Description: "US Letter, 30 labels of 2.625 x 1 in", PageWidth: 215.9, PageHeight: 279.4,
+		TopMargin: 12.7, LeftMargin: 4.76, LabelWidth: 66.68, LabelHeight: 25.4,
+		HorizontalPitch: 69.85, VerticalPitch: 25.4, Columns: 3, Rows: 10},
+	{Name: "5163", Description: "US Letter, 10 labels of 4 x 2 in", PageWidth: 215.9, PageHeight: 279.4,
+		TopMargin: 12.7, LeftMargin: 3.97, LabelWidth: 101.6, LabelHeight: 50.8,
+		HorizontalPitch: 104.78, VerticalPitch: 50.8, Columns: 2, Rows: 5},
+}
+
+// LookupLayout returns the layout with the given name, ignoring case
+func LookupLayout(name string) (Layout, bool) {
+	for _, layout := range Layouts {
+		if strings.EqualFold(layout.Name, name) {
+			return layout, true
+		}
+	}
+	return Layout{}, false
+}
+
+// Label is the content printed on one label. Square codes are printed beside the bold title and
+// detail lines; linear barcodes run across the label below the title, above their human-readable
+// text, and leave no room for detail lines.
+type Label struct {
+	Code  Barcode
+	Title string
+	Lines []string
+}
+
+// Sheet renders labels as a PDF on pages of the layout. The first skip positions are left
+// blank so that a partly used sheet can be fed through again.
+func Sheet(layout Layout, items []Label, skip int) []byte {
+	doc := newDocument(layout.PageWidth, layout.PageHeight)
+	perPage := layout.PerPage()
+	skip %= perPage
+
+	for i, item := range items {
+		position := skip + i
+		if i == 0 || position%perPage == 0 {
+			doc.addPage()
+		}
+		col := position % perPage % layout.Columns
+		row := position % perPage / layout.Columns
+		x := layout.LeftMargin + float64(col)*layout.HorizontalPitch
+		y := layout.TopMargin + float64(row)*layout.VerticalPitch
+		if item.Code.Linear() {
+			drawLinearLabel(doc, layout, item, x, y)
+		} else {
+			drawSquareLabel(doc, layout, item, x, y)
+		}
+	}
+	return doc.bytes()
+}
+
+// labelPadding is the blank margin inside a label, in millimetres
+func labelPadding(layout Layout) float64 {
+	return math.Min(2, layout.LabelHeight*0.08)
+}
+
+// drawSquareLabel draws a label with a two-dimensional code, top-left corner at x, y
+func drawSquareLabel(doc *document, layout Layout, item Label, x, y float64) {
+	padding := labelPadding(layout)
+	side := layout.LabelHeight - 2*padding
+	modules := float64(item.Code.Width() + 2*item.Code.QuietZone)
+	module := side / modules
+	quiet := float64(item.Code.QuietZone) * module
+	drawModules(doc, item.Code, x+padding+quiet, y+padding+quiet, module, module)
+
+	textX := x + side + 2*padding
+	textArea := layout.LabelWidth - side - 3*padding
+	lines := 1 + len(item.Lines)
+	// Lines share the height beside the code, at most 5 mm each
+	lineHeight := math.Min(side/float64(lines), 5)
+	size := lineHeight * pointsPerMM * 0.8
+	top := y + padding + (side-lineHeight*float64(lines))/2
+
+	for i := 0; i < lines; i++ {
+		text, bold := item.Title, true
+		if i > 0 {
+			text, bold = item.Lines[i-1], false
+		}
+		baseline := top + lineHeight*float64(i+1) - lineHeight*0.25
+		doc.text(textX, baseline, size, bold, fitText(text, size, bold, textArea))
+	}
+}
+
+// drawLinearLabel draws a label with a linear barcode, top-left corner at x, y
+func drawLinearLabel(doc *document, layout Layout, item Label, x, y float64) {
+	padding := labelPadding(layout)
+	width := layout.LabelWidth - 2*padding
+	height := layout.LabelHeight - 2*padding
+	// The title and the human-readable text each take a fifth of the height, at most 5 mm
+	lineHeight := math.Min(height/5, 5)
+	size := lineHeight * pointsPerMM * 0.8
+
+	baseline := y + padding + lineHeight*0.75
+	doc.text(x+padding, baseline, size, true, fitText(item.Title, size, true, width))
+
+	module := width / float64(item.Code.Width()+2*item.Code.QuietZone)
+	barHeight := height - 2*lineHeight
+	drawModules(doc, item.Code, x+padding+float64(item.Code.QuietZone)*module, y+padding+lineHeight, module, barHeight)
+
+	text := fitText(item.Code.Text, size, false, width)
+	textX := x + (layout.LabelWidth-textWidth(text, size, false))/2
+	doc.text(textX, y+padding+height-lineHeight*0.25, size, false, text)
+}
+
+// drawModules draws the dark modules of a barcode from its top-left corner, each moduleWidth by
+// moduleHeight millimetres
+func drawModules(doc *document, code Barcode, x, y, moduleWidth, moduleHeight float64) {
+	for row, cells := range code.Modules {
+		runs(cells, func(start, length int) {
+			doc.rect(x+float64(start)*moduleWidth, y+float64(row)*moduleHeight, float64(length)*moduleWidth, moduleHeight)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"sams-backend/internal/models"
+)
+
+// Asset lifecycle statuses
+const (
+	StatusActive      = "active"
+	StatusInactive    = "inactive"
+	StatusMaintenance = "maintenance"
+	StatusDisposed    = "disposed"
+)
+
+// transitions lists the statuses an asset may move to from each status; disposed is final
+var transitions = map[string][]string{
+	StatusActive:      {StatusInactive, StatusMaintenance, StatusDisposed},
+	StatusInactive:    {StatusActive, StatusMaintenance, StatusDisposed},
+	StatusMaintenance: {StatusActive, StatusInactive, StatusDisposed},
+	StatusDisposed:    {},
+}
+
+// reasonRequired lists the target statuses that must be justified with a reason
+var reasonRequired = map[string]bool{
+	StatusInactive: true,
+	StatusDisposed: true,
+}
+
+// workflowOnly lists the target statuses that can only be reached through a dedicated workflow,
+// such as an approved disposal request
+var workflowOnly = map[string]bool{
+	StatusDisposed: true,
+}
+
+// TransitionError describes a transition that is not allowed
+type TransitionError struct {
+	From    string
+	To      string
+	Message string
+}
+
+func (e *TransitionError) Error() string {
+	return e.Message
+}
+
+// ErrConflict is returned by Transition when the asset's status or version changed after it was
+// loaded, so the transition was validated against a stale row
+var ErrConflict = errors.New("asset was changed by another request")
+
+// AllowedTransitions returns the statuses an asset in the given status may move to
+func AllowedTransitions(from string) []string {
+	allowed := transitions[from]
+	if allowed == nil {
+		return []string{}
+	}
+	return allowed
+}
+
+// RequiresReason reports whether moving to the given status needs a reason
+func RequiresReason(to string) bool {
+	return reasonRequired[to]
+}
+
+// RequiresWorkflow reports whether moving to the given status must go through a dedicated workflow
+func RequiresWorkflow(to string) bool {
+	return workflowOnly[to]
+}
+
+// Validate checks that an asset may move from one status to another with the given reason
+func Validate(from, to, reason string) error {
+	if _, ok := transitions[to]; !ok {
+		return &TransitionError{From: from, To: to, Message: fmt.Sprintf("unknown status %q", to)}
+	}
+	if from == to {
+		return &TransitionError{From: from, To: to, Message: fmt.Sprintf("asset is already %s", to)}
+	}
+
+	allowed := false
+	for _, status := range AllowedTransitions(from) {
+		if status == to {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return &TransitionError{From: from, To: to, Message: fmt.Sprintf("cannot move asset from %s to %s", from, to)}
+	}
+
+	if RequiresReason(to) && strings.TrimSpace(reason) == "" {
+		return &TransitionError{From: from, To: to, Message: fmt.Sprintf("a reason is required to move an asset to %s", to)}
+	}
+	return nil
+}
+
+// InitialEvent returns the status event recording the status an asset is created with
+func InitialEvent(asset *models.Asset, reason string, changedBy *uuid.UUID) models.AssetStatusEvent {
+	return models.AssetStatusEvent{
+		AssetID:     asset.ID,
+		ToStatus:    asset.Status,
+		Reason:      reason,
+		ChangedByID: changedBy,
+	}
+}
+
+// Transition validates and applies a status change to the asset within tx, recording it as a
+// status event. The change only applies while the asset still has the status and version it was
+// loaded with; otherwise ErrConflict is returned. On success the asset's Status and Version are
+// updated in place.
+func Transition(tx *gorm.DB, asset *models.Asset, to, reason string, changedBy *uuid.UUID) (*models.AssetStatusEvent, error) {
+	if err := Validate(asset.Status, to, reason); err != nil {
+		return nil, err
+	}
+
+	event := models.AssetStatusEvent{
+		AssetID:     asset.ID,
+		FromStatus:  asset.Status,
+		ToStatus:    to,
+		Reason:      strings.TrimSpace(reason),
+		ChangedByID: changedBy,
+	}
+
+	result := tx.Model(asset).Where("status = ? AND version = ?", asset.Status, asset.Version).
+		Updates(map[string]interface{}{"status": to, "version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrConflict
+	}
+	asset.Status = to
+	asset.Version++
+	if err := tx.Create(&event).Error; err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
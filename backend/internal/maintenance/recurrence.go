@@ -0,0 +1,199 @@
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPeriods bounds how many recurrence periods are walked when looking for an occurrence
+const maxPeriods = 100000
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Recurrence is the subset of an RFC 5545 RRULE supported for calendar-based plans:
+// FREQ (DAILY, WEEKLY, MONTHLY, YEARLY), INTERVAL, BYDAY (weekly), BYMONTHDAY (monthly),
+// COUNT and UNTIL
+type Recurrence struct {
+	Freq       string
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	Count      int
+	Until      *time.Time
+}
+
+// ParseRRule parses an RRULE string such as "FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=1"
+func ParseRRule(rule string) (*Recurrence, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	if rule == "" {
+		return nil, errors.New("rrule is empty")
+	}
+
+	r := &Recurrence{Interval: 1}
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rrule part %q", part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Freq = strings.ToUpper(value)
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", value)
+			}
+			r.Interval = interval
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := weekdays[strings.ToUpper(day)]
+				if !ok {
+					return nil, fmt.Errorf("invalid BYDAY %q", day)
+				}
+				r.ByDay = append(r.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				monthDay, err := strconv.Atoi(day)
+				if err != nil || monthDay == 0 || monthDay < -31 || monthDay > 31 {
+					return nil, fmt.Errorf("invalid BYMONTHDAY %q", day)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, monthDay)
+			}
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("invalid COUNT %q", value)
+			}
+			r.Count = count
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid UNTIL %q", value)
+			}
+			r.Until = &until
+		default:
+			return nil, fmt.Errorf("unsupported rrule part %q", key)
+		}
+	}
+
+	switch r.Freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	case "":
+		return nil, errors.New("rrule requires FREQ")
+	default:
+		return nil, fmt.Errorf("unsupported FREQ %q", r.Freq)
+	}
+	if len(r.ByDay) > 0 && r.Freq != "WEEKLY" {
+		return nil, errors.New("BYDAY is only supported with FREQ=WEEKLY")
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq != "MONTHLY" {
+		return nil, errors.New("BYMONTHDAY is only supported with FREQ=MONTHLY")
+	}
+	if r.Count > 0 && r.Until != nil {
+		return nil, errors.New("COUNT and UNTIL cannot be combined")
+	}
+
+	return r, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102", time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("unrecognised date")
+}
+
+// Next returns the first occurrence strictly after the given time for a rule anchored at start.
+// The second return value is false once the rule is exhausted by COUNT or UNTIL.
+func (r *Recurrence) Next(start, after time.Time) (time.Time, bool) {
+	seen := 0
+	for period := 0; period < maxPeriods; period++ {
+		for _, occurrence := range r.candidates(start, period) {
+			if occurrence.Before(start) {
+				continue
+			}
+			if r.Until != nil && occurrence.After(*r.Until) {
+				return time.Time{}, false
+			}
+			seen++
+			if r.Count > 0 && seen > r.Count {
+				return time.Time{}, false
+			}
+			if occurrence.After(after) {
+				return occurrence, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// candidates returns the sorted occurrences within the n-th period of the rule
+func (r *Recurrence) candidates(start time.Time, n int) []time.Time {
+	step := n * r.Interval
+	hour, min, sec := start.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, 0, start.Location())
+	}
+
+	var occurrences []time.Time
+	switch r.Freq {
+	case "DAILY":
+		occurrences = append(occurrences, start.AddDate(0, 0, step))
+
+	case "WEEKLY":
+		// Weeks start on Monday
+		offset := (int(start.Weekday()) + 6) % 7
+		weekStart := start.AddDate(0, 0, step*7-offset)
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{start.Weekday()}
+		}
+		for _, day := range days {
+			occurrences = append(occurrences, weekStart.AddDate(0, 0, (int(day)+6)%7))
+		}
+
+	case "MONTHLY":
+		first := at(start.Year(), start.Month()+time.Month(step), 1)
+		lastDay := first.AddDate(0, 1, -1).Day()
+		days := r.ByMonthDay
+		if len(days) == 0 {
+			days = []int{start.Day()}
+		}
+		for _, day := range days {
+			if day < 0 {
+				day = lastDay + day + 1
+			}
+			// Days that do not exist in this month are skipped, as in RFC 5545
+			if day < 1 || day > lastDay {
+				continue
+			}
+			occurrences = append(occurrences, at(first.Year(), first.Month(), day))
+		}
+
+	case "YEARLY":
+		occurrence := at(start.Year()+step, start.Month(), start.Day())
+		// Skip February 29th in non-leap years
+		if occurrence.Month() == start.Month() {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].Before(occurrences[j]) })
+	return occurrences
+}
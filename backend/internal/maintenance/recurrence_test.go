@@ -0,0 +1,101 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+func TestParseRRule(t *testing.T) {
+	rule, err := ParseRRule("RRULE:freq=weekly;INTERVAL=2;BYDAY=MO,fr;UNTIL=20241231T000000Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule.Freq != "WEEKLY" || rule.Interval != 2 || len(rule.ByDay) != 2 || rule.ByDay[0] != time.Monday ||
+		rule.ByDay[1] != time.Friday || rule.Until == nil || !rule.Until.Equal(date(2024, 12, 31, 0)) {
+		t.Errorf("ParseRRule = %+v", rule)
+	}
+
+	for _, invalid := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20240101",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=DAILY;BYHOUR=9",
+		"FREQ",
+	} {
+		if _, err := ParseRRule(invalid); err == nil {
+			t.Errorf("ParseRRule(%q): expected an error", invalid)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   string
+		start  time.Time
+		after  time.Time
+		want   time.Time
+		wantOK bool
+	}{
+		{"first occurrence is the start", "FREQ=DAILY", date(2024, 1, 1, 9), date(2023, 12, 1, 0), date(2024, 1, 1, 9), true},
+		{"strictly after", "FREQ=DAILY", date(2024, 1, 1, 9), date(2024, 1, 1, 9), date(2024, 1, 2, 9), true},
+		{"daily interval", "FREQ=DAILY;INTERVAL=10", date(2024, 1, 1, 9), date(2024, 1, 15, 0), date(2024, 1, 21, 9), true},
+		{"weekly on the start's weekday", "FREQ=WEEKLY", date(2024, 1, 3, 9), date(2024, 1, 3, 9), date(2024, 1, 10, 9), true},
+		{"weekly by day", "FREQ=WEEKLY;BYDAY=TU,TH", date(2024, 1, 2, 9), date(2024, 1, 2, 9), date(2024, 1, 4, 9), true},
+		{"weekly by day into the next week", "FREQ=WEEKLY;BYDAY=TH,TU", date(2024, 1, 2, 9), date(2024, 1, 4, 9), date(2024, 1, 9, 9), true},
+		{"fortnightly skips days before the start", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", date(2024, 1, 3, 9), date(2024, 1, 1, 0), date(2024, 1, 15, 9), true},
+		{"weeks start on Monday", "FREQ=WEEKLY;INTERVAL=2;BYDAY=SU", date(2024, 1, 1, 9), date(2024, 1, 7, 9), date(2024, 1, 21, 9), true},
+		{"monthly on the start's day", "FREQ=MONTHLY", date(2024, 1, 15, 9), date(2024, 1, 15, 9), date(2024, 2, 15, 9), true},
+		{"monthly skips months without the day", "FREQ=MONTHLY;BYMONTHDAY=31", date(2024, 1, 31, 9), date(2024, 1, 31, 9), date(2024, 3, 31, 9), true},
+		{"monthly on the last day", "FREQ=MONTHLY;BYMONTHDAY=-1", date(2024, 1, 31, 9), date(2024, 1, 31, 9), date(2024, 2, 29, 9), true},
+		{"quarterly", "FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=1", date(2024, 1, 1, 9), date(2024, 2, 15, 0), date(2024, 4, 1, 9), true},
+		{"monthly by several days", "FREQ=MONTHLY;BYMONTHDAY=15,1", date(2024, 1, 1, 9), date(2024, 1, 1, 9), date(2024, 1, 15, 9), true},
+		{"yearly", "FREQ=YEARLY", date(2024, 6, 1, 9), date(2024, 6, 1, 9), date(2025, 6, 1, 9), true},
+		{"yearly on February 29th", "FREQ=YEARLY", date(2024, 2, 29, 9), date(2024, 2, 29, 9), date(2028, 2, 29, 9), true},
+		{"within COUNT", "FREQ=DAILY;COUNT=3", date(2024, 1, 1, 9), date(2024, 1, 2, 9), date(2024, 1, 3, 9), true},
+		{"exhausted by COUNT", "FREQ=DAILY;COUNT=3", date(2024, 1, 1, 9), date(2024, 1, 3, 9), time.Time{}, false},
+		{"within UNTIL", "FREQ=DAILY;UNTIL=20240105T090000Z", date(2024, 1, 1, 9), date(2024, 1, 4, 9), date(2024, 1, 5, 9), true},
+		{"exhausted by UNTIL", "FREQ=DAILY;UNTIL=20240105", date(2024, 1, 1, 9), date(2024, 1, 4, 9), time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := rule.Next(tt.start, tt.after)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("Next(%s, %s) = %s, %t, want %s, %t", tt.start, tt.after, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// Occurrences keep the start's wall-clock time across daylight saving changes
+func TestNextKeepsLocalTime(t *testing.T) {
+	location, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip("time zone database unavailable")
+	}
+	rule, err := ParseRRule("FREQ=WEEKLY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 3, 25, 9, 0, 0, 0, location)
+	got, ok := rule.Next(start, start)
+	if want := time.Date(2024, 4, 1, 9, 0, 0, 0, location); !ok || !got.Equal(want) {
+		t.Errorf("Next = %s, want %s", got, want)
+	}
+}
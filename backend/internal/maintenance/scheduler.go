@@ -0,0 +1,185 @@
+package maintenance
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"sams-backend/internal/lifecycle"
+	"sams-backend/internal/models"
+)
+
+// errAlreadyGenerated stops a transaction whose plan was advanced by another run
+var errAlreadyGenerated = errors.New("work order already generated")
+
+// ValidatePlan checks the recurrence settings of a maintenance plan
+func ValidatePlan(plan *models.MaintenancePlan) error {
+	if plan.StartDate.IsZero() {
+		return errors.New("start_date is required")
+	}
+	if plan.LeadTimeDays < 0 {
+		return errors.New("lead_time_days cannot be negative")
+	}
+
+	switch plan.ScheduleType {
+	case models.ScheduleTypeInterval:
+		if plan.IntervalDays == nil || *plan.IntervalDays < 1 {
+			return errors.New("interval plans require interval_days of at least 1")
+		}
+	case models.ScheduleTypeCalendar:
+		if _, err := ParseRRule(plan.RRule); err != nil {
+			return err
+		}
+	default:
+		return errors.New("schedule_type must be interval or calendar")
+	}
+	return nil
+}
+
+// InitialDue returns the first due date of a newly created or rescheduled plan
+func InitialDue(plan *models.MaintenancePlan) *time.Time {
+	if plan.ScheduleType == models.ScheduleTypeInterval {
+		due := plan.StartDate
+		return &due
+	}
+
+	rule, err := ParseRRule(plan.RRule)
+	if err != nil {
+		return nil
+	}
+	due, ok := rule.Next(plan.StartDate, plan.StartDate.Add(-time.Nanosecond))
+	if !ok {
+		return nil
+	}
+	return &due
+}
+
+// GenerateDueWorkOrders creates a work order for every active plan whose next due date,
+// less its lead time, has been reached. It returns the number of work orders created. The
+// plan is advanced only if it is still due as read, so concurrent runs, whether the scheduler,
+// a manual generation or another server, create a single work order for each due date.
+func GenerateDueWorkOrders(db *gorm.DB, now time.Time) (int, error) {
+	var plans []models.MaintenancePlan
+	if err := db.Where("is_active = ? AND next_due_at IS NOT NULL", true).Find(&plans).Error; err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for i := range plans {
+		plan := &plans[i]
+		if plan.NextDueAt.AddDate(0, 0, -plan.LeadTimeDays).After(now) {
+			continue
+		}
+
+		var asset models.Asset
+		if err := db.First(&asset, "id = ?", plan.AssetID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return created, err
+		}
+		if asset.Status == lifecycle.StatusDisposed {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			due := *plan.NextDueAt
+			workOrder := models.WorkOrder{
+				AssetID:     plan.AssetID,
+				PlanID:      &plan.ID,
+				Title:       plan.Name,
+				Description: plan.Description,
+				AssigneeID:  plan.DefaultAssigneeID,
+				DueDate:     &due,
+				Status:      models.WorkOrderOpen,
+			}
+
+			// Interval plans wait for the work order to close before scheduling again;
+			// calendar plans move to the next occurrence, collapsing any that were missed
+			var nextDue *time.Time
+			if plan.ScheduleType == models.ScheduleTypeCalendar {
+				if rule, err := ParseRRule(plan.RRule); err == nil {
+					after := due
+					if now.After(after) {
+						after = now
+					}
+					if next, ok := rule.Next(plan.StartDate, after); ok {
+						nextDue = &next
+					}
+				}
+			}
+
+			result := tx.Model(&models.MaintenancePlan{}).
+				Where("id = ? AND is_active = ? AND next_due_at = ?", plan.ID, true, due).
+				Updates(map[string]interface{}{
+					"next_due_at":       nextDue,
+					"last_generated_at": now,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected != 1 {
+				return errAlreadyGenerated
+			}
+			return tx.Create(&workOrder).Error
+		})
+		if errors.Is(err, errAlreadyGenerated) {
+			continue
+		}
+		if err != nil {
+			return created, err
+		}
+		created++
+	}
+
+	return created, nil
+}
+
+// RescheduleAfterClose updates the plan a work order belongs to once the work order is completed
+// or cancelled. Interval plans become due again interval_days after the close date.
+func RescheduleAfterClose(tx *gorm.DB, workOrder *models.WorkOrder, closedAt time.Time) error {
+	if workOrder.PlanID == nil {
+		return nil
+	}
+
+	var plan models.MaintenancePlan
+	if err := tx.First(&plan, "id = ?", *workOrder.PlanID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	updates := map[string]interface{}{}
+	if workOrder.Status == models.WorkOrderCompleted {
+		updates["last_completed_at"] = closedAt
+	}
+	if plan.ScheduleType == models.ScheduleTypeInterval && plan.IntervalDays != nil && plan.NextDueAt == nil {
+		updates["next_due_at"] = closedAt.AddDate(0, 0, *plan.IntervalDays)
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	return tx.Model(&plan).Updates(updates).Error
+}
+
+// StartScheduler runs GenerateDueWorkOrders in the background at the given interval
+func StartScheduler(db *gorm.DB, every time.Duration) {
+	go func() {
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+
+		for {
+			created, err := GenerateDueWorkOrders(db, time.Now())
+			if err != nil {
+				log.Printf("Maintenance scheduler failed: %v", err)
+			} else if created > 0 {
+				log.Printf("Maintenance scheduler generated %d work orders", created)
+			}
+			<-ticker.C
+		}
+	}()
+}
@@ -0,0 +1,277 @@
+// Package meters records the readings of asset meters and raises the maintenance that falls due
+// as cumulative meters count up
+package meters
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"sams-backend/internal/models"
+)
+
+// ReadingError describes a reading that is rejected
+type ReadingError struct {
+	Message string
+}
+
+func (e *ReadingError) Error() string {
+	return e.Message
+}
+
+// Round rounds a meter value to the three decimal places it is stored with
+func Round(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
+
+// format renders a meter value without trailing zeros
+func format(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// NextDue returns the first due value of a rule that lies beyond a reading: the rule falls due
+// every Interval from StartValue, and is raised LeadValue before each due value
+func NextDue(rule *models.MeterRule, reading float64) float64 {
+	periods := math.Floor((reading+rule.LeadValue-rule.StartValue)/rule.Interval) + 1
+	if periods < 1 {
+		periods = 1
+	}
+	due := Round(rule.StartValue + periods*rule.Interval)
+	// Division can fall just short of a whole number of intervals
+	if due <= Round(reading+rule.LeadValue) {
+		due = Round(due + rule.Interval)
+	}
+	return due
+}
+
+// NextDueAfter returns the first due value of a rule beyond a due value it has already raised,
+// so that changing the rule's lead or start cannot make it fall due there again
+func NextDueAfter(rule *models.MeterRule, raised float64) float64 {
+	due := Round(rule.StartValue + (math.Floor((raised-rule.StartValue)/rule.Interval)+1)*rule.Interval)
+	if due <= raised {
+		due = Round(due + rule.Interval)
+	}
+	return due
+}
+
+// Record stores a reading of a meter, which is locked for the rest of the transaction. A
+// cumulative meter never counts down, so its reading cannot be below an earlier reading or above
+// a later one. When the reading is the meter's latest it becomes the meter's last value and the
+// meter's rules are evaluated; the events raised are returned.
+func Record(tx *gorm.DB, meter *models.Meter, reading *models.MeterReading) ([]models.MaintenanceDueEvent, error) {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(meter).Error; err != nil {
+		return nil, err
+	}
+	reading.MeterID = meter.ID
+	reading.AssetID = meter.AssetID
+	reading.Value = Round(reading.Value)
+
+	if meter.Kind == models.MeterCumulative {
+		if reading.Value < 0 {
+			return nil, &ReadingError{Message: "Readings of a cumulative meter cannot be negative"}
+		}
+
+		var previous, next models.MeterReading
+		if err := tx.Where("meter_id = ? AND read_at <= ?", meter.ID, reading.ReadAt).
+			Order("read_at DESC").Limit(1).Find(&previous).Error; err != nil {
+			return nil, err
+		}
+		if previous.ID != uuid.Nil && reading.Value < previous.Value {
+			return nil, &ReadingError{Message: fmt.Sprintf("Reading %s is below the reading of %s %s taken at %s",
+				format(reading.Value), format(previous.Value), meter.Unit, previous.ReadAt.Format(time.RFC3339))}
+		}
+		if err := tx.Where("meter_id = ? AND read_at > ?", meter.ID, reading.ReadAt).
+			Order("read_at ASC").Limit(1).Find(&next).Error; err != nil {
+			return nil, err
+		}
+		if next.ID != uuid.Nil && reading.Value > next.Value {
+			return nil, &ReadingError{Message: fmt.Sprintf("Reading %s is above the later reading of %s %s taken at %s",
+				format(reading.Value), format(next.Value), meter.Unit, next.ReadAt.Format(time.RFC3339))}
+		}
+	}
+
+	if err := tx.Create(reading).Error; err != nil {
+		return nil, err
+	}
+	if meter.LastReadAt != nil && reading.ReadAt.Before(*meter.LastReadAt) {
+		return nil, nil
+	}
+
+	meter.LastValue = &reading.Value
+	meter.LastReadAt = &reading.ReadAt
+	if err := tx.Model(meter).Updates(map[string]interface{}{
+		"last_value":   reading.Value,
+		"last_read_at": reading.ReadAt,
+	}).Error; err != nil {
+		return nil, err
+	}
+	if meter.Kind != models.MeterCumulative {
+		return nil, nil
+	}
+	return evaluate(tx, meter, reading)
+}
+
+// evaluate raises an event for every active rule of the meter whose due value, less its lead,
+// the reading has reached, opening a work order when the rule asks for one. The rule then moves
+// to its first due value beyond the reading, so due values skipped by a large jump in the
+// reading are collapsed into a single event.
+func evaluate(tx *gorm.DB, meter *models.Meter, reading *models.MeterReading) ([]models.MaintenanceDueEvent, error) {
+	var rules []models.MeterRule
+	if err := tx.Where("meter_id = ? AND is_active = ? AND next_due_value - lead_value <= ?", meter.ID, true, reading.Value).
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	var events []models.MaintenanceDueEvent
+	for i := range rules {
+		rule := &rules[i]
+		event := models.MaintenanceDueEvent{
+			RuleID:       rule.ID,
+			MeterID:      meter.ID,
+			AssetID:      meter.AssetID,
+			DueValue:     rule.NextDueValue,
+			ReadingValue: reading.Value,
+			ReadAt:       reading.ReadAt,
+			Status:       models.MaintenanceDueOpen,
+		}
+
+		// A rule whose due value was moved back after raising it has already raised this
+		// event, so it only moves on
+		created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&event)
+		if created.Error != nil {
+			return nil, created.Error
+		}
+
+		if created.RowsAffected == 1 && rule.CreateWorkOrder {
+			description := fmt.Sprintf("%s reached %s %s; due at %s %s.", meter.Name, format(reading.Value), meter.Unit, format(rule.NextDueValue), meter.Unit)
+			if rule.Description != "" {
+				description = strings.TrimSpace(rule.Description) + "\n\n" + description
+			}
+			workOrder := models.WorkOrder{
+				AssetID:     meter.AssetID,
+				Title:       rule.Name,
+				Description: description,
+				AssigneeID:  rule.DefaultAssigneeID,
+				Status:      models.WorkOrderOpen,
+			}
+			if err := tx.Create(&workOrder).Error; err != nil {
+				return nil, err
+			}
+			event.WorkOrderID = &workOrder.ID
+			if err := tx.Model(&event).Update("work_order_id", workOrder.ID).Error; err != nil {
+				return nil, err
+			}
+		}
+
+		updates := map[string]interface{}{"next_due_value": NextDue(rule, reading.Value)}
+		if created.RowsAffected == 1 {
+			updates["last_triggered_at"] = time.Now()
+			events = append(events, event)
+		}
+		if err := tx.Model(rule).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// Forecast is when a meter rule is expected to fall due at the meter's recent rate of use
+type Forecast struct {
+	RuleID       uuid.UUID  `json:"rule_id"`
+	Name         string     `json:"name"`
+	NextDueValue float64    `json:"next_due_value"`
+	Remaining    float64    `json:"remaining"`
+	DueAt        *time.Time `json:"due_at"`
+}
+
+// Usage summarises the readings of a meter over a period. Usage and PerDay, the amount counted
+// and the average counted per day between the first and last readings of the period, are given
+// for cumulative meters only.
+type Usage struct {
+	MeterID     uuid.UUID  `json:"meter_id"`
+	Name        string     `json:"name"`
+	Unit        string     `json:"unit"`
+	Kind        string     `json:"kind"`
+	Readings    int64      `json:"readings"`
+	FirstReadAt *time.Time `json:"first_read_at"`
+	LastReadAt  *time.Time `json:"last_read_at"`
+	Minimum     *float64   `json:"minimum"`
+	Maximum     *float64   `json:"maximum"`
+	Average     *float64   `json:"average"`
+	Usage       *float64   `json:"usage,omitempty"`
+	PerDay      *float64   `json:"per_day,omitempty"`
+	Forecasts   []Forecast `json:"forecasts,omitempty"`
+}
+
+// MeterUsage summarises the readings a meter took since the given time. The active rules of a
+// cumulative meter that is in use are forecast from its latest reading at the period's rate.
+func MeterUsage(db *gorm.DB, meter *models.Meter, since time.Time) (*Usage, error) {
+	var stats struct {
+		Readings    int64
+		FirstReadAt *time.Time
+		LastReadAt  *time.Time
+		Minimum     *float64
+		Maximum     *float64
+		Average     *float64
+	}
+	err := db.Model(&models.MeterReading{}).
+		Select("COUNT(*) AS readings, MIN(read_at) AS first_read_at, MAX(read_at) AS last_read_at, "+
+			"MIN(value) AS minimum, MAX(value) AS maximum, AVG(value) AS average").
+		Where("meter_id = ? AND read_at >= ?", meter.ID, since).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &Usage{
+		MeterID:     meter.ID,
+		Name:        meter.Name,
+		Unit:        meter.Unit,
+		Kind:        meter.Kind,
+		Readings:    stats.Readings,
+		FirstReadAt: stats.FirstReadAt,
+		LastReadAt:  stats.LastReadAt,
+		Minimum:     stats.Minimum,
+		Maximum:     stats.Maximum,
+	}
+	if stats.Average != nil {
+		average := Round(*stats.Average)
+		usage.Average = &average
+	}
+	if meter.Kind != models.MeterCumulative || usage.Readings == 0 {
+		return usage, nil
+	}
+
+	// Cumulative readings only count up, so the first reading of the period is its minimum
+	total := Round(*usage.Maximum - *usage.Minimum)
+	usage.Usage = &total
+	days := usage.LastReadAt.Sub(*usage.FirstReadAt).Hours() / 24
+	if days <= 0 {
+		return usage, nil
+	}
+	perDay := Round(total / days)
+	usage.PerDay = &perDay
+
+	var rules []models.MeterRule
+	if err := db.Where("meter_id = ? AND is_active = ?", meter.ID, true).Order("next_due_value").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		forecast := Forecast{RuleID: rule.ID, Name: rule.Name, NextDueValue: rule.NextDueValue}
+		if meter.LastValue != nil && meter.LastReadAt != nil {
+			forecast.Remaining = Round(rule.NextDueValue - *meter.LastValue)
+			if perDay > 0 {
+				dueAt := meter.LastReadAt.Add(time.Duration(forecast.Remaining / perDay * 24 * float64(time.Hour)))
+				forecast.DueAt = &dueAt
+			}
+		}
+		usage.Forecasts = append(usage.Forecasts, forecast)
+	}
+	return usage, nil
+}
@@ -0,0 +1,48 @@
+package meters
+
+import (
+	"testing"
+
+	"sams-backend/internal/models"
+)
+
+func TestNextDue(t *testing.T) {
+	tests := []struct {
+		start, interval, lead, reading, want float64
+	}{
+		{0, 500, 0, 0, 500},
+		{0, 500, 0, 499.9, 500},
+		{0, 500, 0, 500, 1000},
+		{0, 500, 100, 400, 1000},
+		{0, 500, 100, 399.999, 500},
+		{250, 500, 0, 100, 750},
+		{0, 0.1, 0, 0.3, 0.4},
+		{0, 500, 0, 2600, 3000},
+	}
+	for _, tt := range tests {
+		rule := &models.MeterRule{StartValue: tt.start, Interval: tt.interval, LeadValue: tt.lead}
+		if got := NextDue(rule, tt.reading); got != tt.want {
+			t.Errorf("NextDue(start %v, every %v, lead %v, reading %v) = %v, want %v", tt.start, tt.interval, tt.lead, tt.reading, got, tt.want)
+		}
+	}
+}
+
+func TestNextDueAfter(t *testing.T) {
+	tests := []struct {
+		start, interval, raised, want float64
+	}{
+		{0, 500, 500, 1000},
+		{0, 500, 1000, 1500},
+		{100, 500, 600, 1100},
+		// The interval was changed after the event was raised
+		{0, 300, 500, 600},
+		{0, 0.1, 0.3, 0.4},
+		{0, 0.7, 2.1, 2.8},
+	}
+	for _, tt := range tests {
+		rule := &models.MeterRule{StartValue: tt.start, Interval: tt.interval}
+		if got := NextDueAfter(rule, tt.raised); got != tt.want {
+			t.Errorf("NextDueAfter(start %v, every %v, raised %v) = %v, want %v", tt.start, tt.interval, tt.raised, got, tt.want)
+		}
+	}
+}
@@ -22,13 +22,23 @@ type Asset struct {
 	// Technical Specifications
 	Type         string `json:"type" gorm:"type:varchar(100)"`
 	Model        string `json:"model" gorm:"type:varchar(100)"`
-	SerialNumber string `json:"serial_number" gorm:"type:varchar(100);unique"`
+	SerialNumber string `json:"serial_number" gorm:"type:varchar(100);uniqueIndex:idx_assets_serial_number,where:deleted_at IS NULL"`
+	// AssetTag is the organisation's own tag number, printed on labels; it is optional
+	AssetTag     string `json:"asset_tag" gorm:"type:varchar(50);uniqueIndex:idx_assets_asset_tag,where:deleted_at IS NULL AND asset_tag <> ''"`
 	Manufacturer string `json:"manufacturer" gorm:"type:varchar(100)"`
+	// ManufacturerID and SupplierID link the asset to the vendor registry; Manufacturer keeps the
+	// name as it was entered
+	ManufacturerID     *uuid.UUID `json:"manufacturer_id" gorm:"type:uuid;index"`
+	ManufacturerVendor *Vendor    `json:"manufacturer_vendor,omitempty" gorm:"foreignKey:ManufacturerID"`
+	SupplierID         *uuid.UUID `json:"supplier_id" gorm:"type:uuid;index"`
+	Supplier           *Vendor    `json:"supplier,omitempty" gorm:"foreignKey:SupplierID"`
 
 	// Financial Information
-	AcquisitionCost  float64 `json:"acquisition_cost" gorm:"type:decimal(15,2)"`
-	CurrentValue     float64 `json:"current_value" gorm:"type:decimal(15,2)"`
-	DepreciationRate float64 `json:"depreciation_rate" gorm:"type:decimal(5,2)"`
+	AcquisitionCost    float64 `json:"acquisition_cost" gorm:"type:decimal(15,2)"`
+	CurrentValue       float64 `json:"current_value" gorm:"type:decimal(15,2)"`
+	DepreciationRate   float64 `json:"depreciation_rate" gorm:"type:decimal(5,2)"`
+	SalvageValue       float64 `json:"salvage_value" gorm:"type:decimal(15,2);default:0"`
+	DepreciationMethod string  `json:"depreciation_method" gorm:"type:varchar(30);default:'';check:depreciation_method IN ('', 'straight_line', 'declining_balance', 'sum_of_years_digits')"`
 
 	// Operational Status
 	Status      string `json:"status" gorm:"type:varchar(50);default:'active';check:status IN ('active', 'inactive', 'maintenance', 'disposed')"`
@@ -40,6 +50,9 @@ type Asset struct {
 	Longitude    *float64 `json:"longitude" gorm:"type:decimal(11,8)"`
 	Address      string   `json:"address" gorm:"type:text"`
 	BuildingRoom string   `json:"building_room" gorm:"type:varchar(100)"`
+	// HomeGeofenceID is the zone the asset belongs in; leaving it raises a geofence alert
+	HomeGeofenceID *uuid.UUID `json:"home_geofence_id" gorm:"type:uuid;index"`
+	HomeGeofence   *Geofence  `json:"home_geofence,omitempty" gorm:"foreignKey:HomeGeofenceID"`
 
 	// Lifecycle Information
 	AcquisitionDate     *time.Time `json:"acquisition_date" gorm:"type:date"`
@@ -51,10 +64,17 @@ type Asset struct {
 	Standards      string `json:"standards" gorm:"type:text"`
 	AuditInfo      string `json:"audit_info" gorm:"type:text"`
 
+	// Last sighting, from the most recent scan
+	LastSeenAt   *time.Time `json:"last_seen_at" gorm:"index"`
+	LastSeenByID *uuid.UUID `json:"last_seen_by_id" gorm:"type:uuid"`
+	LastSeenBy   *User      `json:"last_seen_by,omitempty" gorm:"foreignKey:LastSeenByID"`
+
 	// Metadata
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	// Version is incremented on every write and exposed as the asset's ETag
+	Version int `json:"version" gorm:"not null;default:1"`
 
 	// Relationships
 	// Category Category `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
@@ -65,6 +85,9 @@ func (a *Asset) BeforeCreate(tx *gorm.DB) error {
 	if a.ID == uuid.Nil {
 		a.ID = uuid.New()
 	}
+	if a.Version == 0 {
+		a.Version = 1
+	}
 	return nil
 }
 
@@ -93,3 +116,19 @@ type AssetSummary struct {
 	AcquisitionDate   *time.Time `json:"acquisition_date"`
 	ExpectedLifeYears *int       `json:"expected_life_years"`
 }
+
+// AssetLabelRequest selects the assets printed on a label sheet
+type AssetLabelRequest struct {
+	AssetIDs []uuid.UUID `json:"asset_ids" validate:"required,min=1,max=1000"`
+	// Layout is a label stock name such as L7160; see GET /api/v1/assets/labels/layouts
+	Layout string `json:"layout"`
+	// Skip leaves the first positions blank, for reusing a partly used sheet
+	Skip int `json:"skip" validate:"min=0"`
+	// Type is the code printed: qr (default), which links to the asset, or code128, code39 or
+	// datamatrix, which encode the value chosen by Key
+	Type string `json:"type" validate:"omitempty,oneof=qr code128 code39 datamatrix"`
+	// Key is the value barcodes encode: serial (default) or tag
+	Key string `json:"key" validate:"omitempty,oneof=serial tag"`
+	// Level is the QR error correction level: L, M, Q or H
+	Level string `json:"level" validate:"omitempty,oneof=L M Q H l m q h"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AssetHistory records a single field-level change made to an asset (ISO 55001 audit trail)
+type AssetHistory struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	AssetID     uuid.UUID  `json:"asset_id" gorm:"type:uuid;not null;index"`
+	Field       string     `json:"field" gorm:"type:varchar(100);not null"`
+	OldValue    string     `json:"old_value" gorm:"type:text"`
+	NewValue    string     `json:"new_value" gorm:"type:text"`
+	ChangedByID *uuid.UUID `json:"changed_by_id" gorm:"type:uuid"`
+	ChangedBy   *User      `json:"changed_by,omitempty" gorm:"foreignKey:ChangedByID"`
+	ChangedAt   time.Time  `json:"changed_at" gorm:"not null;index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (h *AssetHistory) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	if h.ChangedAt.IsZero() {
+		h.ChangedAt = time.Now()
+	}
+	return nil
+}
+
+// TableName specifies the table name for AssetHistory
+func (AssetHistory) TableName() string {
+	return "asset_histories"
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AssetStatusEvent records a single lifecycle transition of an asset's status
+type AssetStatusEvent struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	AssetID     uuid.UUID  `json:"asset_id" gorm:"type:uuid;not null;index"`
+	FromStatus  string     `json:"from_status" gorm:"type:varchar(50);not null"`
+	ToStatus    string     `json:"to_status" gorm:"type:varchar(50);not null;index"`
+	Reason      string     `json:"reason" gorm:"type:text"`
+	ChangedByID *uuid.UUID `json:"changed_by_id" gorm:"type:uuid"`
+	ChangedBy   *User      `json:"changed_by,omitempty" gorm:"foreignKey:ChangedByID"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (e *AssetStatusEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for AssetStatusEvent
+func (AssetStatusEvent) TableName() string {
+	return "asset_status_events"
+}
+
+// AssetTransitionRequest represents a request to move an asset to a new lifecycle status
+type AssetTransitionRequest struct {
+	Status string `json:"status" validate:"required,oneof=active inactive maintenance disposed"`
+	Reason string `json:"reason" validate:"max=2000"`
+}
@@ -9,12 +9,13 @@ import (
 
 // Category represents an asset category
 type Category struct {
-	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
-	Name        string         `json:"name" gorm:"type:varchar(100);not null;unique"`
-	Description string         `json:"description" gorm:"type:text"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	ID                 uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Name               string         `json:"name" gorm:"type:varchar(100);not null;uniqueIndex:idx_categories_name,where:deleted_at IS NULL"`
+	Description        string         `json:"description" gorm:"type:text"`
+	DepreciationMethod string         `json:"depreciation_method" gorm:"type:varchar(30);default:'';check:depreciation_method IN ('', 'straight_line', 'declining_balance', 'sum_of_years_digits')"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 
 	// Relationships
 	Assets []Asset `json:"assets,omitempty" gorm:"foreignKey:CategoryID"`
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Contract kinds
+const (
+	ContractWarranty = "warranty"
+	ContractService  = "service"
+	ContractSupport  = "support"
+	ContractLease    = "lease"
+)
+
+// DefaultContractAlertDays is how long before its end date a contract raises an expiry alert
+// when no lead time is given
+const DefaultContractAlertDays = 30
+
+// Contract is a warranty or service contract covering one or more assets
+type Contract struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Kind      string    `json:"kind" gorm:"type:varchar(20);not null;default:'warranty';index;check:kind IN ('warranty', 'service', 'support', 'lease')"`
+	Reference string    `json:"reference" gorm:"type:varchar(100)"`
+	Provider  string    `json:"provider" gorm:"type:varchar(255);not null;index"`
+	// VendorID links the contract to the registered service provider named in Provider
+	VendorID *uuid.UUID `json:"vendor_id" gorm:"type:uuid;index"`
+	Vendor   *Vendor    `json:"vendor,omitempty" gorm:"foreignKey:VendorID"`
+
+	// Term and coverage. The term runs from the start of StartDate to the end of EndDate.
+	StartDate     time.Time `json:"start_date" gorm:"type:date;not null"`
+	EndDate       time.Time `json:"end_date" gorm:"type:date;not null;index"`
+	CoverageTerms string    `json:"coverage_terms" gorm:"type:text"`
+	Cost          float64   `json:"cost" gorm:"type:decimal(15,2);default:0"`
+	// DocumentReference locates the signed contract or warranty certificate, such as a file path
+	// or document management URL
+	DocumentReference string `json:"document_reference" gorm:"type:varchar(500)"`
+	Notes             string `json:"notes" gorm:"type:text"`
+	// AlertDays is how long before the end date an expiry alert is raised
+	AlertDays int `json:"alert_days" gorm:"type:integer;not null;default:30"`
+
+	Assets []Asset `json:"assets,omitempty" gorm:"many2many:contract_assets"`
+
+	// Metadata
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (ct *Contract) BeforeCreate(tx *gorm.DB) error {
+	if ct.ID == uuid.Nil {
+		ct.ID = uuid.New()
+	}
+	if ct.Kind == "" {
+		ct.Kind = ContractWarranty
+	}
+	return nil
+}
+
+// TableName specifies the table name for Contract
+func (Contract) TableName() string {
+	return "contracts"
+}
+
+// ContractAsset links a contract to an asset it covers
+type ContractAsset struct {
+	ContractID uuid.UUID `json:"contract_id" gorm:"type:uuid;primaryKey"`
+	AssetID    uuid.UUID `json:"asset_id" gorm:"type:uuid;primaryKey;index"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for ContractAsset
+func (ContractAsset) TableName() string {
+	return "contract_assets"
+}
+
+// Contract alert statuses
+const (
+	ContractAlertOpen         = "open"
+	ContractAlertAcknowledged = "acknowledged"
+)
+
+// ContractAlert warns that a contract is about to expire. One alert is raised per end date, so
+// a contract that is extended raises a new alert as its new end date approaches.
+type ContractAlert struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	ContractID uuid.UUID `json:"contract_id" gorm:"type:uuid;not null;uniqueIndex:idx_contract_alerts_expiry"`
+	Contract   *Contract `json:"contract,omitempty" gorm:"foreignKey:ContractID"`
+	EndDate    time.Time `json:"end_date" gorm:"type:date;not null;uniqueIndex:idx_contract_alerts_expiry"`
+	Status     string    `json:"status" gorm:"type:varchar(20);not null;default:'open';index;check:status IN ('open', 'acknowledged')"`
+
+	AcknowledgedByID *uuid.UUID `json:"acknowledged_by_id" gorm:"type:uuid"`
+	AcknowledgedBy   *User      `json:"acknowledged_by,omitempty" gorm:"foreignKey:AcknowledgedByID"`
+	AcknowledgedAt   *time.Time `json:"acknowledged_at"`
+
+	// Metadata
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (a *ContractAlert) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	if a.Status == "" {
+		a.Status = ContractAlertOpen
+	}
+	return nil
+}
+
+// TableName specifies the table name for ContractAlert
+func (ContractAlert) TableName() string {
+	return "contract_alerts"
+}
+
+// ContractRequest represents the data needed to create or replace a contract
+type ContractRequest struct {
+	Kind              string      `json:"kind" validate:"omitempty,oneof=warranty service support lease"`
+	Reference         string      `json:"reference" validate:"max=100"`
+	Provider          string      `json:"provider" validate:"required_without=VendorID,max=255"`
+	VendorID          *uuid.UUID  `json:"vendor_id"`
+	StartDate         time.Time   `json:"start_date" validate:"required"`
+	EndDate           time.Time   `json:"end_date" validate:"required"`
+	CoverageTerms     string      `json:"coverage_terms"`
+	Cost              float64     `json:"cost" validate:"gte=0"`
+	DocumentReference string      `json:"document_reference" validate:"max=500"`
+	Notes             string      `json:"notes"`
+	AlertDays         *int        `json:"alert_days" validate:"omitempty,gte=0,lte=365"`
+	AssetIDs          []uuid.UUID `json:"asset_ids" validate:"required,min=1"`
+}
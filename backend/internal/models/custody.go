@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AssetCustody records an asset being checked out to a user or department and checked back in
+type AssetCustody struct {
+	ID      uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	AssetID uuid.UUID `json:"asset_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_asset_custodies_open,where:checked_in_at IS NULL"`
+	Asset   *Asset    `json:"asset,omitempty" gorm:"foreignKey:AssetID"`
+
+	// Custodian - exactly one of user or department
+	UserID       *uuid.UUID  `json:"user_id" gorm:"type:uuid;index"`
+	User         *User       `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	DepartmentID *uuid.UUID  `json:"department_id" gorm:"type:uuid;index"`
+	Department   *Department `json:"department,omitempty" gorm:"foreignKey:DepartmentID"`
+
+	// Check-out
+	CheckedOutAt     time.Time  `json:"checked_out_at" gorm:"not null"`
+	CheckedOutByID   *uuid.UUID `json:"checked_out_by_id" gorm:"type:uuid"`
+	ExpectedReturnAt *time.Time `json:"expected_return_at"`
+	CheckoutNotes    string     `json:"checkout_notes" gorm:"type:text"`
+
+	// Check-in
+	CheckedInAt     *time.Time `json:"checked_in_at" gorm:"index"`
+	CheckedInByID   *uuid.UUID `json:"checked_in_by_id" gorm:"type:uuid"`
+	ReturnCondition string     `json:"return_condition" gorm:"type:varchar(50)"`
+	ReturnNotes     string     `json:"return_notes" gorm:"type:text"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (a *AssetCustody) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for AssetCustody
+func (AssetCustody) TableName() string {
+	return "asset_custodies"
+}
+
+// CheckoutRequest represents the data needed to check an asset out
+type CheckoutRequest struct {
+	UserID           *uuid.UUID `json:"user_id"`
+	DepartmentID     *uuid.UUID `json:"department_id"`
+	ExpectedReturnAt *time.Time `json:"expected_return_at"`
+	Notes            string     `json:"notes"`
+}
+
+// CheckinRequest represents the data needed to check an asset back in
+type CheckinRequest struct {
+	Condition string `json:"condition" validate:"omitempty,oneof=excellent good fair poor critical"`
+	Notes     string `json:"notes"`
+}
@@ -10,7 +10,7 @@ import (
 // Department represents an organizational department
 type Department struct {
 	ID          uuid.UUID      `gorm:"type:uuid;primary_key;" json:"id"`
-	Name        string         `gorm:"type:varchar(100);not null;unique" json:"name"`
+	Name        string         `gorm:"type:varchar(100);not null;uniqueIndex:idx_departments_name,where:deleted_at IS NULL" json:"name"`
 	Description string         `gorm:"type:text" json:"description"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
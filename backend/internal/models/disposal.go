@@ -0,0 +1,79 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Disposal request statuses
+const (
+	DisposalPending   = "pending"
+	DisposalApproved  = "approved"
+	DisposalRejected  = "rejected"
+	DisposalCancelled = "cancelled"
+)
+
+// DisposalRequest represents a request to dispose of an asset, approved before the asset is disposed
+// (ISO 55001 - Asset Disposal)
+type DisposalRequest struct {
+	ID      uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	AssetID uuid.UUID `json:"asset_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_disposal_requests_pending,where:status = 'pending'"`
+	Asset   *Asset    `json:"asset,omitempty" gorm:"foreignKey:AssetID"`
+
+	// Request
+	Method        string     `json:"method" gorm:"type:varchar(20);not null;check:method IN ('sale', 'scrap', 'donation', 'transfer')"`
+	Proceeds      float64    `json:"proceeds" gorm:"type:decimal(15,2);default:0"`
+	Recipient     string     `json:"recipient" gorm:"type:varchar(255)"`
+	Reason        string     `json:"reason" gorm:"type:text;not null"`
+	Status        string     `json:"status" gorm:"type:varchar(20);not null;default:'pending';index;check:status IN ('pending', 'approved', 'rejected', 'cancelled')"`
+	RequestedByID *uuid.UUID `json:"requested_by_id" gorm:"type:uuid"`
+	RequestedBy   *User      `json:"requested_by,omitempty" gorm:"foreignKey:RequestedByID"`
+
+	// Review
+	ReviewedByID *uuid.UUID `json:"reviewed_by_id" gorm:"type:uuid"`
+	ReviewedBy   *User      `json:"reviewed_by,omitempty" gorm:"foreignKey:ReviewedByID"`
+	ReviewedAt   *time.Time `json:"reviewed_at"`
+	ReviewNotes  string     `json:"review_notes" gorm:"type:text"`
+
+	// Financial outcome, computed on approval
+	DisposalDate    *time.Time `json:"disposal_date" gorm:"type:date;index"`
+	AcquisitionCost float64    `json:"acquisition_cost" gorm:"type:decimal(15,2)"`
+	BookValue       float64    `json:"book_value" gorm:"type:decimal(15,2)"`
+	GainLoss        float64    `json:"gain_loss" gorm:"type:decimal(15,2)"`
+
+	// Metadata
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (d *DisposalRequest) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	if d.Status == "" {
+		d.Status = DisposalPending
+	}
+	return nil
+}
+
+// TableName specifies the table name for DisposalRequest
+func (DisposalRequest) TableName() string {
+	return "disposal_requests"
+}
+
+// DisposalCreateRequest represents the data needed to request the disposal of an asset
+type DisposalCreateRequest struct {
+	Method    string  `json:"method" validate:"required,oneof=sale scrap donation transfer"`
+	Proceeds  float64 `json:"proceeds" validate:"gte=0"`
+	Recipient string  `json:"recipient" validate:"max=255"`
+	Reason    string  `json:"reason" validate:"required"`
+}
+
+// DisposalReviewRequest represents an approval or rejection of a disposal request
+type DisposalReviewRequest struct {
+	Notes        string     `json:"notes"`
+	DisposalDate *time.Time `json:"disposal_date"`
+}
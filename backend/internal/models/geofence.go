@@ -0,0 +1,121 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"sams-backend/internal/geo"
+)
+
+// Geofence kinds
+const (
+	GeofenceSite      = "site"
+	GeofenceYard      = "yard"
+	GeofenceWarehouse = "warehouse"
+	GeofenceOther     = "other"
+)
+
+// Geofence is a named area, such as a site, yard or warehouse, that assets are assigned to as
+// their home zone
+type Geofence struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Name        string    `json:"name" gorm:"type:varchar(100);not null;uniqueIndex"`
+	Kind        string    `json:"kind" gorm:"type:varchar(20);not null;default:'site';check:kind IN ('site', 'yard', 'warehouse', 'other')"`
+	Description string    `json:"description" gorm:"type:text"`
+	// Boundary is a GeoJSON Polygon
+	Boundary geo.Geometry `json:"boundary" gorm:"type:jsonb;not null"`
+
+	// Metadata
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (g *Geofence) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == uuid.Nil {
+		g.ID = uuid.New()
+	}
+	if g.Kind == "" {
+		g.Kind = GeofenceSite
+	}
+	return nil
+}
+
+// TableName specifies the table name for Geofence
+func (Geofence) TableName() string {
+	return "geofences"
+}
+
+// GeofenceRequest represents the data needed to create or replace a geofence
+type GeofenceRequest struct {
+	Name        string        `json:"name" validate:"required,max=100"`
+	Kind        string        `json:"kind" validate:"omitempty,oneof=site yard warehouse other"`
+	Description string        `json:"description"`
+	Boundary    *geo.Geometry `json:"boundary" validate:"required"`
+}
+
+// Geofence alert statuses
+const (
+	GeofenceAlertOpen         = "open"
+	GeofenceAlertAcknowledged = "acknowledged"
+	GeofenceAlertResolved     = "resolved"
+)
+
+// Sources of the location that raised a geofence alert
+const (
+	LocationSourceAssetCreate = "asset_create"
+	LocationSourceAssetUpdate = "asset_update"
+	LocationSourceImport      = "import"
+	LocationSourceTransfer    = "transfer"
+	LocationSourceScan        = "scan"
+	LocationSourceInventory   = "inventory"
+)
+
+// GeofenceAlert records an asset located outside its home zone. An asset has at most one
+// unresolved alert, which is updated while the asset stays out and resolved when it is back.
+type GeofenceAlert struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	AssetID    uuid.UUID `json:"asset_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_geofence_alerts_unresolved,where:status <> 'resolved'"`
+	Asset      *Asset    `json:"asset,omitempty" gorm:"foreignKey:AssetID"`
+	GeofenceID uuid.UUID `json:"geofence_id" gorm:"type:uuid;not null;index"`
+	Geofence   *Geofence `json:"geofence,omitempty" gorm:"foreignKey:GeofenceID"`
+	Status     string    `json:"status" gorm:"type:varchar(20);not null;default:'open';index;check:status IN ('open', 'acknowledged', 'resolved')"`
+
+	// Last location reported outside the zone
+	Source     string  `json:"source" gorm:"type:varchar(30);not null"`
+	Latitude   float64 `json:"latitude" gorm:"type:decimal(10,8)"`
+	Longitude  float64 `json:"longitude" gorm:"type:decimal(11,8)"`
+	DistanceKm float64 `json:"distance_km" gorm:"type:decimal(10,3)"`
+	// ReportedByID is the user whose update or scan reported the location
+	ReportedByID *uuid.UUID `json:"reported_by_id" gorm:"type:uuid"`
+	ReportedBy   *User      `json:"reported_by,omitempty" gorm:"foreignKey:ReportedByID"`
+	DetectedAt   time.Time  `json:"detected_at"`
+	LastSeenAt   time.Time  `json:"last_seen_at"`
+
+	AcknowledgedByID *uuid.UUID `json:"acknowledged_by_id" gorm:"type:uuid"`
+	AcknowledgedBy   *User      `json:"acknowledged_by,omitempty" gorm:"foreignKey:AcknowledgedByID"`
+	AcknowledgedAt   *time.Time `json:"acknowledged_at"`
+	ResolvedAt       *time.Time `json:"resolved_at"`
+
+	// Metadata
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (a *GeofenceAlert) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	if a.Status == "" {
+		a.Status = GeofenceAlertOpen
+	}
+	return nil
+}
+
+// TableName specifies the table name for GeofenceAlert
+func (GeofenceAlert) TableName() string {
+	return "geofence_alerts"
+}
@@ -0,0 +1,200 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Inventory campaign statuses
+const (
+	InventoryCampaignOpen   = "open"
+	InventoryCampaignClosed = "closed"
+)
+
+// Reconciliation results of an inventory campaign
+const (
+	InventoryFound         = "found"
+	InventoryMissing       = "missing"
+	InventoryWrongLocation = "wrong_location"
+	InventoryNotInRegister = "not_in_register"
+)
+
+// InventoryCampaign is a physical inventory (stocktake) in which auditors scan the assets they
+// find, to be reconciled against the register. An asset is in scope when it matches every scope
+// field that is set; a campaign without scope covers the whole register.
+type InventoryCampaign struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Name        string    `json:"name" gorm:"type:varchar(255);not null"`
+	Description string    `json:"description" gorm:"type:text"`
+	Status      string    `json:"status" gorm:"type:varchar(20);not null;default:'open';index;check:status IN ('open', 'closed')"`
+
+	// Scope
+	DepartmentID *uuid.UUID  `json:"department_id" gorm:"type:uuid;index"`
+	Department   *Department `json:"department,omitempty" gorm:"foreignKey:DepartmentID"`
+	CategoryID   *uuid.UUID  `json:"category_id" gorm:"type:uuid;index"`
+	Category     *Category   `json:"category,omitempty" gorm:"foreignKey:CategoryID"`
+	BuildingRoom string      `json:"building_room" gorm:"type:varchar(100)"`
+	// GeofenceID limits the campaign to assets whose home zone is this geofence
+	GeofenceID *uuid.UUID `json:"geofence_id" gorm:"type:uuid"`
+	Geofence   *Geofence  `json:"geofence,omitempty" gorm:"foreignKey:GeofenceID"`
+
+	// Scans are accepted from the auditors between StartsAt and EndsAt, when set
+	StartsAt time.Time                  `json:"starts_at" gorm:"not null"`
+	EndsAt   *time.Time                 `json:"ends_at"`
+	Auditors []InventoryCampaignAuditor `json:"auditors,omitempty" gorm:"foreignKey:CampaignID"`
+
+	CreatedByID *uuid.UUID `json:"created_by_id" gorm:"type:uuid"`
+	CreatedBy   *User      `json:"created_by,omitempty" gorm:"foreignKey:CreatedByID"`
+
+	// Closing, with the reconciliation as it stood before fixes were applied
+	ClosedByID         *uuid.UUID `json:"closed_by_id" gorm:"type:uuid"`
+	ClosedBy           *User      `json:"closed_by,omitempty" gorm:"foreignKey:ClosedByID"`
+	ClosedAt           *time.Time `json:"closed_at"`
+	CloseNotes         string     `json:"close_notes" gorm:"type:text"`
+	FoundCount         int        `json:"found_count" gorm:"type:integer;default:0"`
+	MissingCount       int        `json:"missing_count" gorm:"type:integer;default:0"`
+	WrongLocationCount int        `json:"wrong_location_count" gorm:"type:integer;default:0"`
+	NotInRegisterCount int        `json:"not_in_register_count" gorm:"type:integer;default:0"`
+	FixedAssetCount    int        `json:"fixed_asset_count" gorm:"type:integer;default:0"`
+
+	// Metadata
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (ic *InventoryCampaign) BeforeCreate(tx *gorm.DB) error {
+	if ic.ID == uuid.Nil {
+		ic.ID = uuid.New()
+	}
+	if ic.Status == "" {
+		ic.Status = InventoryCampaignOpen
+	}
+	if ic.StartsAt.IsZero() {
+		ic.StartsAt = time.Now()
+	}
+	return nil
+}
+
+// TableName specifies the table name for InventoryCampaign
+func (InventoryCampaign) TableName() string {
+	return "inventory_campaigns"
+}
+
+// InventoryCampaignAuditor assigns a user to scan assets for an inventory campaign
+type InventoryCampaignAuditor struct {
+	CampaignID uuid.UUID `json:"campaign_id" gorm:"type:uuid;primaryKey"`
+	UserID     uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey;index"`
+	User       *User     `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for InventoryCampaignAuditor
+func (InventoryCampaignAuditor) TableName() string {
+	return "inventory_campaign_auditors"
+}
+
+// InventoryScan is a code scanned by an auditor during an inventory campaign. Codes that match
+// no asset are kept without an asset, as items found that are not in the register.
+type InventoryScan struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	CampaignID uuid.UUID  `json:"campaign_id" gorm:"type:uuid;not null;index"`
+	AssetID    *uuid.UUID `json:"asset_id" gorm:"type:uuid;index"`
+	Asset      *Asset     `json:"asset,omitempty" gorm:"foreignKey:AssetID"`
+	Payload    string     `json:"payload" gorm:"type:text;not null"`
+
+	// Where and how the item was found
+	BuildingRoom string   `json:"building_room" gorm:"type:varchar(100)"`
+	Latitude     *float64 `json:"latitude" gorm:"type:decimal(10,8)"`
+	Longitude    *float64 `json:"longitude" gorm:"type:decimal(11,8)"`
+	Condition    string   `json:"condition" gorm:"type:varchar(50)"`
+	Notes        string   `json:"notes" gorm:"type:text"`
+
+	ScannedByID *uuid.UUID `json:"scanned_by_id" gorm:"type:uuid"`
+	ScannedBy   *User      `json:"scanned_by,omitempty" gorm:"foreignKey:ScannedByID"`
+	ScannedAt   time.Time  `json:"scanned_at" gorm:"not null;index"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (s *InventoryScan) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for InventoryScan
+func (InventoryScan) TableName() string {
+	return "inventory_scans"
+}
+
+// InventoryCampaignRequest represents the data needed to start an inventory campaign
+type InventoryCampaignRequest struct {
+	Name         string      `json:"name" validate:"required,max=255"`
+	Description  string      `json:"description"`
+	DepartmentID *uuid.UUID  `json:"department_id"`
+	CategoryID   *uuid.UUID  `json:"category_id"`
+	BuildingRoom string      `json:"building_room" validate:"max=100"`
+	GeofenceID   *uuid.UUID  `json:"geofence_id"`
+	StartsAt     *time.Time  `json:"starts_at"`
+	EndsAt       *time.Time  `json:"ends_at"`
+	AuditorIDs   []uuid.UUID `json:"auditor_ids" validate:"required,min=1"`
+}
+
+// InventoryAuditorsRequest replaces the auditors of an inventory campaign
+type InventoryAuditorsRequest struct {
+	AuditorIDs []uuid.UUID `json:"auditor_ids" validate:"required,min=1"`
+}
+
+// InventoryScanRequest represents a code scanned by an auditor and where it was found
+type InventoryScanRequest struct {
+	ScanRequest
+	BuildingRoom string `json:"building_room" validate:"max=100"`
+}
+
+// InventoryFix corrects the register for one asset when a campaign is closed. The status
+// change is made through the asset lifecycle; location fields that are set replace the asset's.
+type InventoryFix struct {
+	AssetID      uuid.UUID `json:"asset_id" validate:"required"`
+	Status       string    `json:"status" validate:"omitempty,oneof=active inactive maintenance"`
+	Reason       string    `json:"reason"`
+	Address      string    `json:"address"`
+	BuildingRoom string    `json:"building_room" validate:"max=100"`
+	Latitude     *float64  `json:"latitude" validate:"omitempty,latitude"`
+	Longitude    *float64  `json:"longitude" validate:"omitempty,longitude"`
+}
+
+// InventoryCloseRequest closes a campaign, applying fixes to the register. MissingStatus moves
+// every asset still missing to that status, and UpdateLocations moves assets found in the wrong
+// location to where they were found; fixes given for an asset replace both.
+type InventoryCloseRequest struct {
+	Notes           string         `json:"notes"`
+	MissingStatus   string         `json:"missing_status" validate:"omitempty,oneof=active inactive maintenance"`
+	UpdateLocations bool           `json:"update_locations"`
+	Fixes           []InventoryFix `json:"fixes" validate:"dive"`
+}
+
+// InventoryReconciliationItem is one line of a reconciliation report. Reasons explain a wrong
+// location result: department or location. InScope is false for assets found that the
+// campaign's scope does not cover.
+type InventoryReconciliationItem struct {
+	Result  string         `json:"result"`
+	Asset   *Asset         `json:"asset,omitempty"`
+	Scan    *InventoryScan `json:"scan,omitempty"`
+	Reasons []string       `json:"reasons,omitempty"`
+	InScope bool           `json:"in_scope"`
+}
+
+// InventoryReconciliation compares the scans of a campaign with the register
+type InventoryReconciliation struct {
+	Campaign      *InventoryCampaign            `json:"campaign"`
+	Summary       map[string]int                `json:"summary"`
+	Found         []InventoryReconciliationItem `json:"found"`
+	Missing       []InventoryReconciliationItem `json:"missing"`
+	WrongLocation []InventoryReconciliationItem `json:"wrong_location"`
+	NotInRegister []InventoryReconciliationItem `json:"not_in_register"`
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Maintenance plan schedule types
+const (
+	ScheduleTypeInterval = "interval"
+	ScheduleTypeCalendar = "calendar"
+)
+
+// Work order statuses
+const (
+	WorkOrderOpen       = "open"
+	WorkOrderInProgress = "in_progress"
+	WorkOrderOnHold     = "on_hold"
+	WorkOrderCompleted  = "completed"
+	WorkOrderCancelled  = "cancelled"
+)
+
+// MaintenancePlan represents a preventive maintenance plan for an asset (ISO 55001 - Maintenance Management)
+type MaintenancePlan struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	AssetID     uuid.UUID `json:"asset_id" gorm:"type:uuid;not null;index"`
+	Asset       *Asset    `json:"asset,omitempty" gorm:"foreignKey:AssetID"`
+	Name        string    `json:"name" gorm:"type:varchar(255);not null"`
+	Description string    `json:"description" gorm:"type:text"`
+
+	// Recurrence - interval plans repeat a number of days after the last completion,
+	// calendar plans follow an RRULE anchored at StartDate
+	ScheduleType string    `json:"schedule_type" gorm:"type:varchar(20);not null;check:schedule_type IN ('interval', 'calendar')"`
+	IntervalDays *int      `json:"interval_days" gorm:"type:integer"`
+	RRule        string    `json:"rrule" gorm:"type:varchar(255)"`
+	StartDate    time.Time `json:"start_date" gorm:"not null"`
+	LeadTimeDays int       `json:"lead_time_days" gorm:"type:integer;default:0"`
+
+	// Work order defaults
+	DefaultAssigneeID *uuid.UUID `json:"default_assignee_id" gorm:"type:uuid"`
+	DefaultAssignee   *User      `json:"default_assignee,omitempty" gorm:"foreignKey:DefaultAssigneeID"`
+
+	// Scheduling state
+	IsActive        bool       `json:"is_active" gorm:"default:true"`
+	NextDueAt       *time.Time `json:"next_due_at" gorm:"index"`
+	LastGeneratedAt *time.Time `json:"last_generated_at"`
+	LastCompletedAt *time.Time `json:"last_completed_at"`
+
+	// Metadata
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (p *MaintenancePlan) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for MaintenancePlan
+func (MaintenancePlan) TableName() string {
+	return "maintenance_plans"
+}
+
+// WorkOrder represents a unit of maintenance work on an asset
+type WorkOrder struct {
+	ID          uuid.UUID        `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	AssetID     uuid.UUID        `json:"asset_id" gorm:"type:uuid;not null;index"`
+	Asset       *Asset           `json:"asset,omitempty" gorm:"foreignKey:AssetID"`
+	PlanID      *uuid.UUID       `json:"plan_id" gorm:"type:uuid;index"`
+	Plan        *MaintenancePlan `json:"plan,omitempty" gorm:"foreignKey:PlanID"`
+	Title       string           `json:"title" gorm:"type:varchar(255);not null"`
+	Description string           `json:"description" gorm:"type:text"`
+
+	// Assignment and lifecycle
+	AssigneeID  *uuid.UUID `json:"assignee_id" gorm:"type:uuid;index"`
+	Assignee    *User      `json:"assignee,omitempty" gorm:"foreignKey:AssigneeID"`
+	DueDate     *time.Time `json:"due_date" gorm:"index"`
+	Status      string     `json:"status" gorm:"type:varchar(20);not null;default:'open';index;check:status IN ('open', 'in_progress', 'on_hold', 'completed', 'cancelled')"`
+	StartedAt   *time.Time `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+
+	// Cost and completion
+	LabourHours     float64    `json:"labour_hours" gorm:"type:decimal(10,2)"`
+	LabourCost      float64    `json:"labour_cost" gorm:"type:decimal(15,2)"`
+	PartsCost       float64    `json:"parts_cost" gorm:"type:decimal(15,2)"`
+	CompletionNotes string     `json:"completion_notes" gorm:"type:text"`
+	CompletedByID   *uuid.UUID `json:"completed_by_id" gorm:"type:uuid"`
+
+	// Metadata
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (w *WorkOrder) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	if w.Status == "" {
+		w.Status = WorkOrderOpen
+	}
+	return nil
+}
+
+// TableName specifies the table name for WorkOrder
+func (WorkOrder) TableName() string {
+	return "work_orders"
+}
+
+// TotalCost returns the combined labour and parts cost of the work order
+func (w *WorkOrder) TotalCost() float64 {
+	return w.LabourCost + w.PartsCost
+}
+
+// IsClosed reports whether the work order has reached a final status
+func (w *WorkOrder) IsClosed() bool {
+	return w.Status == WorkOrderCompleted || w.Status == WorkOrderCancelled
+}
+
+// WorkOrderStatusRequest represents a status change on a work order
+type WorkOrderStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=open in_progress on_hold cancelled"`
+	Notes  string `json:"notes"`
+}
+
+// WorkOrderCompleteRequest represents the data recorded when a work order is completed
+type WorkOrderCompleteRequest struct {
+	LabourHours     float64 `json:"labour_hours" validate:"gte=0"`
+	LabourCost      float64 `json:"labour_cost" validate:"gte=0"`
+	PartsCost       float64 `json:"parts_cost" validate:"gte=0"`
+	CompletionNotes string  `json:"completion_notes"`
+	// AssetStatus is applied to an asset in maintenance once no other work order on it is
+	// in progress; defaults to active, and "maintenance" keeps the asset where it is
+	AssetStatus string `json:"asset_status" validate:"omitempty,oneof=active inactive maintenance"`
+}
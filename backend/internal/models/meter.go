@@ -0,0 +1,202 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Meter kinds
+const (
+	// MeterCumulative meters only count up, such as run-hours or an odometer
+	MeterCumulative = "cumulative"
+	// MeterGauge meters measure a level that goes up and down, such as a temperature
+	MeterGauge = "gauge"
+)
+
+// Meter measures the usage or condition of an asset through readings taken over time
+type Meter struct {
+	ID      uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	AssetID uuid.UUID `json:"asset_id" gorm:"type:uuid;not null;uniqueIndex:idx_meters_asset_name,where:deleted_at IS NULL"`
+	Asset   *Asset    `json:"asset,omitempty" gorm:"foreignKey:AssetID"`
+	Name    string    `json:"name" gorm:"type:varchar(100);not null;uniqueIndex:idx_meters_asset_name,where:deleted_at IS NULL"`
+	// Unit is what the meter counts, such as hours, km or cycles
+	Unit string `json:"unit" gorm:"type:varchar(20);not null"`
+	Kind string `json:"kind" gorm:"type:varchar(20);not null;default:'cumulative';check:kind IN ('cumulative', 'gauge')"`
+
+	// Latest reading
+	LastValue  *float64   `json:"last_value" gorm:"type:decimal(15,3)"`
+	LastReadAt *time.Time `json:"last_read_at"`
+
+	Rules []MeterRule `json:"rules,omitempty" gorm:"foreignKey:MeterID"`
+
+	// Metadata
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (m *Meter) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	if m.Kind == "" {
+		m.Kind = MeterCumulative
+	}
+	return nil
+}
+
+// TableName specifies the table name for Meter
+func (Meter) TableName() string {
+	return "meters"
+}
+
+// MeterReading is a value read from a meter at a point in time
+type MeterReading struct {
+	ID      uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	MeterID uuid.UUID `json:"meter_id" gorm:"type:uuid;not null;index:idx_meter_readings_meter_time,priority:1"`
+	// AssetID is the meter's asset, kept so an asset's readings go with it
+	AssetID      uuid.UUID  `json:"asset_id" gorm:"type:uuid;not null;index"`
+	Value        float64    `json:"value" gorm:"type:decimal(15,3);not null"`
+	ReadAt       time.Time  `json:"read_at" gorm:"not null;index:idx_meter_readings_meter_time,priority:2"`
+	Notes        string     `json:"notes" gorm:"type:text"`
+	RecordedByID *uuid.UUID `json:"recorded_by_id" gorm:"type:uuid"`
+	RecordedBy   *User      `json:"recorded_by,omitempty" gorm:"foreignKey:RecordedByID"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (r *MeterReading) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for MeterReading
+func (MeterReading) TableName() string {
+	return "meter_readings"
+}
+
+// MeterRule makes maintenance due every Interval units of a cumulative meter, counted from
+// StartValue, so a rule every 500 hours from 0 falls due at 500, 1000, 1500 and so on. The
+// event is raised LeadValue units before the due value is reached.
+type MeterRule struct {
+	ID      uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	MeterID uuid.UUID `json:"meter_id" gorm:"type:uuid;not null;index"`
+	// AssetID is the meter's asset, kept so an asset's rules go with it
+	AssetID     uuid.UUID `json:"asset_id" gorm:"type:uuid;not null;index"`
+	Name        string    `json:"name" gorm:"type:varchar(255);not null"`
+	Description string    `json:"description" gorm:"type:text"`
+
+	Interval   float64 `json:"interval" gorm:"type:decimal(15,3);not null"`
+	StartValue float64 `json:"start_value" gorm:"type:decimal(15,3);not null;default:0"`
+	LeadValue  float64 `json:"lead_value" gorm:"type:decimal(15,3);not null;default:0"`
+
+	// Work orders - when CreateWorkOrder is set each event opens a work order named after the rule
+	CreateWorkOrder   bool       `json:"create_work_order" gorm:"not null;default:false"`
+	DefaultAssigneeID *uuid.UUID `json:"default_assignee_id" gorm:"type:uuid"`
+	DefaultAssignee   *User      `json:"default_assignee,omitempty" gorm:"foreignKey:DefaultAssigneeID"`
+
+	// Scheduling state
+	IsActive        bool       `json:"is_active" gorm:"default:true"`
+	NextDueValue    float64    `json:"next_due_value" gorm:"type:decimal(15,3);not null"`
+	LastTriggeredAt *time.Time `json:"last_triggered_at"`
+
+	// Metadata
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (r *MeterRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for MeterRule
+func (MeterRule) TableName() string {
+	return "meter_rules"
+}
+
+// Maintenance due event statuses
+const (
+	MaintenanceDueOpen         = "open"
+	MaintenanceDueAcknowledged = "acknowledged"
+)
+
+// MaintenanceDueEvent reports that a meter reading reached the due value of a meter rule. One
+// event is raised per rule and due value.
+type MaintenanceDueEvent struct {
+	ID       uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	RuleID   uuid.UUID  `json:"rule_id" gorm:"type:uuid;not null;uniqueIndex:idx_maintenance_due_events_rule_value"`
+	Rule     *MeterRule `json:"rule,omitempty" gorm:"foreignKey:RuleID"`
+	MeterID  uuid.UUID  `json:"meter_id" gorm:"type:uuid;not null;index"`
+	Meter    *Meter     `json:"meter,omitempty" gorm:"foreignKey:MeterID"`
+	AssetID  uuid.UUID  `json:"asset_id" gorm:"type:uuid;not null;index"`
+	Asset    *Asset     `json:"asset,omitempty" gorm:"foreignKey:AssetID"`
+	DueValue float64    `json:"due_value" gorm:"type:decimal(15,3);not null;uniqueIndex:idx_maintenance_due_events_rule_value"`
+	// ReadingValue and ReadAt are the reading that raised the event
+	ReadingValue float64    `json:"reading_value" gorm:"type:decimal(15,3);not null"`
+	ReadAt       time.Time  `json:"read_at" gorm:"not null"`
+	WorkOrderID  *uuid.UUID `json:"work_order_id" gorm:"type:uuid;index"`
+	WorkOrder    *WorkOrder `json:"work_order,omitempty" gorm:"foreignKey:WorkOrderID"`
+	Status       string     `json:"status" gorm:"type:varchar(20);not null;default:'open';index;check:status IN ('open', 'acknowledged')"`
+
+	AcknowledgedByID *uuid.UUID `json:"acknowledged_by_id" gorm:"type:uuid"`
+	AcknowledgedBy   *User      `json:"acknowledged_by,omitempty" gorm:"foreignKey:AcknowledgedByID"`
+	AcknowledgedAt   *time.Time `json:"acknowledged_at"`
+
+	// Metadata
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (e *MaintenanceDueEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	if e.Status == "" {
+		e.Status = MaintenanceDueOpen
+	}
+	return nil
+}
+
+// TableName specifies the table name for MaintenanceDueEvent
+func (MaintenanceDueEvent) TableName() string {
+	return "maintenance_due_events"
+}
+
+// MeterRequest represents the data needed to create or replace a meter
+type MeterRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+	Unit string `json:"unit" validate:"required,max=20"`
+	Kind string `json:"kind" validate:"omitempty,oneof=cumulative gauge"`
+}
+
+// MeterReadingRequest represents a reading submitted for a meter. ReadAt defaults to now and is
+// given for readings taken earlier.
+type MeterReadingRequest struct {
+	Value  float64    `json:"value"`
+	ReadAt *time.Time `json:"read_at"`
+	Notes  string     `json:"notes"`
+}
+
+// MeterRuleRequest represents the data needed to create or replace a meter rule. StartValue
+// defaults to the meter's latest reading.
+type MeterRuleRequest struct {
+	Name              string     `json:"name" validate:"required,max=255"`
+	Description       string     `json:"description"`
+	Interval          float64    `json:"interval" validate:"gt=0"`
+	StartValue        *float64   `json:"start_value" validate:"omitempty,gte=0"`
+	LeadValue         float64    `json:"lead_value" validate:"gte=0,ltfield=Interval"`
+	CreateWorkOrder   bool       `json:"create_work_order"`
+	DefaultAssigneeID *uuid.UUID `json:"default_assignee_id"`
+	IsActive          *bool      `json:"is_active"`
+}
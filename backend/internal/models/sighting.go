@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AssetSighting records an asset being seen, usually by scanning its QR code or barcode
+type AssetSighting struct {
+	ID      uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	AssetID uuid.UUID `json:"asset_id" gorm:"type:uuid;not null;index"`
+	Asset   *Asset    `json:"asset,omitempty" gorm:"foreignKey:AssetID"`
+	// Payload is the scanned text that identified the asset
+	Payload     string     `json:"payload" gorm:"type:text"`
+	ScannedByID *uuid.UUID `json:"scanned_by_id" gorm:"type:uuid;index"`
+	ScannedBy   *User      `json:"scanned_by,omitempty" gorm:"foreignKey:ScannedByID"`
+	ScannedAt   time.Time  `json:"scanned_at" gorm:"not null;index"`
+
+	// Optional observations made with the scan
+	Latitude  *float64 `json:"latitude" gorm:"type:decimal(10,8)"`
+	Longitude *float64 `json:"longitude" gorm:"type:decimal(11,8)"`
+	Condition string   `json:"condition" gorm:"type:varchar(50)"`
+	Notes     string   `json:"notes" gorm:"type:text"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (s *AssetSighting) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for AssetSighting
+func (AssetSighting) TableName() string {
+	return "asset_sightings"
+}
+
+// ScanRequest represents a scanned code and what was observed with it
+type ScanRequest struct {
+	// Payload is a QR deep link, asset ID, serial number or tag number
+	Payload   string   `json:"payload" validate:"required,max=500"`
+	Latitude  *float64 `json:"latitude" validate:"omitempty,latitude"`
+	Longitude *float64 `json:"longitude" validate:"omitempty,longitude"`
+	Condition string   `json:"condition" validate:"omitempty,oneof=excellent good fair poor critical"`
+	Notes     string   `json:"notes"`
+	// ScannedAt is when a scan made offline happened; it defaults to now
+	ScannedAt *time.Time `json:"scanned_at"`
+}
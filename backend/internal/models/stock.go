@@ -0,0 +1,188 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Stock movement kinds
+const (
+	StockReceive = "receive"
+	StockIssue   = "issue"
+	StockAdjust  = "adjust"
+)
+
+// Part is a spare part or consumable kept in stock for maintenance
+type Part struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	PartNumber  string    `json:"part_number" gorm:"type:varchar(100);not null;uniqueIndex:idx_parts_part_number,where:deleted_at IS NULL"`
+	Name        string    `json:"name" gorm:"type:varchar(255);not null"`
+	Description string    `json:"description" gorm:"type:text"`
+	// Unit is what quantities are counted in, such as each, litre or metre
+	Unit string `json:"unit" gorm:"type:varchar(20);not null;default:'each'"`
+	// UnitCost is the moving average cost of the part, updated on every receipt and charged on
+	// every issue
+	UnitCost   float64    `json:"unit_cost" gorm:"type:decimal(15,2);default:0"`
+	SupplierID *uuid.UUID `json:"supplier_id" gorm:"type:uuid;index"`
+	Supplier   *Vendor    `json:"supplier,omitempty" gorm:"foreignKey:SupplierID"`
+
+	Levels []StockLevel `json:"levels,omitempty" gorm:"foreignKey:PartID"`
+
+	// Metadata
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (p *Part) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	if p.Unit == "" {
+		p.Unit = "each"
+	}
+	return nil
+}
+
+// TableName specifies the table name for Part
+func (Part) TableName() string {
+	return "parts"
+}
+
+// StockLocation is a store room, shelf or van where parts are kept
+type StockLocation struct {
+	ID           uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Name         string    `json:"name" gorm:"type:varchar(100);not null;uniqueIndex:idx_stock_locations_name,where:deleted_at IS NULL"`
+	BuildingRoom string    `json:"building_room" gorm:"type:varchar(100)"`
+	Description  string    `json:"description" gorm:"type:text"`
+
+	// Metadata
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (l *StockLocation) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for StockLocation
+func (StockLocation) TableName() string {
+	return "stock_locations"
+}
+
+// StockLevel is the quantity of a part on hand at a location and the rule for reordering it:
+// once the quantity falls to the reorder point, the reorder quantity is due to be ordered. A
+// reorder point of zero turns the rule off.
+type StockLevel struct {
+	ID              uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	PartID          uuid.UUID      `json:"part_id" gorm:"type:uuid;not null;uniqueIndex:idx_stock_levels_part_location"`
+	Part            *Part          `json:"part,omitempty" gorm:"foreignKey:PartID"`
+	LocationID      uuid.UUID      `json:"location_id" gorm:"type:uuid;not null;uniqueIndex:idx_stock_levels_part_location;index"`
+	Location        *StockLocation `json:"location,omitempty" gorm:"foreignKey:LocationID"`
+	QuantityOnHand  float64        `json:"quantity_on_hand" gorm:"type:decimal(15,3);not null;default:0;check:quantity_on_hand >= 0"`
+	ReorderPoint    float64        `json:"reorder_point" gorm:"type:decimal(15,3);not null;default:0"`
+	ReorderQuantity float64        `json:"reorder_quantity" gorm:"type:decimal(15,3);not null;default:0"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (s *StockLevel) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for StockLevel
+func (StockLevel) TableName() string {
+	return "stock_levels"
+}
+
+// StockMovement records a change to the quantity of a part at a location. Quantity is positive
+// for stock coming in and negative for stock going out; movements are never edited, so the
+// movements of a part and location add up to its quantity on hand.
+type StockMovement struct {
+	ID         uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	PartID     uuid.UUID      `json:"part_id" gorm:"type:uuid;not null;index"`
+	Part       *Part          `json:"part,omitempty" gorm:"foreignKey:PartID"`
+	LocationID uuid.UUID      `json:"location_id" gorm:"type:uuid;not null;index"`
+	Location   *StockLocation `json:"location,omitempty" gorm:"foreignKey:LocationID"`
+	Kind       string         `json:"kind" gorm:"type:varchar(20);not null;index;check:kind IN ('receive', 'issue', 'adjust')"`
+	Quantity   float64        `json:"quantity" gorm:"type:decimal(15,3);not null"`
+	// QuantityAfter is the quantity on hand at the location once the movement was applied
+	QuantityAfter float64 `json:"quantity_after" gorm:"type:decimal(15,3);not null"`
+	// UnitCost is the purchase price for a receipt and the average cost charged for an issue
+	UnitCost float64 `json:"unit_cost" gorm:"type:decimal(15,2);default:0"`
+
+	// What an issue was used on
+	AssetID     *uuid.UUID `json:"asset_id" gorm:"type:uuid;index"`
+	Asset       *Asset     `json:"asset,omitempty" gorm:"foreignKey:AssetID"`
+	WorkOrderID *uuid.UUID `json:"work_order_id" gorm:"type:uuid;index"`
+	WorkOrder   *WorkOrder `json:"work_order,omitempty" gorm:"foreignKey:WorkOrderID"`
+
+	// Reference is the purchase order, delivery note or count sheet behind the movement
+	Reference   string     `json:"reference" gorm:"type:varchar(100)"`
+	Notes       string     `json:"notes" gorm:"type:text"`
+	CreatedByID *uuid.UUID `json:"created_by_id" gorm:"type:uuid"`
+	CreatedBy   *User      `json:"created_by,omitempty" gorm:"foreignKey:CreatedByID"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (m *StockMovement) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for StockMovement
+func (StockMovement) TableName() string {
+	return "stock_movements"
+}
+
+// PartRequest represents the data needed to create or replace a part
+type PartRequest struct {
+	PartNumber  string     `json:"part_number" validate:"required,max=100"`
+	Name        string     `json:"name" validate:"required,max=255"`
+	Description string     `json:"description"`
+	Unit        string     `json:"unit" validate:"max=20"`
+	UnitCost    float64    `json:"unit_cost" validate:"gte=0"`
+	SupplierID  *uuid.UUID `json:"supplier_id"`
+}
+
+// StockLocationRequest represents the data needed to create or replace a stock location
+type StockLocationRequest struct {
+	Name         string `json:"name" validate:"required,max=100"`
+	BuildingRoom string `json:"building_room" validate:"max=100"`
+	Description  string `json:"description"`
+}
+
+// StockRuleRequest sets the reorder rule for a part at a location
+type StockRuleRequest struct {
+	ReorderPoint    float64 `json:"reorder_point" validate:"gte=0"`
+	ReorderQuantity float64 `json:"reorder_quantity" validate:"gte=0"`
+}
+
+// StockMovementRequest represents a receipt, issue or adjustment of stock. Quantity is the
+// amount received or issued, and the signed change for an adjustment. An issue is made to an
+// asset or to a work order, whose asset it is charged to.
+type StockMovementRequest struct {
+	Kind        string     `json:"kind" validate:"required,oneof=receive issue adjust"`
+	PartID      uuid.UUID  `json:"part_id" validate:"required"`
+	LocationID  uuid.UUID  `json:"location_id" validate:"required"`
+	Quantity    float64    `json:"quantity" validate:"required"`
+	UnitCost    *float64   `json:"unit_cost" validate:"omitempty,gte=0"`
+	AssetID     *uuid.UUID `json:"asset_id"`
+	WorkOrderID *uuid.UUID `json:"work_order_id"`
+	Reference   string     `json:"reference" validate:"max=100"`
+	Notes       string     `json:"notes"`
+}
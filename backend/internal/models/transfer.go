@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Asset transfer statuses
+const (
+	TransferPending   = "pending"
+	TransferAccepted  = "accepted"
+	TransferRejected  = "rejected"
+	TransferCancelled = "cancelled"
+)
+
+// AssetTransfer represents a request to move an asset from one department to another. The asset
+// only changes department when the receiving department accepts (ISO 55001 - Asset Custody)
+type AssetTransfer struct {
+	ID               uuid.UUID   `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	AssetID          uuid.UUID   `json:"asset_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_asset_transfers_pending,where:status = 'pending'"`
+	Asset            *Asset      `json:"asset,omitempty" gorm:"foreignKey:AssetID"`
+	FromDepartmentID *uuid.UUID  `json:"from_department_id" gorm:"type:uuid;index"`
+	FromDepartment   *Department `json:"from_department,omitempty" gorm:"foreignKey:FromDepartmentID"`
+	ToDepartmentID   uuid.UUID   `json:"to_department_id" gorm:"type:uuid;not null;index"`
+	ToDepartment     *Department `json:"to_department,omitempty" gorm:"foreignKey:ToDepartmentID"`
+	Status           string      `json:"status" gorm:"type:varchar(20);not null;default:'pending';index;check:status IN ('pending', 'accepted', 'rejected', 'cancelled')"`
+
+	// Request
+	Reason        string     `json:"reason" gorm:"type:text"`
+	RequestedByID *uuid.UUID `json:"requested_by_id" gorm:"type:uuid"`
+	RequestedBy   *User      `json:"requested_by,omitempty" gorm:"foreignKey:RequestedByID"`
+
+	// Response
+	RespondedByID *uuid.UUID `json:"responded_by_id" gorm:"type:uuid"`
+	RespondedBy   *User      `json:"responded_by,omitempty" gorm:"foreignKey:RespondedByID"`
+	RespondedAt   *time.Time `json:"responded_at"`
+	ResponseNotes string     `json:"response_notes" gorm:"type:text"`
+
+	// Handover record, captured when the transfer is accepted
+	HandoverAddress      string   `json:"handover_address" gorm:"type:text"`
+	HandoverBuildingRoom string   `json:"handover_building_room" gorm:"type:varchar(100)"`
+	HandoverLatitude     *float64 `json:"handover_latitude" gorm:"type:decimal(10,8)"`
+	HandoverLongitude    *float64 `json:"handover_longitude" gorm:"type:decimal(11,8)"`
+	HandoverCondition    string   `json:"handover_condition" gorm:"type:varchar(20)"`
+	HandoverValue        float64  `json:"handover_value" gorm:"type:decimal(15,2)"`
+
+	// Metadata
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (t *AssetTransfer) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	if t.Status == "" {
+		t.Status = TransferPending
+	}
+	return nil
+}
+
+// TableName specifies the table name for AssetTransfer
+func (AssetTransfer) TableName() string {
+	return "asset_transfers"
+}
+
+// TransferCreateRequest represents the data needed to request an inter-department transfer
+type TransferCreateRequest struct {
+	ToDepartmentID uuid.UUID `json:"to_department_id" validate:"required"`
+	Reason         string    `json:"reason" validate:"required"`
+}
+
+// TransferResponseRequest represents the receiving department's response to a transfer. On
+// acceptance the optional location and condition describe the asset as it was handed over.
+type TransferResponseRequest struct {
+	Notes        string   `json:"notes"`
+	Address      string   `json:"address"`
+	BuildingRoom string   `json:"building_room" validate:"max=100"`
+	Latitude     *float64 `json:"latitude" validate:"omitempty,gte=-90,lte=90"`
+	Longitude    *float64 `json:"longitude" validate:"omitempty,gte=-180,lte=180"`
+	Condition    string   `json:"condition" validate:"omitempty,oneof=excellent good fair poor critical"`
+}
@@ -10,8 +10,8 @@ import (
 // User represents a system user
 type User struct {
 	ID           uuid.UUID      `gorm:"type:uuid;primary_key;" json:"id"`
-	Username     string         `gorm:"type:varchar(50);not null;unique" json:"username"`
-	Email        string         `gorm:"type:varchar(100);not null;unique" json:"email"`
+	Username     string         `gorm:"type:varchar(50);not null;uniqueIndex:idx_users_username,where:deleted_at IS NULL" json:"username"`
+	Email        string         `gorm:"type:varchar(100);not null;uniqueIndex:idx_users_email,where:deleted_at IS NULL" json:"email"`
 	FirstName    string         `gorm:"type:varchar(50);not null" json:"first_name"`
 	LastName     string         `gorm:"type:varchar(50);not null" json:"last_name"`
 	Password     string         `gorm:"type:varchar(255);not null" json:"-"`                  // Hidden from JSON
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Vendor is a company the organisation deals with as a manufacturer of its assets, a supplier
+// they were bought from or a provider of services such as maintenance contracts. A vendor can
+// hold several roles.
+type Vendor struct {
+	ID   uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Name string    `json:"name" gorm:"type:varchar(255);not null;uniqueIndex:idx_vendors_name,where:deleted_at IS NULL"`
+
+	// Roles
+	IsManufacturer    bool `json:"is_manufacturer" gorm:"not null;default:false;index"`
+	IsSupplier        bool `json:"is_supplier" gorm:"not null;default:false;index"`
+	IsServiceProvider bool `json:"is_service_provider" gorm:"not null;default:false;index"`
+
+	// Company details
+	Website string `json:"website" gorm:"type:varchar(255)"`
+	Email   string `json:"email" gorm:"type:varchar(100)"`
+	Phone   string `json:"phone" gorm:"type:varchar(50)"`
+	Address string `json:"address" gorm:"type:text"`
+	Notes   string `json:"notes" gorm:"type:text"`
+
+	Contacts []VendorContact `json:"contacts,omitempty" gorm:"foreignKey:VendorID"`
+
+	// Metadata
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (v *Vendor) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for Vendor
+func (Vendor) TableName() string {
+	return "vendors"
+}
+
+// VendorContact is a person to reach at a vendor
+type VendorContact struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	VendorID  uuid.UUID `json:"vendor_id" gorm:"type:uuid;not null;index"`
+	Name      string    `json:"name" gorm:"type:varchar(255);not null"`
+	Title     string    `json:"title" gorm:"type:varchar(100)"`
+	Email     string    `json:"email" gorm:"type:varchar(100)"`
+	Phone     string    `json:"phone" gorm:"type:varchar(50)"`
+	IsPrimary bool      `json:"is_primary" gorm:"not null;default:false"`
+	Notes     string    `json:"notes" gorm:"type:text"`
+
+	// Metadata
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID
+func (vc *VendorContact) BeforeCreate(tx *gorm.DB) error {
+	if vc.ID == uuid.Nil {
+		vc.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name for VendorContact
+func (VendorContact) TableName() string {
+	return "vendor_contacts"
+}
+
+// VendorRequest represents the data needed to create or replace a vendor
+type VendorRequest struct {
+	Name              string `json:"name" validate:"required,max=255"`
+	IsManufacturer    bool   `json:"is_manufacturer"`
+	IsSupplier        bool   `json:"is_supplier"`
+	IsServiceProvider bool   `json:"is_service_provider"`
+	Website           string `json:"website" validate:"max=255"`
+	Email             string `json:"email" validate:"omitempty,email,max=100"`
+	Phone             string `json:"phone" validate:"max=50"`
+	Address           string `json:"address"`
+	Notes             string `json:"notes"`
+}
+
+// VendorContactRequest represents the data needed to create or replace a vendor contact
+type VendorContactRequest struct {
+	Name      string `json:"name" validate:"required,max=255"`
+	Title     string `json:"title" validate:"max=100"`
+	Email     string `json:"email" validate:"omitempty,email,max=100"`
+	Phone     string `json:"phone" validate:"max=50"`
+	IsPrimary bool   `json:"is_primary"`
+	Notes     string `json:"notes"`
+}
@@ -0,0 +1,242 @@
+// Package search implements relevance-ranked search over assets, categories, departments and
+// users. Words are matched with PostgreSQL full-text search, and pg_trgm word similarity catches
+// typos and partial serial numbers that full-text search misses.
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// The simple configuration lowercases without stemming, which suits names, serial numbers and
+// descriptions written in more than one language
+const textSearchConfig = "simple"
+
+// headlineOptions wrap matched words in <mark> tags and keep snippets short
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MinWords=5, MaxWords=20, MaxFragments=2, FragmentDelimiter=\" … \""
+
+// assetVector is the weighted document stored in assets.search_vector: name and serial number
+// rank highest, then model and manufacturer, then description and address
+const assetVector = "setweight(to_tsvector('simple', coalesce(name, '') || ' ' || coalesce(serial_number, '')), 'A') || " +
+	"setweight(to_tsvector('simple', coalesce(model, '') || ' ' || coalesce(manufacturer, '')), 'B') || " +
+	"setweight(to_tsvector('simple', coalesce(description, '') || ' ' || coalesce(address, '')), 'C')"
+
+// setupStatements create the pg_trgm extension, the asset search column and the search indexes
+var setupStatements = []string{
+	"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+	"ALTER TABLE assets ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (" + assetVector + ") STORED",
+	"CREATE INDEX IF NOT EXISTS idx_assets_search_vector ON assets USING gin (search_vector)",
+	"CREATE INDEX IF NOT EXISTS idx_assets_name_trgm ON assets USING gin (name gin_trgm_ops)",
+	"CREATE INDEX IF NOT EXISTS idx_assets_serial_number_trgm ON assets USING gin (serial_number gin_trgm_ops)",
+	"CREATE INDEX IF NOT EXISTS idx_assets_model_trgm ON assets USING gin (model gin_trgm_ops)",
+	"CREATE INDEX IF NOT EXISTS idx_assets_manufacturer_trgm ON assets USING gin (manufacturer gin_trgm_ops)",
+	"CREATE INDEX IF NOT EXISTS idx_categories_name_trgm ON categories USING gin (name gin_trgm_ops)",
+	"CREATE INDEX IF NOT EXISTS idx_departments_name_trgm ON departments USING gin (name gin_trgm_ops)",
+	"CREATE INDEX IF NOT EXISTS idx_users_username_trgm ON users USING gin (username gin_trgm_ops)",
+	"CREATE INDEX IF NOT EXISTS idx_users_email_trgm ON users USING gin (email gin_trgm_ops)",
+}
+
+// Setup prepares the database for searching. It runs after AutoMigrate and is safe to repeat.
+func Setup(db *gorm.DB) error {
+	for _, statement := range setupStatements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Query is a parsed search term
+type Query struct {
+	// term is the trimmed input, compared by trigram similarity
+	term string
+	// tsquery matches every word of the input as a prefix, such as "pump:* & 3000:*"
+	tsquery string
+}
+
+// Parse turns user input into a query. Punctuation separates words.
+func Parse(input string) Query {
+	words := strings.FieldsFunc(input, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return Query{term: strings.TrimSpace(input), tsquery: strings.Join(words, " & ")}
+}
+
+// Empty reports whether the query has nothing to search for
+func (q Query) Empty() bool {
+	return q.tsquery == ""
+}
+
+// Source is a searchable table
+type Source struct {
+	// Type names the source in search results, such as "assets"
+	Type  string
+	table string
+	// vector is the tsvector expression of a row
+	vector string
+	// fuzzy lists the columns compared to the term by trigram word similarity
+	fuzzy []string
+	// title and subtitle label a row in search results, and document is the text snippets are
+	// taken from
+	title, subtitle, document string
+}
+
+// Sources lists the searchable tables in the order results are returned
+var Sources = []*Source{Assets, Categories, Departments, Users}
+
+// Assets searches assets through the indexed search_vector column
+var Assets = &Source{
+	Type:     "assets",
+	table:    "assets",
+	vector:   "search_vector",
+	fuzzy:    []string{"name", "serial_number", "model", "manufacturer"},
+	title:    "name",
+	subtitle: "serial_number",
+	document: "concat_ws(' · ', name, serial_number, model, manufacturer, description, address)",
+}
+
+// Categories searches category names and descriptions
+var Categories = &Source{
+	Type:     "categories",
+	table:    "categories",
+	vector:   "setweight(to_tsvector('simple', name), 'A') || setweight(to_tsvector('simple', coalesce(description, '')), 'C')",
+	fuzzy:    []string{"name"},
+	title:    "name",
+	subtitle: "''",
+	document: "concat_ws(' · ', name, description)",
+}
+
+// Departments searches department names and descriptions
+var Departments = &Source{
+	Type:     "departments",
+	table:    "departments",
+	vector:   "setweight(to_tsvector('simple', name), 'A') || setweight(to_tsvector('simple', coalesce(description, '')), 'C')",
+	fuzzy:    []string{"name"},
+	title:    "name",
+	subtitle: "''",
+	document: "concat_ws(' · ', name, description)",
+}
+
+// Users searches user names, usernames and email addresses
+var Users = &Source{
+	Type:     "users",
+	table:    "users",
+	vector:   "to_tsvector('simple', username || ' ' || first_name || ' ' || last_name || ' ' || email)",
+	fuzzy:    []string{"username", "email"},
+	title:    "first_name || ' ' || last_name",
+	subtitle: "username",
+	document: "concat_ws(' · ', first_name || ' ' || last_name, username, email)",
+}
+
+// Lookup returns the source with the given type name
+func Lookup(name string) (*Source, bool) {
+	for _, source := range Sources {
+		if source.Type == name {
+			return source, true
+		}
+	}
+	return nil, false
+}
+
+// Hit is one search result. Title and Subtitle are plain text.
+type Hit struct {
+	Type     string    `json:"type"`
+	ID       uuid.UUID `json:"id"`
+	Title    string    `json:"title"`
+	Subtitle string    `json:"subtitle,omitempty"`
+	// Snippet is an HTML excerpt with the matched words wrapped in <mark> tags
+	Snippet string  `json:"snippet"`
+	Rank    float64 `json:"rank"`
+}
+
+// match returns the condition selecting rows that match the query
+func (s *Source) match(q Query) clause.Expr {
+	conditions := []string{}
+	vars := []interface{}{}
+	if q.tsquery != "" {
+		conditions = append(conditions, "("+s.vector+") @@ to_tsquery('"+textSearchConfig+"', ?)")
+		vars = append(vars, q.tsquery)
+	}
+	for _, column := range s.fuzzy {
+		conditions = append(conditions, "? <% "+column)
+		vars = append(vars, q.term)
+	}
+	return clause.Expr{SQL: "(" + strings.Join(conditions, " OR ") + ")", Vars: vars}
+}
+
+// Rank returns the relevance of a row to q: its text search rank plus its best trigram similarity
+func (s *Source) Rank(q Query) clause.Expr {
+	similarities := make([]string, len(s.fuzzy))
+	vars := []interface{}{q.tsquery}
+	for i, column := range s.fuzzy {
+		similarities[i] = "word_similarity(?, coalesce(" + column + ", ''))"
+		vars = append(vars, q.term)
+	}
+	sql := fmt.Sprintf("(ts_rank((%s), to_tsquery('%s', ?)) + greatest(%s))", s.vector, textSearchConfig, strings.Join(similarities, ", "))
+	return clause.Expr{SQL: sql, Vars: vars}
+}
+
+// escapeHTML returns a SQL expression escaping the HTML special characters of a text expression
+func escapeHTML(expr string) string {
+	return "replace(replace(replace(replace(" + expr + ", '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '\"', '&quot;')"
+}
+
+// headline returns the snippet expression of a row. The document is HTML-escaped first, so the
+// <mark> tags are the only markup in the snippet.
+func (s *Source) headline(q Query) clause.Expr {
+	sql := fmt.Sprintf("ts_headline('%s', %s, to_tsquery('%s', ?), '%s')", textSearchConfig, escapeHTML(s.document), textSearchConfig, headlineOptions)
+	return clause.Expr{SQL: sql, Vars: []interface{}{q.tsquery}}
+}
+
+// Filter limits a query on the source's table to rows matching q
+func (s *Source) Filter(db *gorm.DB, q Query) *gorm.DB {
+	return db.Where(s.match(q))
+}
+
+// Snippets returns the snippet of each of the given rows, keyed by ID
+func (s *Source) Snippets(db *gorm.DB, q Query, ids []uuid.UUID) (map[uuid.UUID]string, error) {
+	snippets := map[uuid.UUID]string{}
+	if len(ids) == 0 {
+		return snippets, nil
+	}
+
+	var rows []struct {
+		ID      uuid.UUID
+		Snippet string
+	}
+	err := db.Table(s.table).Select("id, ? AS snippet", s.headline(q)).Where("id IN ?", ids).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		snippets[row.ID] = row.Snippet
+	}
+	return snippets, nil
+}
+
+// Search returns the rows of the source best matching q, at most limit of them
+func (s *Source) Search(db *gorm.DB, q Query, limit int) ([]Hit, error) {
+	hits := []Hit{}
+	err := db.Table(s.table).
+		Select("id, "+s.title+" AS title, "+s.subtitle+" AS subtitle, ? AS snippet, ? AS rank", s.headline(q), s.Rank(q)).
+		Where("deleted_at IS NULL").
+		Where(s.match(q)).
+		Order("rank DESC").
+		Limit(limit).
+		Scan(&hits).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range hits {
+		hits[i].Type = s.Type
+	}
+	return hits, nil
+}
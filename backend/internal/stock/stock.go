@@ -0,0 +1,75 @@
+// Package stock keeps the quantity on hand and the average cost of spare parts in step with the
+// movements of stock in and out of their locations
+package stock
+
+import (
+	"errors"
+	"math"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"sams-backend/internal/models"
+)
+
+// ErrInsufficientStock is returned for a movement that would take the quantity on hand at a
+// location below zero
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// round rounds a value to the given number of decimal places, matching the precision of the
+// column it is stored in
+func round(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
+
+// Move applies a stock movement whose Quantity already carries its sign. The part and its stock
+// level at the location are locked for the rest of the transaction, the level being created on
+// the first movement. A receipt is valued at its own UnitCost and updates the part's moving
+// average cost; issues and adjustments are valued at the average cost.
+func Move(tx *gorm.DB, movement *models.StockMovement) error {
+	var part models.Part
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&part, "id = ?", movement.PartID).Error; err != nil {
+		return err
+	}
+
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "part_id"}, {Name: "location_id"}},
+		DoNothing: true,
+	}).Create(&models.StockLevel{PartID: movement.PartID, LocationID: movement.LocationID}).Error
+	if err != nil {
+		return err
+	}
+	var level models.StockLevel
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&level, "part_id = ? AND location_id = ?", movement.PartID, movement.LocationID).Error; err != nil {
+		return err
+	}
+
+	quantity := round(level.QuantityOnHand+movement.Quantity, 3)
+	if quantity < 0 {
+		return ErrInsufficientStock
+	}
+
+	if movement.Kind == models.StockReceive {
+		var onHand float64
+		if err := tx.Model(&models.StockLevel{}).Select("COALESCE(SUM(quantity_on_hand), 0)").
+			Where("part_id = ?", part.ID).Scan(&onHand).Error; err != nil {
+			return err
+		}
+		if total := onHand + movement.Quantity; total > 0 {
+			average := round((onHand*part.UnitCost+movement.Quantity*movement.UnitCost)/total, 2)
+			if err := tx.Model(&part).Update("unit_cost", average).Error; err != nil {
+				return err
+			}
+		}
+	} else {
+		movement.UnitCost = part.UnitCost
+	}
+
+	if err := tx.Model(&level).Update("quantity_on_hand", quantity).Error; err != nil {
+		return err
+	}
+	movement.QuantityAfter = quantity
+	return tx.Create(movement).Error
+}
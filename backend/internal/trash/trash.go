@@ -0,0 +1,374 @@
+package trash
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"sams-backend/internal/database"
+	"sams-backend/internal/models"
+)
+
+// ConflictError is returned when a record cannot be restored because it would clash with live data
+type ConflictError struct {
+	Conflicts []string
+}
+
+func (e *ConflictError) Error() string {
+	return "cannot restore: " + strings.Join(e.Conflicts, "; ")
+}
+
+// RetainedError is returned when a record cannot be purged because records that must be kept,
+// such as a disposal register entry, still refer to it
+type RetainedError struct {
+	Records []string
+}
+
+func (e *RetainedError) Error() string {
+	return "cannot purge: " + strings.Join(e.Records, "; ")
+}
+
+// retainer is a model whose rows are kept for the record and so prevent their owner from being
+// purged
+type retainer struct {
+	model interface{}
+	label string
+}
+
+// Kind describes a soft-deleted model that can be listed, restored and purged from the trash
+type Kind struct {
+	Name string
+	// AdminOnly restricts listing and restoring to admins
+	AdminOnly bool
+
+	newRecord func() interface{}
+	newList   func() interface{}
+	// conflicts returns the reasons the deleted record cannot be restored, if any
+	conflicts func(db *gorm.DB, record interface{}) ([]string, error)
+	// dependents are operational models owned by the record, deleted with it on purge; they
+	// reference it through ownerKey, as do retainers
+	dependents []interface{}
+	// retainers are models kept for the record; the record cannot be purged while it has any
+	retainers []retainer
+	ownerKey  string
+	// versioned models get their version incremented on restore
+	versioned bool
+	// present converts a list of the kind's records for the response, for models that do not
+	// serialise their deleted_at
+	present func(list interface{}) interface{}
+}
+
+// deletedUser is a user in the trash as listed, with the time it was deleted
+type deletedUser struct {
+	models.User
+	DeletedAt gorm.DeletedAt `json:"deleted_at"`
+}
+
+// deletedDepartment is a department in the trash as listed, with the time it was deleted
+type deletedDepartment struct {
+	models.Department
+	DeletedAt gorm.DeletedAt `json:"deleted_at"`
+}
+
+// kinds lists the models with a trash, in the order they are purged: assets go first because
+// they reference categories and departments
+var kinds = []*Kind{
+	{
+		Name:      "assets",
+		newRecord: func() interface{} { return &models.Asset{} },
+		newList:   func() interface{} { return &[]models.Asset{} },
+		conflicts: assetConflicts,
+		// Maintenance-due events reference work orders, and work orders reference maintenance plans,
+		// so they are deleted first. The asset's history and status events are its audit trail and
+		// outlive it.
+		dependents: []interface{}{
+			&models.AssetCustody{}, &models.GeofenceAlert{}, &models.AssetSighting{},
+			&models.MaintenanceDueEvent{}, &models.MeterReading{}, &models.MeterRule{}, &models.Meter{},
+			&models.WorkOrder{}, &models.MaintenancePlan{},
+		},
+		retainers: []retainer{
+			{&models.DisposalRequest{}, "disposal requests"},
+			{&models.AssetTransfer{}, "transfers"},
+			{&models.InventoryScan{}, "inventory scans"},
+			{&models.ContractAsset{}, "contracts"},
+			{&models.StockMovement{}, "stock movements"},
+		},
+		ownerKey:  "asset_id",
+		versioned: true,
+	},
+	{
+		Name:      "users",
+		AdminOnly: true,
+		newRecord: func() interface{} { return &models.User{} },
+		newList:   func() interface{} { return &[]models.User{} },
+		conflicts: userConflicts,
+		present: func(list interface{}) interface{} {
+			users := *list.(*[]models.User)
+			deleted := make([]deletedUser, len(users))
+			for i, user := range users {
+				deleted[i] = deletedUser{User: user, DeletedAt: user.DeletedAt}
+			}
+			return deleted
+		},
+	},
+	{
+		Name:      "categories",
+		newRecord: func() interface{} { return &models.Category{} },
+		newList:   func() interface{} { return &[]models.Category{} },
+		conflicts: func(db *gorm.DB, record interface{}) ([]string, error) {
+			category := record.(*models.Category)
+			return liveDuplicates(db, &models.Category{}, "name", category.Name)
+		},
+	},
+	{
+		Name:      "departments",
+		newRecord: func() interface{} { return &models.Department{} },
+		newList:   func() interface{} { return &[]models.Department{} },
+		conflicts: func(db *gorm.DB, record interface{}) ([]string, error) {
+			department := record.(*models.Department)
+			return liveDuplicates(db, &models.Department{}, "name", department.Name)
+		},
+		present: func(list interface{}) interface{} {
+			departments := *list.(*[]models.Department)
+			deleted := make([]deletedDepartment, len(departments))
+			for i, department := range departments {
+				deleted[i] = deletedDepartment{Department: department, DeletedAt: department.DeletedAt}
+			}
+			return deleted
+		},
+	},
+}
+
+// Lookup returns the trash kind with the given name, such as "assets"
+func Lookup(name string) (*Kind, bool) {
+	for _, kind := range kinds {
+		if kind.Name == name {
+			return kind, true
+		}
+	}
+	return nil, false
+}
+
+// Query returns a query over the deleted records of the kind
+func (k *Kind) Query(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Model(k.newRecord()).Where("deleted_at IS NOT NULL")
+}
+
+// NewList returns a pointer to an empty slice of the kind's model, to load records into
+func (k *Kind) NewList() interface{} {
+	return k.newList()
+}
+
+// Present returns a list loaded into NewList as it is written in responses
+func (k *Kind) Present(list interface{}) interface{} {
+	if k.present == nil {
+		return list
+	}
+	return k.present(list)
+}
+
+// findDeleted loads a record that is in the trash, returning gorm.ErrRecordNotFound otherwise
+func (k *Kind) findDeleted(db *gorm.DB, id string) (interface{}, error) {
+	record := k.newRecord()
+	if err := db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(record).Error; err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Restore takes a record out of the trash. A *ConflictError is returned when the record clashes
+// with live data, such as a serial number reused since the delete.
+func (k *Kind) Restore(db *gorm.DB, id string) (interface{}, error) {
+	var record interface{}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if record, err = k.findDeleted(tx, id); err != nil {
+			return err
+		}
+
+		conflicts, err := k.conflicts(tx, record)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			return &ConflictError{Conflicts: conflicts}
+		}
+
+		updates := map[string]interface{}{"deleted_at": nil}
+		if k.versioned {
+			updates["version"] = gorm.Expr("version + 1")
+		}
+		if err := tx.Unscoped().Model(record).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.First(record, "id = ?", id).Error
+	})
+	return record, err
+}
+
+// PurgeOne permanently deletes a record in the trash along with the operational records it owns.
+// It returns a *RetainedError when the record has records that are kept, and
+// gorm.ErrForeignKeyViolated when other records still reference it.
+func (k *Kind) PurgeOne(db *gorm.DB, id string) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		record, err := k.findDeleted(tx, id)
+		if err != nil {
+			return err
+		}
+
+		var retained []string
+		for _, r := range k.retainers {
+			var count int64
+			if err := tx.Unscoped().Model(r.model).Where(k.ownerKey+" = ?", id).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				retained = append(retained, r.label)
+			}
+		}
+		if len(retained) > 0 {
+			return &RetainedError{Records: retained}
+		}
+
+		for _, dependent := range k.dependents {
+			if err := tx.Unscoped().Where(k.ownerKey+" = ?", id).Delete(dependent).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Delete(record).Error
+	})
+	return database.TranslateError(db, err)
+}
+
+// Purge permanently deletes every record that was moved to the trash before the given time.
+// Records that are retained or still referenced elsewhere are left in the trash. It returns the
+// number purged.
+func Purge(db *gorm.DB, before time.Time) (int, error) {
+	purged := 0
+	for _, kind := range kinds {
+		var ids []string
+		if err := db.Unscoped().Model(kind.newRecord()).Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Pluck("id", &ids).Error; err != nil {
+			return purged, err
+		}
+
+		for _, id := range ids {
+			err := kind.PurgeOne(db, id)
+			var retained *RetainedError
+			if errors.As(err, &retained) {
+				log.Printf("Trash purge skipped %s %s: has %s", kind.Name, id, strings.Join(retained.Records, ", "))
+				continue
+			}
+			if errors.Is(err, gorm.ErrForeignKeyViolated) {
+				log.Printf("Trash purge skipped %s %s: still referenced", kind.Name, id)
+				continue
+			}
+			if err != nil {
+				return purged, err
+			}
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// StartPurge runs Purge in the background at the given interval, removing records that have
+// been in the trash for longer than retention.
+func StartPurge(db *gorm.DB, every, retention time.Duration) {
+	go func() {
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+
+		for {
+			purged, err := Purge(db, time.Now().Add(-retention))
+			if err != nil {
+				log.Printf("Trash purge failed: %v", err)
+			} else if purged > 0 {
+				log.Printf("Trash purge removed %d records", purged)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// liveDuplicates reports a conflict when a live record of the model has the same column value
+func liveDuplicates(db *gorm.DB, model interface{}, column, value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var count int64
+	if err := db.Model(model).Where(column+" = ?", value).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return []string{column + " " + value + " is in use"}, nil
+	}
+	return nil, nil
+}
+
+// inTrash reports a conflict when the referenced record is itself in the trash
+func inTrash(db *gorm.DB, model interface{}, id interface{}, label string) ([]string, error) {
+	var count int64
+	if err := db.Unscoped().Model(model).Where("id = ? AND deleted_at IS NOT NULL", id).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return []string{label + " is in the trash; restore it first"}, nil
+	}
+	return nil, nil
+}
+
+func assetConflicts(db *gorm.DB, record interface{}) ([]string, error) {
+	asset := record.(*models.Asset)
+
+	conflicts, err := liveDuplicates(db, &models.Asset{}, "serial_number", asset.SerialNumber)
+	if err != nil {
+		return nil, err
+	}
+	found, err := liveDuplicates(db, &models.Asset{}, "asset_tag", asset.AssetTag)
+	if err != nil {
+		return nil, err
+	}
+	conflicts = append(conflicts, found...)
+	if asset.CategoryID != nil {
+		found, err := inTrash(db, &models.Category{}, *asset.CategoryID, "category")
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, found...)
+	}
+	if asset.DepartmentID != nil {
+		found, err := inTrash(db, &models.Department{}, *asset.DepartmentID, "department")
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, found...)
+	}
+	return conflicts, nil
+}
+
+func userConflicts(db *gorm.DB, record interface{}) ([]string, error) {
+	user := record.(*models.User)
+
+	conflicts, err := liveDuplicates(db, &models.User{}, "username", user.Username)
+	if err != nil {
+		return nil, err
+	}
+	found, err := liveDuplicates(db, &models.User{}, "email", user.Email)
+	if err != nil {
+		return nil, err
+	}
+	conflicts = append(conflicts, found...)
+
+	if user.DepartmentID != nil {
+		found, err := inTrash(db, &models.Department{}, *user.DepartmentID, "department")
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, found...)
+	}
+	return conflicts, nil
+}
@@ -0,0 +1,202 @@
+// Package vendors groups the free-text manufacturer names recorded on assets into vendors of
+// the vendor registry
+package vendors
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"sams-backend/internal/models"
+)
+
+// similarity is the smallest edit-distance ratio at which two names are taken to be spellings of
+// the same company, and minSimilarLength the shortest key compared this way. Short keys such as
+// "hp" and "lg" are a single edit apart from unrelated companies.
+const (
+	similarity       = 0.85
+	minSimilarLength = 5
+)
+
+// legalSuffixes are words naming a company's legal form, ignored when comparing names
+var legalSuffixes = map[string]bool{
+	"inc": true, "incorporated": true, "corp": true, "corporation": true, "co": true,
+	"company": true, "ltd": true, "limited": true, "llc": true, "plc": true, "gmbh": true,
+	"ag": true, "sa": true, "bv": true, "nv": true, "spa": true, "srl": true, "pty": true,
+	"pt": true, "tbk": true, "kk": true, "group": true, "holdings": true,
+}
+
+// Name is a manufacturer spelling and the number of assets recorded with it
+type Name struct {
+	Text  string `json:"text"`
+	Count int64  `json:"count"`
+}
+
+// Cluster is a group of spellings taken to name the same company
+type Cluster struct {
+	// Canonical is the vendor name for the group: the registered vendor's name when the group
+	// matched one, otherwise its most used spelling
+	Canonical string `json:"canonical"`
+	// VendorID is the registered vendor the group matched, if any
+	VendorID *uuid.UUID `json:"vendor_id,omitempty"`
+	Names    []Name     `json:"names"`
+	Assets   int64      `json:"assets"`
+}
+
+// tokens lowercases a name, splits it into words at anything other than letters and digits and
+// drops legal-form words, unless the name consists of nothing else
+func tokens(name string) []string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var kept []string
+	for _, word := range words {
+		if !legalSuffixes[word] {
+			kept = append(kept, word)
+		}
+	}
+	if len(kept) == 0 {
+		return words
+	}
+	return kept
+}
+
+// acronym returns the initials of a name of two or more words, so "Hewlett Packard" gives "hp"
+func acronym(words []string) string {
+	if len(words) < 2 {
+		return ""
+	}
+	var b strings.Builder
+	for _, word := range words {
+		r := []rune(word)
+		b.WriteRune(r[0])
+	}
+	return b.String()
+}
+
+// levenshtein returns the number of single-character edits turning a into b
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// entry is a name being clustered, with the keys it is compared by
+type entry struct {
+	name    Name
+	vendor  *models.Vendor
+	key     string
+	acronym string
+}
+
+// matches reports whether two entries are taken to name the same company: their keys are
+// equal, one is the acronym of the other, or their keys are nearly the same spelling
+func (e entry) matches(o entry) bool {
+	if e.key == o.key {
+		return true
+	}
+	if (e.acronym != "" && e.acronym == o.key) || (o.acronym != "" && o.acronym == e.key) {
+		return true
+	}
+
+	a, b := []rune(e.key), []rune(o.key)
+	longest := max(len(a), len(b))
+	if min(len(a), len(b)) < minSimilarLength {
+		return false
+	}
+	return 1-float64(levenshtein(a, b))/float64(longest) >= similarity
+}
+
+// Group clusters manufacturer spellings with each other and with the registered vendors. Only
+// clusters containing at least one spelling are returned, those covering the most assets first.
+func Group(names []Name, vendors []models.Vendor) []Cluster {
+	entries := make([]entry, 0, len(vendors)+len(names))
+	for i := range vendors {
+		words := tokens(vendors[i].Name)
+		entries = append(entries, entry{vendor: &vendors[i], key: strings.Join(words, ""), acronym: acronym(words)})
+	}
+	for _, name := range names {
+		words := tokens(name.Text)
+		entries = append(entries, entry{name: name, key: strings.Join(words, ""), acronym: acronym(words)})
+	}
+
+	parent := make([]int, len(entries))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range entries {
+		for j := i + 1; j < len(entries); j++ {
+			if entries[i].matches(entries[j]) {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	// Registered vendors come first, so the root of a cluster that matched one is its first vendor
+	groups := make(map[int]*Cluster)
+	var roots []int
+	for i, e := range entries {
+		root := find(i)
+		cluster, ok := groups[root]
+		if !ok {
+			cluster = &Cluster{}
+			groups[root] = cluster
+			roots = append(roots, root)
+		}
+		if e.vendor != nil {
+			if cluster.VendorID == nil {
+				cluster.VendorID = &e.vendor.ID
+				cluster.Canonical = e.vendor.Name
+			}
+			continue
+		}
+		cluster.Names = append(cluster.Names, e.name)
+		cluster.Assets += e.name.Count
+	}
+
+	var clusters []Cluster
+	for _, root := range roots {
+		cluster := groups[root]
+		if len(cluster.Names) == 0 {
+			continue
+		}
+		sort.Slice(cluster.Names, func(i, j int) bool {
+			if cluster.Names[i].Count != cluster.Names[j].Count {
+				return cluster.Names[i].Count > cluster.Names[j].Count
+			}
+			return cluster.Names[i].Text < cluster.Names[j].Text
+		})
+		if cluster.VendorID == nil {
+			cluster.Canonical = cluster.Names[0].Text
+		}
+		clusters = append(clusters, *cluster)
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].Assets > clusters[j].Assets
+	})
+	return clusters
+}
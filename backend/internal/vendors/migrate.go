@@ -0,0 +1,130 @@
+package vendors
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"sams-backend/internal/models"
+)
+
+// linkBatch is how many assets are linked per update statement
+const linkBatch = 1000
+
+// Result reports what Migrate did or, in a dry run, would do
+type Result struct {
+	Clusters       []Cluster `json:"clusters"`
+	VendorsCreated int       `json:"vendors_created"`
+	AssetsLinked   int64     `json:"assets_linked"`
+}
+
+// Migrate clusters the manufacturer names of assets not yet linked to a manufacturer and links
+// each cluster to a vendor, registering a vendor under the cluster's canonical name when none
+// matched. The free-text manufacturer is left as recorded. Without apply nothing is written and
+// the result only lists the clusters found.
+func Migrate(db *gorm.DB, apply bool) (*Result, error) {
+	var names []Name
+	err := db.Model(&models.Asset{}).
+		Select("TRIM(manufacturer) AS text, COUNT(*) AS count").
+		Where("manufacturer_id IS NULL AND TRIM(COALESCE(manufacturer, '')) <> ''").
+		Group("TRIM(manufacturer)").
+		Scan(&names).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var registered []models.Vendor
+	if err := db.Order("created_at").Find(&registered).Error; err != nil {
+		return nil, err
+	}
+
+	result := &Result{Clusters: Group(names, registered)}
+	if !apply {
+		return result, nil
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for i := range result.Clusters {
+			cluster := &result.Clusters[i]
+			vendorID, created, err := clusterVendor(tx, cluster)
+			if err != nil {
+				return err
+			}
+			if created {
+				result.VendorsCreated++
+			}
+
+			linked, err := linkAssets(tx, cluster, vendorID)
+			if err != nil {
+				return err
+			}
+			result.AssetsLinked += linked
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// clusterVendor returns the vendor a cluster is linked to, registering it as a manufacturer when
+// it is not one yet and creating it when the cluster matched no vendor
+func clusterVendor(tx *gorm.DB, cluster *Cluster) (uuid.UUID, bool, error) {
+	if cluster.VendorID != nil {
+		err := tx.Model(&models.Vendor{}).Where("id = ? AND NOT is_manufacturer", *cluster.VendorID).
+			Update("is_manufacturer", true).Error
+		return *cluster.VendorID, false, err
+	}
+
+	vendor := models.Vendor{Name: cluster.Canonical, IsManufacturer: true}
+	if err := tx.Create(&vendor).Error; err != nil {
+		return uuid.Nil, false, err
+	}
+	return vendor.ID, true, nil
+}
+
+// linkAssets sets the manufacturer of the unlinked assets recorded with one of the cluster's
+// spellings, bumping their version and recording the change in their history
+func linkAssets(tx *gorm.DB, cluster *Cluster, vendorID uuid.UUID) (int64, error) {
+	spellings := make([]string, len(cluster.Names))
+	for i, name := range cluster.Names {
+		spellings[i] = name.Text
+	}
+
+	var ids []uuid.UUID
+	err := tx.Model(&models.Asset{}).
+		Where("manufacturer_id IS NULL AND TRIM(manufacturer) IN ?", spellings).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	for start := 0; start < len(ids); start += linkBatch {
+		batch := ids[start:min(start+linkBatch, len(ids))]
+		err := tx.Model(&models.Asset{}).Where("id IN ?", batch).UpdateColumns(map[string]interface{}{
+			"manufacturer_id": vendorID,
+			"version":         gorm.Expr("version + 1"),
+			"updated_at":      now,
+		}).Error
+		if err != nil {
+			return 0, err
+		}
+
+		history := make([]models.AssetHistory, len(batch))
+		for i, id := range batch {
+			history[i] = models.AssetHistory{
+				AssetID:   id,
+				Field:     "manufacturer_id",
+				NewValue:  vendorID.String(),
+				ChangedAt: now,
+			}
+		}
+		if err := tx.Create(&history).Error; err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(ids)), nil
+}